@@ -18,6 +18,10 @@ import (
 	pb "github.com/nci/gsky/worker/gdalservice"
 )
 
+// drillGracePeriod is how long a timed out drill is given to return
+// before the process is killed.
+const drillGracePeriod = 5 * time.Second
+
 func sendOutput(out *pb.Result, conn net.Conn) error {
 	outb, err := proto.Marshal(out)
 	if err != nil {
@@ -63,6 +67,15 @@ func dataHandler(conn net.Conn, timeout int) {
 			timeoutCancel()
 		case <-timeoutCtx.Done():
 			log.Printf("%v timed out in %v seconds", in.Path, timeout)
+			// Drills abort on the context deadline, so give them a
+			// chance to report the timeout before killing the process.
+			if in.Operation == "drill" {
+				select {
+				case <-done:
+					return
+				case <-time.After(drillGracePeriod):
+				}
+			}
 			os.Exit(2)
 		}
 	}()
@@ -71,7 +84,13 @@ func dataHandler(conn net.Conn, timeout int) {
 	case "warp":
 		out = gp.WarpRaster(in)
 	case "drill":
-		out = gp.DrillDataset(in)
+		if in.Stream {
+			out = gp.DrillDatasetStream(timeoutCtx, in, func(part *pb.Result) error {
+				return gp.WriteResultFrame(conn, part)
+			})
+		} else {
+			out = gp.DrillDataset(timeoutCtx, in)
+		}
 	case "extent":
 		out = gp.ComputeReprojectExtent(in)
 	case "info":
@@ -81,7 +100,12 @@ func dataHandler(conn net.Conn, timeout int) {
 	}
 	done <- true
 
-	err = sendOutput(out, conn)
+	// Streamed results are framed, the final one included
+	if in.Stream {
+		err = gp.WriteResultFrame(conn, out)
+	} else {
+		err = sendOutput(out, conn)
+	}
 	if err != nil {
 		log.Println(err)
 	}
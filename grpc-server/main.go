@@ -32,6 +32,9 @@ func (s *server) Process(ctx context.Context, in *pb.GeoRPCGranule) (*pb.Result,
 		return &pb.Result{WorkerInfo: &pb.WorkerInfo{PoolSize: int32(s.PoolSize)}}, nil
 	}
 
+	// Results are only streamed by DrillStream
+	in.Stream = false
+
 	rChan := make(chan *pb.Result, 1)
 	defer close(rChan)
 	errChan := make(chan error, 1)
@@ -53,6 +56,41 @@ func (s *server) Process(ctx context.Context, in *pb.GeoRPCGranule) (*pb.Result,
 	}
 }
 
+// DrillStream drills the granule like Process, streaming the rows of the
+// time series as they're computed. The final result carries the metrics
+// and the shape of all the rows.
+func (s *server) DrillStream(in *pb.GeoRPCGranule, stream pb.GDAL_DrillStreamServer) error {
+	in.Stream = true
+
+	partChan := make(chan *pb.Result)
+	rChan := make(chan *pb.Result, 1)
+	errChan := make(chan error, 1)
+
+	s.Pool.AddQueue(&pp.Task{Payload: in, Resp: rChan, Error: errChan, Partial: partChan})
+
+	// The partial results are drained until the task is done even if
+	// the client has gone, so that the process isn't blocked.
+	var sendErr error
+	for {
+		select {
+		case part := <-partChan:
+			if sendErr == nil {
+				sendErr = stream.Send(part)
+			}
+		case out := <-rChan:
+			if sendErr != nil {
+				return sendErr
+			}
+			if out.Error != "OK" {
+				return fmt.Errorf("%s", out.Error)
+			}
+			return stream.Send(out)
+		case err := <-errChan:
+			return fmt.Errorf("Error in ops: %v", err)
+		}
+	}
+}
+
 func main() {
 	port := flag.Int("p", 6000, "gRPC server listening port.")
 	poolSize := flag.Int("n", runtime.NumCPU(), "Maximum number of requests handled concurrently.")
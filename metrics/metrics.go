@@ -42,6 +42,11 @@ type RPCInfo struct {
 	BytesRead        int64         `json:"bytes_read"`
 	UserTime         int64         `json:"user_time"`
 	SysTime          int64         `json:"sys_time"`
+	WallTime         int64         `json:"wall_time"`
+	RasterIOTime     int64         `json:"raster_io_time"`
+	ReduceTime       int64         `json:"reduce_time"`
+	ValidPixels      int64         `json:"valid_pixels"`
+	MaskedPixels     int64         `json:"masked_pixels"`
 }
 
 type MetricsInfo struct {
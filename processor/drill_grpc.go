@@ -11,6 +11,10 @@ import (
 	"google.golang.org/grpc"
 )
 
+// compressBandsThreshold is the number of bands from which the workers
+// are asked to compress the time series of a granule.
+const compressBandsThreshold = 1000
+
 type GeoDrillGRPC struct {
 	Context context.Context
 	In      chan *GeoDrillGranule
@@ -101,6 +105,11 @@ func (gi *GeoDrillGRPC) Run(bandStrides int, decileCount int, pixelCount int, ve
 							geoReq.MetricsCollector.Info.RPC.BytesRead += metrics[i].BytesRead
 							geoReq.MetricsCollector.Info.RPC.UserTime += metrics[i].UserTime
 							geoReq.MetricsCollector.Info.RPC.SysTime += metrics[i].SysTime
+							geoReq.MetricsCollector.Info.RPC.WallTime += metrics[i].WallTime
+							geoReq.MetricsCollector.Info.RPC.RasterIOTime += metrics[i].RasterIOTime
+							geoReq.MetricsCollector.Info.RPC.ReduceTime += metrics[i].ReduceTime
+							geoReq.MetricsCollector.Info.RPC.ValidPixels += metrics[i].ValidPixels
+							geoReq.MetricsCollector.Info.RPC.MaskedPixels += metrics[i].MaskedPixels
 						}
 					}
 				}()
@@ -129,13 +138,22 @@ func (gi *GeoDrillGRPC) Run(bandStrides int, decileCount int, pixelCount int, ve
 				c := pb.NewGDALClient(conns[(iTile+workerStart)%len(conns)])
 				bands, err := getBands(g.TimeStamps)
 
-				granule := &pb.GeoRPCGranule{Operation: "drill", Path: g.Path, Geometry: g.Geometry, Bands: bands, BandStrides: int32(bandStrides), DrillDecileCount: int32(decileCount), ClipUpper: gran.ClipUpper, ClipLower: gran.ClipLower, PixelCount: int32(pixelCount), VRT: g.VRT}
+				granule := &pb.GeoRPCGranule{Operation: "drill", Path: g.Path, Geometry: g.Geometry, Bands: bands, BandStrides: int32(bandStrides), DrillDecileCount: int32(decileCount), ClipUpper: gran.ClipUpper, ClipLower: gran.ClipLower, PixelCount: int32(pixelCount), VRT: g.VRT, CompressTimeSeries: len(bands) >= compressBandsThreshold}
 				r, err := c.Process(gi.Context, granule)
 				if err != nil {
 					gi.sendError(fmt.Errorf("Drill gRPC: %v", err))
 					r = &pb.Result{}
 					return
 				}
+				if err := pb.DecompressTimeSeries(r); err != nil {
+					gi.sendError(fmt.Errorf("Drill gRPC: %v", err))
+					return
+				}
+
+				// Granules the geometry doesn't overlap don't contribute
+				if r.Error == "NO_OVERLAP" {
+					return
+				}
 
 				nCols := int(r.Shape[1])
 				nRows := int(r.Shape[0])
@@ -0,0 +1,76 @@
+package gdalprocess
+
+// #include <stdlib.h>
+// #include "cpl_error.h"
+// #cgo pkg-config: gdal
+// extern void goCPLErrorHandler(CPLErr, CPLErrorNum, char *);
+import "C"
+
+import (
+	"log"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// cplErrorCapture records the last GDAL error raised while processing
+// a request. GDAL error handlers are installed per thread, hence the
+// goroutine is locked to its thread for the duration of the capture.
+type cplErrorCapture struct {
+	key     unsafe.Pointer
+	lastMsg string
+}
+
+// cplErrorCaptures maps the opaque handler argument handed to GDAL to
+// the capture. Go pointers can't be retained by C, hence the argument
+// is a C allocation used as a key.
+var cplErrorCaptures = struct {
+	sync.Mutex
+	captures map[unsafe.Pointer]*cplErrorCapture
+}{captures: make(map[unsafe.Pointer]*cplErrorCapture)}
+
+//export goCPLErrorHandler
+func goCPLErrorHandler(eErrClass C.CPLErr, errNo C.CPLErrorNum, msg *C.char) {
+	// Debug messages are dropped like GDAL's default handler does,
+	// which would otherwise flood the log with CPL_DEBUG enabled
+	if eErrClass < C.CE_Warning {
+		return
+	}
+	goMsg := C.GoString(msg)
+	log.Printf("GDAL error %d: %s", int(errNo), goMsg)
+	if eErrClass < C.CE_Failure {
+		return
+	}
+
+	cplErrorCaptures.Lock()
+	capture := cplErrorCaptures.captures[C.CPLGetErrorHandlerUserData()]
+	cplErrorCaptures.Unlock()
+	if capture != nil {
+		capture.lastMsg = goMsg
+	}
+}
+
+// captureCPLErrors starts capturing the GDAL errors raised by the
+// calling goroutine until release is called.
+func captureCPLErrors() *cplErrorCapture {
+	runtime.LockOSThread()
+
+	capture := &cplErrorCapture{key: C.malloc(1)}
+	cplErrorCaptures.Lock()
+	cplErrorCaptures.captures[capture.key] = capture
+	cplErrorCaptures.Unlock()
+
+	C.CPLPushErrorHandlerEx(C.CPLErrorHandler(C.goCPLErrorHandler), capture.key)
+	return capture
+}
+
+func (c *cplErrorCapture) release() {
+	C.CPLPopErrorHandler()
+
+	cplErrorCaptures.Lock()
+	delete(cplErrorCaptures.captures, c.key)
+	cplErrorCaptures.Unlock()
+	C.free(c.key)
+
+	runtime.UnlockOSThread()
+}
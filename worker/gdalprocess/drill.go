@@ -5,15 +5,24 @@ package gdalprocess
 // #include "ogr_api.h"
 // #include "ogr_srs_api.h"
 // #include "cpl_string.h"
+// #include "cpl_conv.h"
 // #cgo pkg-config: gdal
 import "C"
 
 import (
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"log"
 	"math"
-	"sort"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"encoding/json"
@@ -25,28 +34,186 @@ import (
 type DrillFileDescriptor struct {
 	OffX, OffY     int32
 	CountX, CountY int32
-	Mask           []uint8
+
+	// Mask flags the pixels of the window within the geometry with any
+	// non-zero value, burnt by createMask in the mask type of the request
+	// with its burn value, 255 by default. Pixels outside of the geometry
+	// or masked out are zero.
+	Mask *MaskBuffer
+
+	// Weights holds the fractional coverage of each pixel of the window
+	// by the geometry. It's nil unless fractional coverage is requested.
+	// Only the mean and the pixel count are weighted, the other
+	// statistics are computed over the pixels with non-zero coverage.
+	Weights []float32
+
+	// PixelWeights holds the values of the weight band for the window,
+	// which multiply the coverage weights in the mean and the sum but
+	// don't weight the pixel count. It's nil without a weight band.
+	PixelWeights []float32
+
+	// OvrLevel is the index of the overview the window is read from,
+	// or -1 for the full resolution raster. GeoTransform is the
+	// geotransform of the grid the window refers to.
+	OvrLevel     int
+	GeoTransform []float64
+
+	// Samples holds the fractional pixel coordinates of the points
+	// relative to the window when points are interpolated from their
+	// neighbours with the given resampling rather than sampled by
+	// nearest neighbour, in which case the window spans the neighbours.
+	Samples    [][2]float64
+	Resampling pb.Resampling
+
+	// InteriorMask holds the mask of the pixels whose center is within
+	// the geometry when the deciles are computed over the interior
+	// pixels only, i.e. excluding the edge pixels merely touched by the
+	// geometry. It's nil otherwise.
+	InteriorMask *MaskBuffer
+
+	// Zones holds the zone of each pixel of the window when zone IDs are
+	// given, i.e. the index of its distinct zone ID plus one, or zero
+	// outside of the features. It's nil otherwise.
+	Zones []int32
+
+	// GeometryArea is the area of the geometry within the dataset in the
+	// units of the dataset SRS, zero for points and lines. AreaToM2 is
+	// the number of square meters per square unit of the dataset SRS
+	// around the geometry, or zero if unknown.
+	GeometryArea float64
+	AreaToM2     float64
+
+	// MaskCached reports whether the mask, along with the other buffers
+	// derived from the geometry, was found in the mask cache.
+	MaskCached bool
+
+	// Warnings holds the caveats of the window, e.g. an inaccurate
+	// reprojection of the geometry, which are returned in the result.
+	Warnings []string
+}
+
+// dataBufPool recycles the RasterIO buffers across band strides and
+// granules to reduce the pressure on the garbage collector.
+var dataBufPool = sync.Pool{
+	New: func() interface{} { return new([]float32) },
+}
+
+// getDataBuf returns a pooled buffer of the given size.
+func getDataBuf(size int) *[]float32 {
+	buf := dataBufPool.Get().(*[]float32)
+	if cap(*buf) < size {
+		*buf = make([]float32, size)
+	}
+	*buf = (*buf)[:size]
+	return buf
 }
 
+// defaultMaxReturnPixels is the default cap on the number of pixels
+// within the geometry for which the pixel values are returned.
+const defaultMaxReturnPixels = 4096
+
+// defaultSigmaIterations is the maximum number of sigma clipping
+// iterations unless given, which usually converge within a few.
+const defaultSigmaIterations = 5
+
+// coverageSupersampling is the number of sub-pixels in each direction
+// used to estimate the fractional coverage of the pixels.
+const coverageSupersampling = 4
+
+// noOverlapStatus is the error status of the results of geometries which
+// don't overlap the dataset.
+const noOverlapStatus = "NO_OVERLAP"
+
+// errNoOverlap is returned when the geometry doesn't overlap the dataset.
+var errNoOverlap = errors.New("geometry doesn't overlap the dataset")
+
+// metersPerDegree is the length of a degree of latitude on the WGS84
+// equatorial radius, used to convert buffer distances to degrees.
+const metersPerDegree = 6378137 * math.Pi / 180
+
 var cWGS84WKT = C.CString(`GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563,AUTHORITY["EPSG","7030"]],TOWGS84[0,0,0,0,0,0,0],AUTHORITY["EPSG","6326"]],PRIMEM["Greenwich",0,AUTHORITY["EPSG","8901"]],UNIT["degree",0.0174532925199433,AUTHORITY["EPSG","9108"]],AUTHORITY["EPSG","4326"]]","proj4":"+proj=longlat +ellps=WGS84 +towgs84=0,0,0,0,0,0,0 +no_defs `)
 
-func DrillDataset(in *pb.GeoRPCGranule) *pb.Result {
+// DrillDataset computes the zonal statistics of the granule within the
+// requested geometry. The drill is abandoned once ctx is done, in
+// which case an error result is returned. Error results include the
+// last error message reported by GDAL, if any. The time series are
+// gzipped into CompressedTimeSeries if requested.
+func DrillDataset(ctx context.Context, in *pb.GeoRPCGranule) *pb.Result {
+	return DrillDatasetStream(ctx, in, nil)
+}
+
+// DrillDatasetStream is DrillDataset streaming the rows of the time
+// series through emit as the band strides are merged, hence they reach
+// the client before the remaining strides are read. The rows of
+// interpolated anchors and of collections aren't streamed. The result
+// returned holds the rows not streamed yet along with the metrics and
+// the shape of all the rows. A nil emit streams nothing.
+func DrillDatasetStream(ctx context.Context, in *pb.GeoRPCGranule, emit func(*pb.Result) error) (res *pb.Result) {
+	// A panic must not take down the concurrent drills of the worker.
+	// Crashes within GDAL itself can't be recovered though.
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Drill of %s panicked: %v\n%s", in.Path, r, debug.Stack())
+			res = &pb.Result{Error: fmt.Sprintf("Drill panicked: %v", r)}
+		}
+	}()
 
-	var feat geo.Feature
-	err := json.Unmarshal([]byte(in.Geometry), &feat)
+	cplErrors := captureCPLErrors()
+	defer cplErrors.release()
+
+	// The rows streamed are compressed as well as the final ones
+	if in.CompressTimeSeries && emit != nil {
+		emitRows := emit
+		emit = func(part *pb.Result) error {
+			if err := pb.CompressTimeSeries(part); err != nil {
+				return err
+			}
+			return emitRows(part)
+		}
+	}
+
+	res = drillDataset(ctx, in, emit)
+	if res.Error != "OK" && res.Error != noOverlapStatus && len(cplErrors.lastMsg) > 0 {
+		res.Error = fmt.Sprintf("%s: GDAL error: %s", res.Error, cplErrors.lastMsg)
+	}
+	if in.CompressTimeSeries {
+		if err := pb.CompressTimeSeries(res); err != nil {
+			return &pb.Result{Error: err.Error()}
+		}
+	}
+	return res
+}
+
+func drillDataset(ctx context.Context, in *pb.GeoRPCGranule, emit func(*pb.Result) error) *pb.Result {
+	geometries, isCollection, err := parseDrillGeometries(in.Geometry)
 	if err != nil {
-		msg := fmt.Sprintf("Problem unmarshalling geometry %v", in)
+		msg := fmt.Sprintf("Problem unmarshalling geometry %s: %v", truncateGeometry(in.Geometry), err)
 		log.Println(msg)
 		return &pb.Result{Error: msg}
 	}
-	geomGeoJSON, err := json.Marshal(feat.Geometry)
+
+	// Credentials and file system options, e.g. for /vsis3/, are set
+	// for the thread the drill is locked to and restored once the
+	// dataset is closed, hence they don't leak to concurrent requests.
+	restoreConfig, err := setThreadConfigOptions(in.ConfigOptions)
 	if err != nil {
-		msg := fmt.Sprintf("Problem marshaling GeoJSON geometry: %v", err)
-		log.Println(msg)
-		return &pb.Result{Error: msg}
+		return &pb.Result{Error: err.Error()}
 	}
+	defer restoreConfig()
+
+	// Assets not indexed by the server, e.g. found by a STAC search,
+	// are stacked into a VRT built on the fly.
+	if len(in.AssetHrefs) > 0 {
+		vrtMgr, err := NewVRTManagerFromHrefs(in.AssetHrefs)
+		if err != nil {
+			msg := fmt.Sprintf("VRT Manager error: %v", err)
+			log.Println(msg)
+			return &pb.Result{Error: msg}
+		}
+		in.Path = vrtMgr.DSFileName
 
-	if len(in.VRT) > 0 {
+		defer vrtMgr.Close()
+	} else if len(in.VRT) > 0 {
 		vrtMgr, err := NewVRTManager([]byte(in.VRT))
 		if err != nil {
 			msg := fmt.Sprintf("VRT Manager error: %v", err)
@@ -58,61 +225,686 @@ func DrillDataset(in *pb.GeoRPCGranule) *pb.Result {
 		defer vrtMgr.Close()
 	}
 
+	// Restricting the drivers avoids probing the file with every
+	// driver, all of them being tried if none is given.
+	allowedDrivers, freeAllowedDrivers := cStringList(in.AllowedDrivers)
+	defer freeAllowedDrivers()
+	openOptions, freeOpenOptions := cStringList(in.OpenOptions)
+	defer freeOpenOptions()
+
+	// A variable of a multi-variable container, e.g. NetCDF or HDF5, is
+	// opened by the connection string of its subdataset.
+	if len(in.Subdataset) > 0 {
+		subdataset, err := findSubdataset(in.Path, in.Subdataset, allowedDrivers, openOptions)
+		if err != nil {
+			log.Println(err)
+			return &pb.Result{Error: err.Error()}
+		}
+		in.Path = subdataset
+	}
+
 	cPath := C.CString(in.Path)
 	defer C.free(unsafe.Pointer(cPath))
-	ds := C.GDALOpen(cPath, C.GDAL_OF_READONLY)
+	ds := C.GDALOpenEx(cPath, C.GDAL_OF_READONLY|C.GDAL_OF_RASTER, allowedDrivers, openOptions, nil)
 	if ds == nil {
 		msg := fmt.Sprintf("GDAL could not open dataset: %s", in.Path)
 		log.Println(msg)
 		return &pb.Result{Error: msg}
 	}
 	defer C.GDALClose(ds)
+	// Further handles for concurrent reads are opened the same way
+	openClone := func() C.GDALDatasetH {
+		return C.GDALOpenEx(cPath, C.GDAL_OF_READONLY|C.GDAL_OF_RASTER, allowedDrivers, openOptions, nil)
+	}
 
-	cGeom := C.CString(string(geomGeoJSON))
-	defer C.free(unsafe.Pointer(cGeom))
-	geom := C.OGR_G_CreateGeometryFromJson(cGeom)
-	if geom == nil {
-		msg := fmt.Sprintf("Geometry %s could not be parsed", in.Geometry)
-		log.Println(msg)
-		return &pb.Result{Error: msg}
+	// The dataset is drilled on the target grid when a target SRS is
+	// given, in which case the strides are read one after another.
+	if len(in.WarpSRS) > 0 {
+		warped, err := warpOnRead(ds, in)
+		if err != nil {
+			log.Println(err)
+			return &pb.Result{Error: err.Error()}
+		}
+		defer C.GDALClose(warped)
+		ds = warped
+		openClone = nil
 	}
 
 	selSRS := C.OSRNewSpatialReference(cWGS84WKT)
 	defer C.OSRDestroySpatialReference(selSRS)
 
-	C.OGR_G_AssignSpatialReference(geom, selSRS)
+	// The geometry is assumed to be WGS84 unless the request specifies
+	// its SRS either as an authority code (e.g. EPSG:3577), WKT or PROJ4.
+	if len(in.GeometrySRS) > 0 {
+		cGeomSRS := C.CString(in.GeometrySRS)
+		defer C.free(unsafe.Pointer(cGeomSRS))
+		if C.OSRSetFromUserInput(selSRS, cGeomSRS) != C.OGRERR_NONE {
+			msg := fmt.Sprintf("Geometry SRS %s could not be parsed", in.GeometrySRS)
+			log.Println(msg)
+			return &pb.Result{Error: msg}
+		}
+	}
+	C.OSRSetAxisMappingStrategy(selSRS, C.OAMS_TRADITIONAL_GIS_ORDER)
 
-	res := readData(ds, in.Bands, geom, int(in.BandStrides), int(in.DrillDecileCount), int(in.PixelCount), in.ClipUpper, in.ClipLower)
-	C.OGR_G_DestroyGeometry(geom)
-	return res
+	if len(in.ZoneIDs) > 0 {
+		return drillZones(ctx, ds, openClone, in, geometries, isCollection, selSRS)
+	}
+
+	// The features of a collection are drilled one after another
+	// against the dataset opened once, each with its own window and mask.
+	if isCollection {
+		emit = nil
+	}
+	results := make([]*pb.Result, len(geometries))
+	for i, geomGeoJSON := range geometries {
+		cGeom := C.CString(string(geomGeoJSON))
+		geom := C.OGR_G_CreateGeometryFromJson(cGeom)
+		C.free(unsafe.Pointer(cGeom))
+		if geom == nil {
+			msg := fmt.Sprintf("Geometry %s could not be parsed", truncateGeometry(in.Geometry))
+			if isCollection {
+				msg = fmt.Sprintf("Geometry of feature %d could not be parsed: %s", i, truncateGeometry(string(geomGeoJSON)))
+			}
+			log.Println(msg)
+			return &pb.Result{Error: msg}
+		}
+
+		C.OGR_G_AssignSpatialReference(geom, selSRS)
+
+		res := readData(ctx, ds, openClone, in, geom, emit)
+		C.OGR_G_DestroyGeometry(geom)
+		if res.Error != "OK" && (res.Error != noOverlapStatus || !isCollection) {
+			if isCollection {
+				res.Error = fmt.Sprintf("feature %d: %s", i, res.Error)
+			}
+			return res
+		}
+		results[i] = res
+	}
+
+	if !isCollection {
+		return results[0]
+	}
+	return mergeFeatureResults(results)
+}
+
+// drillZones drills the features of a collection as zones, i.e. the
+// features are burnt with their zone IDs onto a single window which is
+// read once and reduced separately for every distinct zone ID. The rows
+// of the zones are returned in the order their IDs first appear, along
+// with the distinct zone IDs.
+func drillZones(ctx context.Context, ds C.GDALDatasetH, openClone func() C.GDALDatasetH, in *pb.GeoRPCGranule, geometries [][]byte, isCollection bool, selSRS C.OGRSpatialReferenceH) *pb.Result {
+	if !isCollection || len(in.ZoneIDs) != len(geometries) {
+		return &pb.Result{Error: fmt.Sprintf("%d zone IDs given for %d features", len(in.ZoneIDs), len(geometries))}
+	}
+	if in.BufferMeters != 0 {
+		return &pb.Result{Error: "buffered geometries not supported with zones"}
+	}
+
+	features := C.OGR_G_CreateGeometry(C.wkbGeometryCollection)
+	defer C.OGR_G_DestroyGeometry(features)
+	for i, geomGeoJSON := range geometries {
+		cGeom := C.CString(string(geomGeoJSON))
+		geom := C.OGR_G_CreateGeometryFromJson(cGeom)
+		C.free(unsafe.Pointer(cGeom))
+		if geom == nil {
+			msg := fmt.Sprintf("Geometry of feature %d could not be parsed: %s", i, truncateGeometry(string(geomGeoJSON)))
+			log.Println(msg)
+			return &pb.Result{Error: msg}
+		}
+		if C.OGR_G_GetDimension(geom) < 2 {
+			C.OGR_G_DestroyGeometry(geom)
+			return &pb.Result{Error: fmt.Sprintf("feature %d: zones must be polygons", i)}
+		}
+		C.OGR_G_AddGeometryDirectly(features, geom)
+	}
+	C.OGR_G_AssignSpatialReference(features, selSRS)
+
+	return readData(ctx, ds, openClone, in, features, nil)
+}
+
+// labelClasses sets the labels of the classes of the class fractions
+// from the name column of the default raster attribute table of their
+// band, i.e. the column of usage GFU_Name or else the first string
+// column. Bands without such a table are left with the numeric codes
+// only, as are the classes missing from the table.
+func labelClasses(ds C.GDALDatasetH, classFractions []*pb.ClassFractions) {
+	for _, cf := range classFractions {
+		rat := C.GDALGetDefaultRAT(C.GDALGetRasterBand(ds, C.int(cf.Band)))
+		if rat == nil {
+			continue
+		}
+		nameCol := C.GDALRATGetColOfUsage(rat, C.GFU_Name)
+		for iCol := C.int(0); nameCol < 0 && iCol < C.GDALRATGetColumnCount(rat); iCol++ {
+			if C.GDALRATGetTypeOfCol(rat, iCol) == C.GFT_String {
+				nameCol = iCol
+			}
+		}
+		if nameCol < 0 {
+			continue
+		}
+
+		cf.Labels = make([]string, len(cf.Classes))
+		for i, class := range cf.Classes {
+			cf.Labels[i] = strconv.Itoa(int(class))
+			if row := C.GDALRATGetRowOfValue(rat, C.double(class)); row >= 0 {
+				cf.Labels[i] = C.GoString(C.GDALRATGetValueAsString(rat, row, nameCol))
+			}
+		}
+	}
+}
+
+// netcdfBandTimes returns the Unix times of the bands of a CF-compliant
+// NetCDF variable from the NETCDF_DIM_time metadata of the bands and the
+// units of the time coordinate. It returns nil if the bands have no time
+// coordinate.
+func netcdfBandTimes(ds C.GDALDatasetH, bands []int32) ([]int64, error) {
+	cUnits := C.CString("time#units")
+	defer C.free(unsafe.Pointer(cUnits))
+	units := C.GDALGetMetadataItem(C.GDALMajorObjectH(ds), cUnits, nil)
+	if units == nil {
+		return nil, nil
+	}
+	cCalendar := C.CString("time#calendar")
+	defer C.free(unsafe.Pointer(cCalendar))
+	calendar := C.GDALGetMetadataItem(C.GDALMajorObjectH(ds), cCalendar, nil)
+	step, ref, err := parseCFTimeUnits(C.GoString(units), C.GoString(calendar))
+	if err != nil {
+		return nil, err
+	}
+
+	cKey := C.CString("NETCDF_DIM_time")
+	defer C.free(unsafe.Pointer(cKey))
+	times := make([]int64, len(bands))
+	for i, band := range bands {
+		bandH := C.GDALGetRasterBand(ds, C.int(band))
+		if bandH == nil {
+			return nil, nil
+		}
+		value := C.GDALGetMetadataItem(C.GDALMajorObjectH(bandH), cKey, nil)
+		if value == nil {
+			return nil, nil
+		}
+		t, err := strconv.ParseFloat(C.GoString(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse the time of band %d: %s", band, C.GoString(value))
+		}
+		times[i] = cfTimeToUnix(t, step, ref)
+	}
+	return times, nil
+}
+
+// findSubdataset returns the connection string of the subdataset of the
+// container at path, e.g. NETCDF:"path":var, given either its connection
+// string or its name, i.e. the variable name following the last colon.
+// The error lists the names of the subdatasets available if not found.
+func findSubdataset(path string, name string, allowedDrivers **C.char, openOptions **C.char) (string, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+	ds := C.GDALOpenEx(cPath, C.GDAL_OF_READONLY|C.GDAL_OF_RASTER, allowedDrivers, openOptions, nil)
+	if ds == nil {
+		return "", fmt.Errorf("GDAL could not open dataset: %s", path)
+	}
+	defer C.GDALClose(ds)
+
+	cDomain := C.CString("SUBDATASETS")
+	defer C.free(unsafe.Pointer(cDomain))
+	metadata := C.GDALGetMetadata(C.GDALMajorObjectH(ds), cDomain)
+	nSubdatasets := int(C.CSLCount(metadata)) / 2
+
+	var names []string
+	for i := 1; i <= nSubdatasets; i++ {
+		cKey := C.CString(fmt.Sprintf("SUBDATASET_%d_NAME", i))
+		subdataset := C.GoString(C.CSLFetchNameValue(metadata, cKey))
+		C.free(unsafe.Pointer(cKey))
+		if len(subdataset) == 0 {
+			continue
+		}
+
+		subName := subdataset[strings.LastIndex(subdataset, ":")+1:]
+		if subdataset == name || subName == name {
+			return subdataset, nil
+		}
+		names = append(names, subName)
+	}
+
+	if len(names) == 0 {
+		return "", fmt.Errorf("dataset %s has no subdatasets", path)
+	}
+	return "", fmt.Errorf("subdataset %s not found in %s, available subdatasets: %s", name, path, strings.Join(names, ", "))
 }
 
-func readData(ds C.GDALDatasetH, bands []int32, geom C.OGRGeometryH, bandStrides int, decileCount int, pixelCount int, clipUpper float32, clipLower float32) *pb.Result {
+// setThreadConfigOptions sets the KEY=VALUE GDAL configuration options
+// for the current thread and returns a function restoring their previous
+// values. The caller must be locked to its OS thread until then.
+func setThreadConfigOptions(options []string) (func(), error) {
+	type prevOption struct {
+		key, val *C.char
+	}
+	var prev []prevOption
+	restore := func() {
+		for i := len(prev) - 1; i >= 0; i-- {
+			C.CPLSetThreadLocalConfigOption(prev[i].key, prev[i].val)
+			C.free(unsafe.Pointer(prev[i].key))
+			if prev[i].val != nil {
+				C.free(unsafe.Pointer(prev[i].val))
+			}
+		}
+	}
+
+	for _, opt := range options {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			restore()
+			return nil, fmt.Errorf("invalid config option, expected KEY=VALUE: %s", opt)
+		}
+
+		key := C.CString(kv[0])
+		var val *C.char
+		if cur := C.CPLGetThreadLocalConfigOption(key, nil); cur != nil {
+			val = C.CString(C.GoString(cur))
+		}
+		prev = append(prev, prevOption{key, val})
+
+		cVal := C.CString(kv[1])
+		C.CPLSetThreadLocalConfigOption(key, cVal)
+		C.free(unsafe.Pointer(cVal))
+	}
+	return restore, nil
+}
+
+// cStringList returns strs as a NULL terminated array of C strings, or
+// nil if strs is empty, along with a function freeing the strings.
+func cStringList(strs []string) (**C.char, func()) {
+	if len(strs) == 0 {
+		return nil, func() {}
+	}
+
+	list := make([]*C.char, len(strs)+1)
+	for i, str := range strs {
+		list[i] = C.CString(str)
+	}
+	return &list[0], func() {
+		for _, str := range list[:len(strs)] {
+			C.free(unsafe.Pointer(str))
+		}
+	}
+}
+
+// parseDrillGeometries returns the GeoJSON geometries to drill, which
+// are either the geometry of a single feature or the geometries of the
+// features of a feature collection, in which case isCollection is true.
+// The geometries are validated before OGR parses them so that malformed
+// ones are reported concisely with the path of the faulty coordinates.
+func parseDrillGeometries(geometry string) (geometries [][]byte, isCollection bool, err error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(geometry), &header); err != nil {
+		return nil, false, describeJSONError(err, []byte(geometry))
+	}
+
+	if header.Type != "FeatureCollection" {
+		var feat geo.Feature
+		if err := json.Unmarshal([]byte(geometry), &feat); err != nil {
+			return nil, false, describeJSONError(err, []byte(geometry))
+		}
+		geomGeoJSON, err := json.Marshal(feat.Geometry)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := validateGeoJSONGeometry(geomGeoJSON); err != nil {
+			return nil, false, err
+		}
+		return [][]byte{geomGeoJSON}, false, nil
+	}
+
+	var featCol geo.FeatureCollection
+	if err := json.Unmarshal([]byte(geometry), &featCol); err != nil {
+		return nil, true, describeJSONError(err, []byte(geometry))
+	}
+	if len(featCol.Features) == 0 {
+		return nil, true, fmt.Errorf("feature collection has no features")
+	}
+	for i, feat := range featCol.Features {
+		geomGeoJSON, err := json.Marshal(feat.Geometry)
+		if err != nil {
+			return nil, true, fmt.Errorf("feature %d: %v", i, err)
+		}
+		if err := validateGeoJSONGeometry(geomGeoJSON); err != nil {
+			return nil, true, fmt.Errorf("feature %d: %v", i, err)
+		}
+		geometries = append(geometries, geomGeoJSON)
+	}
+	return geometries, true, nil
+}
+
+// mergeFeatureResults concatenates the rows of the results of the
+// features of a collection into a single result of shape
+// [nFeatures, nRows, nCols]. The pixels, histograms, class fractions
+// and checksums are concatenated in feature order and the metrics are
+// accumulated along with the areas of the features. The overview level,
+// resolution and pixel area are the ones of the first feature.
+func mergeFeatureResults(results []*pb.Result) *pb.Result {
+	first := results[0]
+	merged := &pb.Result{
+		Raster:        first.Raster,
+		Error:         "OK",
+		Metrics:       &pb.WorkerMetrics{},
+		OverviewLevel: first.OverviewLevel,
+		Resolution:    first.Resolution,
+		PixelArea:     first.PixelArea,
+		PixelAreaM2:   first.PixelAreaM2,
+		BandTimes:     first.BandTimes,
+		Unit:          first.Unit,
+		Shape:         append([]int32{int32(len(results))}, first.Shape...),
+	}
+
+	var sumError float64
+	for _, res := range results {
+		merged.TimeSeries = append(merged.TimeSeries, res.TimeSeries...)
+		merged.Pixels = append(merged.Pixels, res.Pixels...)
+		merged.Histograms = append(merged.Histograms, res.Histograms...)
+		merged.ClassFractions = append(merged.ClassFractions, res.ClassFractions...)
+		merged.Checksums = append(merged.Checksums, res.Checksums...)
+		merged.GeometryArea += res.GeometryArea
+		merged.GeometryAreaM2 += res.GeometryAreaM2
+		for _, warning := range res.Warnings {
+			if !containsString(merged.Warnings, warning) {
+				merged.Warnings = append(merged.Warnings, warning)
+			}
+		}
+
+		m := res.Metrics
+		merged.Metrics.BytesRead += m.BytesRead
+		merged.Metrics.MaskCacheHits += m.MaskCacheHits
+		merged.Metrics.UserTime += m.UserTime
+		merged.Metrics.SysTime += m.SysTime
+		merged.Metrics.WallTime += m.WallTime
+		merged.Metrics.RasterIOTime += m.RasterIOTime
+		merged.Metrics.ReduceTime += m.ReduceTime
+		merged.Metrics.ValidPixels += m.ValidPixels
+		merged.Metrics.MaskedPixels += m.MaskedPixels
+		merged.Metrics.InterpolationChecks += m.InterpolationChecks
+		merged.Metrics.InterpolationMaxError = math.Max(merged.Metrics.InterpolationMaxError, m.InterpolationMaxError)
+		// The block cache is shared, hence its latest state is reported
+		merged.Metrics.CacheUsed = m.CacheUsed
+		merged.Metrics.CacheMax = m.CacheMax
+		sumError += m.InterpolationMeanError * float64(m.InterpolationChecks)
+	}
+	if merged.Metrics.InterpolationChecks > 0 {
+		merged.Metrics.InterpolationMeanError = sumError / float64(merged.Metrics.InterpolationChecks)
+	}
+	return merged
+}
+
+func readData(ctx context.Context, ds C.GDALDatasetH, openClone func() C.GDALDatasetH, in *pb.GeoRPCGranule, geom C.OGRGeometryH, emit func(*pb.Result) error) *pb.Result {
+	t0 := time.Now()
+	bands := in.Bands
+	bandTimes := in.BandTimes
+	if len(bandTimes) > 0 && len(bandTimes) != len(bands) {
+		return &pb.Result{Error: fmt.Sprintf("%d band times given for %d bands", len(bandTimes), len(bands))}
+	}
+	// The bands of CF-compliant NetCDF variables are timestamped from
+	// their time coordinate unless the times are given, in which case
+	// the times are returned too. Times which can't be parsed are only
+	// reported as a warning.
+	var ncTimes []int64
+	var ncTimesErr error
+	if len(bandTimes) == 0 {
+		ncTimes, ncTimesErr = netcdfBandTimes(ds, bands)
+		bandTimes = ncTimes
+	}
+	bandStrides := int(in.BandStrides)
+	decileCount := int(in.DrillDecileCount)
+	if len(in.Percentiles) > 0 {
+		for _, pct := range in.Percentiles {
+			if pct < 0 || pct > 100 {
+				return &pb.Result{Error: fmt.Sprintf("percentile out of range [0, 100]: %v", pct)}
+			}
+		}
+		decileCount = len(in.Percentiles)
+	}
+	applyStatsMask(in)
+	// The pixel count mode replaces the mean by the fraction of the
+	// pixels within the clip bounds. It's deprecated in favour of the
+	// valid and masked pixel counts returned along with the mean.
+	pixelCount := int(in.PixelCount)
+	clipUpper := in.ClipUpper
+	clipLower := in.ClipLower
+	if in.TrimFraction < 0 || in.TrimFraction >= 0.5 {
+		return &pb.Result{Error: fmt.Sprintf("trim fraction out of range [0, 0.5): %v", in.TrimFraction)}
+	}
+	if in.TrimFraction > 0 && in.Aggregation != pb.Aggregation_ARITHMETIC {
+		return &pb.Result{Error: "trimmed mean requires the arithmetic aggregation"}
+	}
+	if in.SigmaClip < 0 {
+		return &pb.Result{Error: fmt.Sprintf("negative sigma clip: %v", in.SigmaClip)}
+	}
+	sigmaIterations := int(in.SigmaIterations)
+	if sigmaIterations <= 0 {
+		sigmaIterations = defaultSigmaIterations
+	}
+	if in.SmoothWindow < 0 {
+		return &pb.Result{Error: fmt.Sprintf("negative smoothing window: %d", in.SmoothWindow)}
+	}
+	if in.MinValidFraction < 0 || in.MinValidFraction > 1 {
+		return &pb.Result{Error: fmt.Sprintf("minimum valid fraction out of range [0, 1]: %v", in.MinValidFraction)}
+	}
+	if len(in.ClipLowerPerBand) > len(bands) || len(in.ClipUpperPerBand) > len(bands) {
+		return &pb.Result{Error: fmt.Sprintf("%d lower and %d upper clip bounds given for %d bands", len(in.ClipLowerPerBand), len(in.ClipUpperPerBand), len(bands))}
+	}
+	for ib := range bands {
+		lower, upper := bandClipBounds(in, ib)
+		if in.ClipByPercentile && (lower < 0 || upper > 100 || lower > upper) {
+			return &pb.Result{Error: fmt.Sprintf("invalid clip percentiles [%v, %v]", lower, upper)}
+		}
+	}
+	// Out of range bands would otherwise fail within RasterIO with a
+	// confusing error, if at all.
+	nRasterBands := int32(C.GDALGetRasterCount(ds))
+	for _, band := range bands {
+		if band < 1 || band > nRasterBands {
+			return &pb.Result{Error: fmt.Sprintf("band %d out of range [1, %d] of the dataset", band, nRasterBands)}
+		}
+	}
+	if err := checkDrillBands(len(bands), bandStrides, in); err != nil {
+		return &pb.Result{Error: err.Error()}
+	}
+	if in.WeightBand != 0 && (in.WeightBand < 1 || in.WeightBand > nRasterBands) {
+		return &pb.Result{Error: fmt.Sprintf("weight band %d out of range [1, %d] of the dataset", in.WeightBand, nRasterBands)}
+	}
+	nodataTol := noDataTolerance{abs: in.NoDataTolerance, rel: in.NoDataRelTolerance}
+	statsWorkers := int(in.StatsWorkers)
+	if statsWorkers <= 0 {
+		statsWorkers = runtime.NumCPU()
+	}
+
+	// Each row holds the mean followed by the optional deciles (or the
+	// explicitly requested percentiles), the optional standard
+	// deviation and variance columns, the optional median column, the
+	// optional min and max columns, the optional mode column and the
+	// optional interquartile range and median absolute deviation columns,
+	// the optional sum column, the optional standard error column, the
+	// optional coefficient of variation column, the optional NoData
+	// fraction column, the optional skewness and kurtosis columns and the
+	// coherence column of the phase of complex bands.
 	nCols := 1 + decileCount
+	if in.ComputeStdDev {
+		nCols += 2
+	}
+	if in.ComputeMedian {
+		nCols++
+	}
+	minMaxCol := -1
+	if in.ComputeMinMax {
+		minMaxCol = nCols
+		nCols += 2
+	}
+	if in.ComputeMode {
+		nCols++
+	}
+	if in.ComputeIQR {
+		nCols++
+	}
+	if in.ComputeMAD {
+		nCols++
+	}
+	if in.ComputeSum {
+		nCols++
+	}
+	if in.ComputeStdError {
+		nCols++
+	}
+	cvCol := -1
+	if in.ComputeCV {
+		cvCol = nCols
+		nCols++
+	}
+	if in.ComputeNoDataFraction {
+		nCols++
+	}
+	if in.ComputeMoments {
+		nCols += 2
+	}
+	// The phase is aggregated with circular statistics, i.e. its mean
+	// is the direction of the mean of the unit vectors, whose length is
+	// returned as the coherence of the phase.
+	phaseMean := in.ComplexPart == pb.ComplexPart_PHASE
+	if phaseMean {
+		if in.Aggregation != pb.Aggregation_ARITHMETIC || in.TrimFraction > 0 || pixelCount != 0 {
+			return &pb.Result{Error: "phase requires the arithmetic aggregation of the pixel values"}
+		}
+		nCols++
+	}
 
-	avgs := []*pb.TimeSeries{}
+	maxReturnPixels := int(in.MaxReturnPixels)
+	if maxReturnPixels <= 0 {
+		maxReturnPixels = defaultMaxReturnPixels
+	}
+
+	// The rows of the bands read, which the skipped bands are
+	// interpolated from in a second pass with PCHIP interpolation
+	pchip := in.Interpolation == pb.Interpolation_PCHIP && bandStrides > 2
 
-	dsDscr, err := getDrillFileDescriptor(ds, geom)
+	dsDscr, err := getDrillFileDescriptor(ds, geom, in)
+	if err == errNoOverlap {
+		res := noOverlapResult(len(bands), nCols, bandTimes, float64(C.GDALGetRasterNoDataValue(C.GDALGetRasterBand(ds, C.int(1)), nil)))
+		res.BandTimes = ncTimes
+		return res
+	}
 	if err != nil {
 		return &pb.Result{Error: err.Error()}
 	}
+	if ncTimesErr != nil {
+		dsDscr.Warnings = append(dsDscr.Warnings, fmt.Sprintf("bands not timestamped: %v", ncTimesErr))
+	}
 
 	// it is safe to assume all data bands have same data type and nodata value
 	bandH := C.GDALGetRasterBand(ds, C.int(1))
 	dType := C.GDALGetRasterDataType(bandH)
 
-	dSize := C.GDALGetDataTypeSizeBytes(dType)
-	if dSize == 0 {
-		err := fmt.Errorf("GDAL data type not implemented")
-		return &pb.Result{Error: err.Error()}
+	// Complex bands, e.g. SAR SLC stacks, are read as interleaved real
+	// and imaginary parts and reduced over the amplitude of the pixels.
+	isComplex := C.GDALDataTypeIsComplex(dType) != 0
+	isInteger := C.GDALDataTypeIsInteger(dType) != 0 && !isComplex
+	if in.ComplexPart != pb.ComplexPart_AMPLITUDE && !isComplex {
+		return &pb.Result{Error: fmt.Sprintf("%v requires complex bands", in.ComplexPart)}
+	}
+	if !supportedDataType(dType) {
+		return &pb.Result{Error: fmt.Sprintf("unsupported GDAL data type: %s", C.GoString(C.GDALGetDataTypeName(dType)))}
 	}
+	// 32-bit integers exceed the float32 mantissa, hence such bands are
+	// read in their native type and the mean is accumulated from their
+	// exact values in float64. 64-bit integers are read straight into
+	// float64 without the float32 intermediary. Resampled points are
+	// interpolated in float32 regardless.
+	is64 := dType == C.GDT_Int64 || dType == C.GDT_UInt64
+	isWide := (dType == C.GDT_Int32 || dType == C.GDT_UInt32 || is64) && dsDscr.Samples == nil
+	dSize := C.GDALGetDataTypeSizeBytes(dType)
 
 	if bandStrides <= 0 {
 		bandStrides = 1
 	}
 
-	nodata := float32(C.GDALGetRasterNoDataValue(bandH, nil))
+	nodata := float64(C.GDALGetRasterNoDataValue(bandH, nil))
 	metrics := &pb.WorkerMetrics{}
+	if dsDscr.MaskCached {
+		metrics.MaskCacheHits = 1
+	}
+
+	if in.WeightBand != 0 && !in.MetadataOnly {
+		if dsDscr.Samples != nil {
+			return &pb.Result{Error: "weight band not supported for resampled points"}
+		}
+		if err := readPixelWeights(ds, dsDscr, in.WeightBand, nodata, nodataTol); err != nil {
+			return &pb.Result{Error: err.Error()}
+		}
+		metrics.BytesRead += int64(len(dsDscr.PixelWeights)) * 4
+	}
+
+	if (in.MaskBand != 0 || len(in.MaskPath) > 0 || hasMaskRange(in)) && !in.MetadataOnly {
+		if dsDscr.Samples != nil {
+			return &pb.Result{Error: "validity mask not supported for resampled points"}
+		}
+		bytesRead, err := applyValidityMask(ds, dsDscr, in)
+		if err != nil {
+			return &pb.Result{Error: err.Error()}
+		}
+		metrics.BytesRead += bytesRead
+	}
+
+	// Resampled points are reduced as a window holding one pixel per
+	// point, all of which are within the geometry.
+	redDscr := dsDscr
+	if dsDscr.Samples != nil {
+		nPoints := len(dsDscr.Samples)
+		redDscr = &DrillFileDescriptor{CountX: int32(nPoints), CountY: 1, Mask: newFullMask(nPoints), OvrLevel: dsDscr.OvrLevel, GeoTransform: dsDscr.GeoTransform}
+	}
+
+	// The pixels of the window within the geometry are reduced as a
+	// whole unless zone IDs are given, in which case the pixels of each
+	// zone are reduced separately from the same reads.
+	geomZone := newDrillZone(redDscr, in, maxReturnPixels)
+	maskedPixels := geomZone.maskedPixels
+	zones := []*drillZone{geomZone}
+	if dsDscr.Zones != nil {
+		zoneIDs, _ := distinctZoneIDs(in.ZoneIDs)
+		zones = make([]*drillZone, len(zoneIDs))
+		for iZone := range zones {
+			zoneDscr := *redDscr
+			zoneDscr.Mask = zoneMask(redDscr.Mask, dsDscr.Zones, iZone)
+			zones[iZone] = newDrillZone(&zoneDscr, in, maxReturnPixels)
+		}
+	}
+
+	// The window is given at the resolution it would be read at, e.g.
+	// on an overview. The pixels within the geometry include the NoData
+	// pixels, which are only known once the bands are read.
+	window := &pb.Window{OffX: dsDscr.OffX, OffY: dsDscr.OffY, CountX: dsDscr.CountX, CountY: dsDscr.CountY, MaskedPixels: int64(maskedPixels)}
+	// The geotransform of the window and the dataset SRS let clients
+	// reconstruct the footprint of the pixels read.
+	raster := &pb.Raster{
+		NoData:       nodata,
+		GeoTransform: windowGeoTransform(dsDscr.GeoTransform, dsDscr.OffX, dsDscr.OffY),
+		Projection:   C.GoString(C.GDALGetProjectionRef(ds)),
+	}
+	// The unit of the first band stands for the ones of all the bands
+	var unit string
+	if len(bands) > 0 {
+		unit = bandUnit(ds, bands[0], in.ApplyScaleOffset)
+	}
+	if in.MetadataOnly {
+		return &pb.Result{Raster: raster, Shape: []int32{0, int32(nCols)}, Error: "OK", Metrics: &pb.WorkerMetrics{MaskedPixels: int64(maskedPixels)}, OverviewLevel: int32(dsDscr.OvrLevel + 1), Window: window, Warnings: dsDscr.Warnings, Unit: unit}
+	}
+
+	// The histograms of integer bands drilled over the whole raster are
+	// computed by GDAL, which is much faster, as long as only the NoData
+	// pixels are excluded from them.
+	useGDALHist := in.HistogramBins > 0 && in.HistogramMin < in.HistogramMax && isInteger && !in.ApplyScaleOffset &&
+		dsDscr.Samples == nil && dsDscr.Weights == nil && dsDscr.PixelWeights == nil && dsDscr.Zones == nil && nodataTol.isZero() &&
+		!in.ClipByPercentile && in.SigmaClip == 0 && len(in.ClipLowerPerBand) == 0 && len(in.ClipUpperPerBand) == 0 && float64(clipLower) < in.HistogramMin && float64(clipUpper) > in.HistogramMax &&
+		coversRaster(ds, dsDscr)
 
 	var resUsage0, resUsage1 syscall.Rusage
 	syscall.Getrusage(syscall.RUSAGE_SELF, &resUsage0)
@@ -125,162 +917,1180 @@ func readData(ds C.GDALDatasetH, bands []int32, geom C.OGRGeometryH, bandStrides
 	// 1) Load band 1 and compute average for band 1 (i.e. avg1)
 	// 2) Load band 3 and compute average for band 3 (i.e. avg3)
 	// 3) Linearly interpolate avg2 using avg1 and avg3
-	for ibBgn := 0; ibBgn < len(bands); ibBgn += bandStrides {
-		ibEnd := ibBgn + bandStrides
-		if ibEnd > len(bands) {
-			ibEnd = len(bands)
+	//
+	// At most the first and last bands of a stride are read at once,
+	// plus a skipped band when checking the interpolation error
+	maxBandsRead := 2
+	if bandStrides == 1 {
+		maxBandsRead = 1
+	}
+	checkStride := int(in.InterpolationCheckStride)
+	if checkStride > 0 && bandStrides > 2 {
+		maxBandsRead = 3
+	}
+
+	// Every reader holds the buffers of maxBandsRead bands
+	bandBytes := int64(4)
+	if isWide {
+		bandBytes += 8
+		if !is64 {
+			bandBytes += 4
 		}
+	}
+	if isComplex {
+		bandBytes += 8
+	}
+	nReaders := int64(1)
+	if in.ConcurrentReads > 1 {
+		nReaders = int64(in.ConcurrentReads)
+	}
+	if err := checkDrillMemory(dsDscr.CountX, dsDscr.CountY, bandBytes*int64(maxBandsRead)*nReaders, in); err != nil {
+		return &pb.Result{Error: err.Error()}
+	}
+
+	// The area of the pixels of the window in the units of the dataset
+	// SRS, which accounts for the overview read and rotated rasters.
+	geot := dsDscr.GeoTransform
+	pixelArea := math.Abs(geot[1]*geot[5] - geot[2]*geot[4])
+
+	plan := &drillPlan{
+		in:              in,
+		bands:           bands,
+		bandTimes:       bandTimes,
+		bandStrides:     bandStrides,
+		maxBandsRead:    maxBandsRead,
+		checkStride:     checkStride,
+		decileCount:     decileCount,
+		nCols:           nCols,
+		cvCol:           cvCol,
+		minMaxCol:       minMaxCol,
+		pchip:           pchip,
+		sigmaIterations: sigmaIterations,
+		statsWorkers:    statsWorkers,
+		nodata:          nodata,
+		nodataTol:       nodataTol,
+		pixelArea:       pixelArea,
+		dType:           dType,
+		dSize:           int(dSize),
+		isComplex:       isComplex,
+		isInteger:       isInteger,
+		isWide:          isWide,
+		is64:            is64,
+		useGDALHist:     useGDALHist,
+		dsDscr:          dsDscr,
+		redDscr:         redDscr,
+		zones:           zones,
+	}
 
-		bandsRead := []int32{bands[ibBgn], bands[ibEnd-1]}
-		if bandStrides == 1 {
-			bandsRead = bandsRead[:1]
+	// mergeGroup appends the rows of the stride starting at ibBgn to the
+	// output of every zone. The strides must be merged in order.
+	mergeGroup := func(ibBgn int, group *strideGroup) {
+		metrics.BytesRead += group.bytesRead
+		metrics.RasterIOTime += group.rasterIOTime
+		metrics.ReduceTime += group.reduceTime
+		for iZone, zone := range zones {
+			zone.merge(plan, ibBgn, group.checkBand, group.zones[iZone])
 		}
+	}
+
+	// The rows merged so far are streamed unless the zones are merged
+	// or the rows interpolated or smoothed once all the strides are read.
+	nStreamed := 0
+	emitRows := func() error {
+		if emit == nil || len(zones) > 1 || pchip || in.SmoothWindow > 1 || len(zones[0].avgs) == nStreamed {
+			return nil
+		}
+		rows := zones[0].avgs[nStreamed:]
+		nStreamed = len(zones[0].avgs)
+		return emit(&pb.Result{TimeSeries: rows, Shape: []int32{int32(len(rows) / nCols), int32(nCols)}, Error: "OK"})
+	}
+
+	// The strides are read one after another unless concurrent reads
+	// are requested, in which case they are distributed across further
+	// handles to the dataset and merged back in order.
+	nGroups := (len(bands) + bandStrides - 1) / bandStrides
+	concurrentReads := int(in.ConcurrentReads)
+	if concurrentReads > nGroups {
+		concurrentReads = nGroups
+	}
+	if concurrentReads <= 1 || openClone == nil {
+		rd := newStrideReader(ctx, ds, plan)
+		defer rd.release()
+		for ibBgn := 0; ibBgn < len(bands); ibBgn += bandStrides {
+			group, err := reduceStride(ctx, plan, ibBgn, rd)
+			if err != nil {
+				return &pb.Result{Error: err.Error()}
+			}
+			mergeGroup(ibBgn, group)
+			if err := emitRows(); err != nil {
+				return &pb.Result{Error: fmt.Sprintf("failed to stream the rows: %v", err)}
+			}
+		}
+	} else {
+		groups, err := readGroupsConcurrently(nGroups, concurrentReads, in.ConfigOptions, func(iReader int) *strideReader {
+			if iReader == 0 {
+				return newStrideReader(ctx, ds, plan)
+			}
+			clone := openClone()
+			if clone == nil {
+				return nil
+			}
+			rd := newStrideReader(ctx, clone, plan)
+			rd.close = true
+			return rd
+		}, func(iGroup int, rd *strideReader) (*strideGroup, error) {
+			return reduceStride(ctx, plan, iGroup*bandStrides, rd)
+		})
+		if err != nil {
+			return &pb.Result{Error: err.Error()}
+		}
+		for iGroup, group := range groups {
+			mergeGroup(iGroup*bandStrides, group)
+			if err := emitRows(); err != nil {
+				return &pb.Result{Error: fmt.Sprintf("failed to stream the rows: %v", err)}
+			}
+		}
+	}
+	for _, zone := range zones {
+		zone.finish(plan)
+	}
+	// The usage of the GDAL block cache right after the reads reflects
+	// the working set of the drill, which helps sizing GDAL_CACHEMAX.
+	metrics.CacheUsed = int64(C.GDALGetCacheUsed64())
+	metrics.CacheMax = int64(C.GDALGetCacheMax64())
+	syscall.Getrusage(syscall.RUSAGE_SELF, &resUsage1)
+	metrics.UserTime = resUsage1.Utime.Nano() - resUsage0.Utime.Nano()
+	metrics.SysTime = resUsage1.Stime.Nano() - resUsage0.Stime.Nano()
+	metrics.WallTime = time.Since(t0).Nanoseconds()
+
+	// The resolution is reported along the pixel axes, which only
+	// differs from the geotransform terms for rotated rasters.
+	resolution := []float64{math.Hypot(geot[1], geot[4]), math.Hypot(geot[2], geot[5])}
+	// The areas in square meters let clients weight the values by the
+	// area without the geotransform and the SRS of the dataset.
+	geometryAreaM2 := dsDscr.GeometryArea * dsDscr.AreaToM2
+	pixelAreaM2 := pixelArea * dsDscr.AreaToM2
+
+	results := make([]*pb.Result, len(zones))
+	for iZone, zone := range zones {
+		// The readers are done with the dataset by now, hence the
+		// attribute tables are safely queried
+		if in.UseRAT {
+			labelClasses(ds, zone.classFractions)
+		}
+		// The counters of the reads are shared by the zones, hence
+		// they're only reported once when the zones are merged
+		zoneMetrics := zone.metrics
+		zoneMetrics.CacheUsed, zoneMetrics.CacheMax = metrics.CacheUsed, metrics.CacheMax
+		if iZone == 0 {
+			zoneMetrics.BytesRead = metrics.BytesRead
+			zoneMetrics.MaskCacheHits = metrics.MaskCacheHits
+			zoneMetrics.UserTime, zoneMetrics.SysTime, zoneMetrics.WallTime = metrics.UserTime, metrics.SysTime, metrics.WallTime
+			zoneMetrics.RasterIOTime, zoneMetrics.ReduceTime = metrics.RasterIOTime, metrics.ReduceTime
+		}
+		// The shape covers the rows already streamed too
+		nRows := len(zone.avgs) / nCols
+		results[iZone] = &pb.Result{TimeSeries: zone.avgs[nStreamed:], Raster: raster, Shape: []int32{int32(nRows), int32(nCols)}, Error: "OK", Metrics: zoneMetrics, OverviewLevel: int32(dsDscr.OvrLevel + 1), Resolution: resolution, Pixels: zone.pixels, Histograms: zone.histograms, ClassFractions: zone.classFractions, Checksums: zone.checksums, PixelArea: pixelArea, PixelAreaM2: pixelAreaM2, GeometryArea: dsDscr.GeometryArea, GeometryAreaM2: geometryAreaM2, BandTimes: ncTimes, Window: window, Warnings: dsDscr.Warnings, Unit: unit}
+	}
+	if dsDscr.Zones == nil {
+		return results[0]
+	}
+
+	// The zones share the area of their union
+	res := mergeFeatureResults(results)
+	res.GeometryArea, res.GeometryAreaM2 = dsDscr.GeometryArea, geometryAreaM2
+	res.Window = window
+	res.Warnings = dsDscr.Warnings
+	res.ZoneIDs, _ = distinctZoneIDs(in.ZoneIDs)
+	return res
+}
 
-		effectiveNBands := len(bandsRead)
+// noOverlapResult returns the result of a geometry which doesn't overlap
+// the dataset. It holds zero count rows for all the bands, whereas its
+// distinct status lets the caller skip the granule altogether.
+func noOverlapResult(nBands int, nCols int, bandTimes []int64, nodata float64) *pb.Result {
+	avgs := make([]*pb.TimeSeries, nBands*nCols)
+	for i := range avgs {
+		avgs[i] = &pb.TimeSeries{Value: 0, Count: 0}
+	}
+	for ib, t := range bandTimes {
+		setRowTime(avgs[ib*nCols:(ib+1)*nCols], t)
+	}
+	return &pb.Result{TimeSeries: avgs, Raster: &pb.Raster{NoData: nodata}, Shape: []int32{int32(nBands), int32(nCols)}, Error: noOverlapStatus, Metrics: &pb.WorkerMetrics{}}
+}
 
-		dataBuf := make([]float32, dsDscr.CountX*dsDscr.CountY*int32(effectiveNBands))
-		C.GDALDatasetRasterIO(ds, C.GF_Read, C.int(dsDscr.OffX), C.int(dsDscr.OffY), C.int(dsDscr.CountX), C.int(dsDscr.CountY), unsafe.Pointer(&dataBuf[0]), C.int(dsDscr.CountX), C.int(dsDscr.CountY), C.GDT_Float32, C.int(effectiveNBands), (*C.int)(unsafe.Pointer(&bandsRead[0])), 0, 0, 0)
-		metrics.BytesRead += int64(len(dataBuf)) * int64(dSize)
+// containsString reports whether the string is in the list.
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
 
-		boundAvgs := make([]*pb.TimeSeries, effectiveNBands*nCols)
-		bandSize := int(dsDscr.CountX * dsDscr.CountY)
-		for iBand := 0; iBand < effectiveNBands; iBand++ {
-			bandOffset := iBand * bandSize
+// setRowTime sets the timestamp of every column of the row.
+func setRowTime(row []*pb.TimeSeries, t int64) {
+	for _, ts := range row {
+		ts.Time = t
+	}
+}
 
-			sum := float32(0)
-			total := int32(0)
+// strideAnchor holds the row of a band read when interpolating across
+// band strides, or the actual row of a band checked against the
+// interpolated one.
+type strideAnchor struct {
+	iBand int
+	row   []*pb.TimeSeries
+}
 
-			for i := 0; i < bandSize; i++ {
-				if dsDscr.Mask[i] == 255 && dataBuf[i+bandOffset] != nodata {
-					val := dataBuf[i+bandOffset]
-					if pixelCount != 0 {
-						total++
-					}
+// bandClipBounds returns the clip bounds of the band at index ib of the
+// band list. The per-band clip bounds take precedence over the scalar
+// ones for the bands they're given for, i.e. the first bands of the list.
+func bandClipBounds(in *pb.GeoRPCGranule, ib int) (float32, float32) {
+	lower, upper := in.ClipLower, in.ClipUpper
+	if ib < len(in.ClipLowerPerBand) {
+		lower = in.ClipLowerPerBand[ib]
+	}
+	if ib < len(in.ClipUpperPerBand) {
+		upper = in.ClipUpperPerBand[ib]
+	}
+	return lower, upper
+}
 
-					if val < clipLower || val > clipUpper {
-						continue
-					}
-					if pixelCount == 0 {
-						sum += val
-						total++
-					} else {
-						sum += 1.0
-					}
+// drillPlan holds the state of a drill shared by the reads of all its
+// strides, resolved from the request and the dataset beforehand. It's
+// read-only once the strides are read.
+type drillPlan struct {
+	in              *pb.GeoRPCGranule
+	bands           []int32
+	bandTimes       []int64
+	bandStrides     int
+	maxBandsRead    int
+	checkStride     int
+	decileCount     int
+	nCols           int
+	cvCol           int
+	minMaxCol       int
+	pchip           bool
+	sigmaIterations int
+	statsWorkers    int
+	nodata          float64
+	nodataTol       noDataTolerance
+	pixelArea       float64
+
+	dType       C.GDALDataType
+	dSize       int
+	isComplex   bool
+	isInteger   bool
+	isWide      bool
+	is64        bool
+	useGDALHist bool
+
+	dsDscr  *DrillFileDescriptor
+	redDscr *DrillFileDescriptor
+	zones   []*drillZone
+}
+
+// strideReader holds a handle to the dataset and the buffers the
+// strides are read into. A reader is used by a single goroutine at once.
+type strideReader struct {
+	ds           C.GDALDatasetH
+	close        bool
+	rasterIOArg  *C.GDALRasterIOExtraArg
+	releaseArg   func()
+	dataBuf      *[]float32
+	rawBuf       []uint32
+	wideBuf      []float64
+	complexBuf   *[]float32
+	resampledBuf []float32
+}
+
+// release returns the buffers of the reader to the pool and closes its
+// handle if it was opened for the reader.
+func (rd *strideReader) release() {
+	rd.releaseArg()
+	dataBufPool.Put(rd.dataBuf)
+	if rd.complexBuf != nil {
+		dataBufPool.Put(rd.complexBuf)
+	}
+	if rd.close {
+		C.GDALClose(rd.ds)
+	}
+}
+
+// newStrideReader returns a reader of the strides of the plan from the
+// dataset handle, which isn't safe for concurrent use, with its own
+// buffers.
+func newStrideReader(ctx context.Context, ds C.GDALDatasetH, plan *drillPlan) *strideReader {
+	dsDscr, maxBandsRead := plan.dsDscr, plan.maxBandsRead
+	rd := &strideReader{ds: ds}
+	rd.rasterIOArg, rd.releaseArg = newCancellableRasterIOArg(ctx)
+	rd.dataBuf = getDataBuf(int(dsDscr.CountX) * int(dsDscr.CountY) * maxBandsRead)
+	// Int32 and UInt32 values are both read into rawBuf and
+	// reinterpreted, 64-bit ones are read into wideBuf
+	if plan.isWide {
+		rd.wideBuf = make([]float64, int(dsDscr.CountX)*int(dsDscr.CountY)*maxBandsRead)
+		if !plan.is64 {
+			rd.rawBuf = make([]uint32, len(rd.wideBuf))
+		}
+	}
+	if plan.isComplex {
+		rd.complexBuf = getDataBuf(2 * int(dsDscr.CountX) * int(dsDscr.CountY) * maxBandsRead)
+	}
+	if dsDscr.Samples != nil {
+		rd.resampledBuf = make([]float32, len(dsDscr.Samples)*maxBandsRead)
+	}
+	return rd
+}
+
+// strideGroup holds the rows of the bands read for a stride, one set
+// per zone, along with the counters of the read.
+type strideGroup struct {
+	checkBand int
+	zones     []*strideRows
+	bytesRead int64
+	// The time in nanoseconds spent reading the window of the bands
+	// and reducing them, which tells I/O-bound drills from CPU-bound
+	// ones. Both are summed over the groups, hence their total exceeds
+	// the wall time of concurrent reads.
+	rasterIOTime int64
+	reduceTime   int64
+}
+
+// strideRows holds the rows of the bands read for a stride within a
+// zone along with the per-band outputs.
+type strideRows struct {
+	boundAvgs    []*pb.TimeSeries
+	pixels       []*pb.BandPixels
+	histograms   []*pb.Histogram
+	classes      []*pb.ClassFractions
+	checksums    []*pb.BandChecksum
+	validPixels  []int64
+	maskedPixels int64
+}
+
+// reduceStride reads the bands of the stride starting at ibBgn with the
+// reader and reduces them to their rows.
+func reduceStride(ctx context.Context, plan *drillPlan, ibBgn int, rd *strideReader) (*strideGroup, error) {
+	in, bands, bandStrides := plan.in, plan.bands, plan.bandStrides
+	dsDscr, zones, nCols, nodataTol := plan.dsDscr, plan.zones, plan.nCols, plan.nodataTol
+	ds, rasterIOArg := rd.ds, rd.rasterIOArg
+	pooledBuf, rawBuf, wideBuf, complexBuf, resampledBuf := rd.dataBuf, rd.rawBuf, rd.wideBuf, rd.complexBuf, rd.resampledBuf
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("Drill cancelled after %d of %d bands: %v", ibBgn, len(bands), err)
+	}
+
+	ibEnd := ibBgn + bandStrides
+	if ibEnd > len(bands) {
+		ibEnd = len(bands)
+	}
+
+	// indices within the band list of the bands read, i.e. a single
+	// band for unit strides and a trailing stride of one band
+	bandIndices := []int{ibBgn, ibEnd - 1}
+	if ibEnd-1 == ibBgn {
+		bandIndices = bandIndices[:1]
+	}
+
+	// Every checkStride-th full stride, the band in the middle of
+	// the stride is also read to measure the interpolation error
+	checkBand := -1
+	if plan.maxBandsRead == 3 && ibEnd-ibBgn == bandStrides && (ibBgn/bandStrides)%plan.checkStride == 0 {
+		checkBand = ibBgn + bandStrides/2
+		bandIndices = []int{ibBgn, checkBand, ibEnd - 1}
+	}
+	bandsRead := make([]int32, len(bandIndices))
+	for i, ib := range bandIndices {
+		bandsRead[i] = bands[ib]
+	}
+
+	effectiveNBands := len(bandsRead)
+
+	// RasterIO overwrites the whole buffer, hence there's no need
+	// to clear the values left over by previous reads
+	dataBuf := (*pooledBuf)[:int(dsDscr.CountX)*int(dsDscr.CountY)*effectiveNBands]
+	tRead := time.Now()
+	var gerr C.CPLErr
+	switch {
+	case plan.isComplex:
+		readBuf := (*complexBuf)[:2*len(dataBuf)]
+		gerr = readWindow(ds, dsDscr, bandsRead, unsafe.Pointer(&readBuf[0]), C.GDT_CFloat32, rasterIOArg)
+	case plan.isWide && plan.is64:
+		readBuf := wideBuf[:len(dataBuf)]
+		gerr = readWindow(ds, dsDscr, bandsRead, unsafe.Pointer(&readBuf[0]), C.GDT_Float64, rasterIOArg)
+	case plan.isWide:
+		readBuf := rawBuf[:len(dataBuf)]
+		gerr = readWindow(ds, dsDscr, bandsRead, unsafe.Pointer(&readBuf[0]), plan.dType, rasterIOArg)
+	default:
+		gerr = readWindow(ds, dsDscr, bandsRead, unsafe.Pointer(&dataBuf[0]), C.GDT_Float32, rasterIOArg)
+	}
+	// A failed read, e.g. a corrupt block or a denied object store
+	// request, fails the drill rather than passing for zero pixels
+	if gerr >= C.CE_Failure {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("Drill cancelled after %d of %d bands: %v", ibBgn, len(bands), ctx.Err())
+		}
+		return nil, fmt.Errorf("RasterIO of bands %v failed", bandsRead)
+	}
+	tReduce := time.Now()
+	rasterIOTime := tReduce.Sub(tRead)
+
+	// Every zone gets its own rows of the bands read
+	zoneRows := make([]*strideRows, len(zones))
+	for iZone, zone := range zones {
+		rows := &strideRows{
+			boundAvgs:    make([]*pb.TimeSeries, effectiveNBands*nCols),
+			validPixels:  make([]int64, effectiveNBands),
+			maskedPixels: int64(zone.maskedPixels) * int64(effectiveNBands),
+		}
+		if zone.returnPixels {
+			rows.pixels = make([]*pb.BandPixels, effectiveNBands)
+		}
+		if in.HistogramBins > 0 {
+			rows.histograms = make([]*pb.Histogram, effectiveNBands)
+		}
+		if in.Categorical {
+			rows.classes = make([]*pb.ClassFractions, effectiveNBands)
+		}
+		if in.DebugChecksum {
+			rows.checksums = make([]*pb.BandChecksum, effectiveNBands)
+		}
+		zoneRows[iZone] = rows
+	}
+	// GDAL handles aren't safe for concurrent use, so the band
+	// metadata is queried before dispatching the reductions
+	bandInfos := make([]bandInfo, effectiveNBands)
+	for iBand := range bandInfos {
+		bandNoData := getBandNoData(ds, bandsRead[iBand], plan.nodata)
+		bandInfos[iBand] = bandInfo{noData: float32(bandNoData), rawNoData: bandNoData, scale: 1}
+		if in.ApplyScaleOffset {
+			bandInfos[iBand].scale, bandInfos[iBand].offset = getBandScaleOffset(ds, bandsRead[iBand])
+		}
+	}
+	var gdalHists [][]int64
+	if plan.useGDALHist {
+		gdalHists = make([][]int64, effectiveNBands)
+		for iBand := range gdalHists {
+			gdalHists[iBand] = gdalHistogram(ds, bandsRead[iBand], int(in.HistogramBins), in.HistogramMin, in.HistogramMax)
+		}
+	}
+
+	var bandsWide []float64
+	if plan.isComplex {
+		complexComponent(dataBuf, (*complexBuf)[:2*len(dataBuf)], bandInfos, nodataTol, in.ComplexPart)
+	}
+	if plan.isWide {
+		bandsWide = wideBuf[:len(dataBuf)]
+		var bandsRaw []uint32
+		if !plan.is64 {
+			bandsRaw = rawBuf[:len(dataBuf)]
+		}
+		widenWindow(bandsWide, dataBuf, bandsRaw, plan.dType == C.GDT_UInt32, bandInfos, nodataTol)
+	}
+	if dsDscr.Samples != nil {
+		// the resampled values are interpolated from the float32 ones
+		resampleWindow(resampledBuf, dataBuf, dsDscr, bandInfos, nodataTol)
+		dataBuf = resampledBuf[:len(dsDscr.Samples)*effectiveNBands]
+		bandsWide = nil
+	}
+	bandSize := int(plan.redDscr.CountX) * int(plan.redDscr.CountY)
+
+	// The per-band reductions of the zones are independent of each
+	// other and write into disjoint rows of boundAvgs, which
+	// preserves the band ordering regardless of scheduling.
+	parallelFor(len(zones)*effectiveNBands, plan.statsWorkers, func(iTask int) {
+		iBand := iTask % effectiveNBands
+		zone, rows := zones[iTask/effectiveNBands], zoneRows[iTask/effectiveNBands]
+		bandOffset := iBand * bandSize
+
+		r := &bandReducer{
+			in:           in,
+			band:         bandInfos[iBand],
+			nodataTol:    nodataTol,
+			isInteger:    plan.isInteger,
+			nodata:       plan.nodata,
+			pixelArea:    plan.pixelArea,
+			vals:         dataBuf[bandOffset : bandOffset+bandSize],
+			mask:         zone.dscr.Mask,
+			decileMask:   zone.decileDscr.Mask,
+			weights:      zone.dscr.Weights,
+			pixelWeights: zone.dscr.PixelWeights,
+		}
+		if bandsWide != nil {
+			r.wide = bandsWide[bandOffset : bandOffset+bandSize]
+		}
+		if gdalHists != nil {
+			r.gdalHist = gdalHists[iBand]
+		}
+
+		if in.DebugChecksum {
+			rows.checksums[iBand] = r.checksum(bandsRead[iBand])
+		}
+		lower, upper := bandClipBounds(in, bandIndices[iBand])
+		r.setClipBounds(lower, upper, plan.sigmaIterations)
+		r.accumulate(bandsRead[iBand], zone.returnPixels)
+		rows.validPixels[iBand] = r.validPixels
+		if r.pixels != nil {
+			rows.pixels[iBand] = r.pixels
+		}
+		if in.HistogramBins > 0 {
+			rows.histograms[iBand] = r.histogram(bandsRead[iBand])
+		}
+		if r.classes != nil {
+			rows.classes[iBand] = r.classFractions(bandsRead[iBand])
+		}
+		r.reduce(rows.boundAvgs[iBand*nCols:(iBand+1)*nCols], plan.decileCount, zone.maskedPixels, zone.minValid)
+	})
+
+	return &strideGroup{
+		checkBand:    checkBand,
+		zones:        zoneRows,
+		bytesRead:    int64(len(dataBuf)) * int64(plan.dSize),
+		rasterIOTime: rasterIOTime.Nanoseconds(),
+		reduceTime:   time.Since(tReduce).Nanoseconds(),
+	}, nil
+}
+
+// drillZone holds the pixels of the window reduced together, i.e. the
+// whole geometry or the features sharing a zone ID, along with the rows
+// of the zone merged so far.
+type drillZone struct {
+	dscr         *DrillFileDescriptor
+	decileDscr   *DrillFileDescriptor
+	maskedPixels int
+	minValid     int64
+	returnPixels bool
+
+	avgs           []*pb.TimeSeries
+	anchors        []strideAnchor
+	checks         []strideAnchor
+	pixels         []*pb.BandPixels
+	histograms     []*pb.Histogram
+	classFractions []*pb.ClassFractions
+	checksums      []*pb.BandChecksum
+	metrics        *pb.WorkerMetrics
+}
+
+// newDrillZone returns the zone of the pixels within the mask of the
+// descriptor.
+func newDrillZone(dscr *DrillFileDescriptor, in *pb.GeoRPCGranule, maxReturnPixels int) *drillZone {
+	zone := &drillZone{dscr: dscr, avgs: []*pb.TimeSeries{}, metrics: &pb.WorkerMetrics{}}
+
+	// Pixels of the window within the zone, regardless of NoData
+	for i := 0; i < dscr.Mask.len(); i++ {
+		if dscr.Mask.isSet(i) && (dscr.Weights == nil || dscr.Weights[i] > 0) {
+			zone.maskedPixels++
+		}
+	}
+
+	// The mean of a band with fewer valid pixels than either threshold,
+	// e.g. a barely covered timestep, isn't representative of the
+	// geometry. The fraction is relative to the pixels within it.
+	zone.minValid = int64(in.MinValidPixels)
+	if in.MinValidFraction > 0 {
+		if n := int64(math.Ceil(in.MinValidFraction * float64(zone.maskedPixels))); n > zone.minValid {
+			zone.minValid = n
+		}
+	}
+
+	// The valid pixel values of the bands read are only returned for
+	// small geometries, larger ones only get the aggregates. Pixels are
+	// identified by their row-major index within the window, or by the
+	// index of the point for resampled points.
+	zone.returnPixels = in.ReturnPixels && zone.maskedPixels <= maxReturnPixels
+
+	// The deciles of the interior pixels aren't skewed by the edge
+	// pixels, whereas the mean is still computed over all of them.
+	zone.decileDscr = dscr
+	if dscr.InteriorMask != nil {
+		decileDscr := *dscr
+		decileDscr.Mask = newMaskBuffer(pb.MaskType_MASK_BYTE, dscr.Mask.len())
+		for i := range decileDscr.Mask.Byte {
+			if dscr.Mask.isSet(i) && dscr.InteriorMask.isSet(i) {
+				decileDscr.Mask.Byte[i] = defaultMaskBurnValue
+			}
+		}
+		zone.decileDscr = &decileDscr
+	}
+	return zone
+}
+
+// merge appends the rows of the zone read for the stride starting at
+// ibBgn to its output, interpolating the skipped bands. The rows of the
+// checked band, if any, are kept aside. The strides must be merged in
+// order.
+func (zone *drillZone) merge(plan *drillPlan, ibBgn int, checkBand int, rows *strideRows) {
+	bands, bandTimes, nCols, nodata := plan.bands, plan.bandTimes, plan.nCols, plan.nodata
+	ibEnd := ibBgn + plan.bandStrides
+	if ibEnd > len(bands) {
+		ibEnd = len(bands)
+	}
+	boundAvgs := rows.boundAvgs
+	bandPixels, bandHistograms, bandClasses := rows.pixels, rows.histograms, rows.classes
+	bandChecksums := rows.checksums
+
+	zone.metrics.MaskedPixels += rows.maskedPixels
+	for _, n := range rows.validPixels {
+		zone.metrics.ValidPixels += n
+	}
+	// The checked band is dropped from the output as if skipped
+	if checkBand >= 0 {
+		zone.checks = append(zone.checks, strideAnchor{checkBand, boundAvgs[nCols : 2*nCols]})
+		boundAvgs = append(boundAvgs[:nCols:nCols], boundAvgs[2*nCols:]...)
+		if bandPixels != nil {
+			bandPixels = []*pb.BandPixels{bandPixels[0], bandPixels[2]}
+		}
+		if bandHistograms != nil {
+			bandHistograms = []*pb.Histogram{bandHistograms[0], bandHistograms[2]}
+		}
+		if bandClasses != nil {
+			bandClasses = []*pb.ClassFractions{bandClasses[0], bandClasses[2]}
+		}
+		if bandChecksums != nil {
+			bandChecksums = []*pb.BandChecksum{bandChecksums[0], bandChecksums[2]}
+		}
+	}
+
+	if len(bandTimes) > 0 {
+		setRowTime(boundAvgs[:nCols], bandTimes[ibBgn])
+		setRowTime(boundAvgs[len(boundAvgs)-nCols:], bandTimes[ibEnd-1])
+	}
+
+	zone.pixels = append(zone.pixels, bandPixels...)
+	zone.histograms = append(zone.histograms, bandHistograms...)
+	zone.classFractions = append(zone.classFractions, bandClasses...)
+	zone.checksums = append(zone.checksums, bandChecksums...)
+
+	if plan.pchip {
+		zone.anchors = append(zone.anchors, strideAnchor{ibBgn, boundAvgs[:nCols]})
+		if ibEnd-1 > ibBgn {
+			zone.anchors = append(zone.anchors, strideAnchor{ibEnd - 1, boundAvgs[len(boundAvgs)-nCols:]})
+		}
+		return
+	}
+
+	zone.avgs = append(zone.avgs, boundAvgs[:nCols]...)
+
+	// The trailing stride may be shorter, hence the bands
+	// interpolated are the ones of the stride merged
+	nStrideBands := ibEnd - ibBgn
+	if nStrideBands > 2 && len(boundAvgs) > nCols {
+		var beta []float64
+		var count []float64
+		for ic := 0; ic < nCols; ic++ {
+			beta_ := (boundAvgs[ic+nCols].Value - boundAvgs[ic].Value) / float64(nStrideBands-1)
+			beta = append(beta, beta_)
+
+			count_ := math.Round(float64(boundAvgs[ic].Count+boundAvgs[ic+nCols].Count) / float64(2))
+			count = append(count, count_)
+		}
+		for ip := 1; ip < nStrideBands-1; ip++ {
+			for ic := 0; ic < nCols; ic++ {
+				// The undefined coefficient of variation, min and max
+				// and the mean below the valid pixel threshold aren't
+				// values to interpolate from
+				sentinelCol := ic == plan.cvCol || (ic == 0 && zone.minValid > 0) ||
+					(plan.minMaxCol >= 0 && (ic == plan.minMaxCol || ic == plan.minMaxCol+1))
+				if sentinelCol && (boundAvgs[ic].Count == 0 || boundAvgs[ic+nCols].Count == 0) {
+					zone.avgs = append(zone.avgs, &pb.TimeSeries{Value: nodata, Count: 0})
+					continue
 				}
+				beta_ := beta[ic]
+				val := boundAvgs[ic].Value + float64(ip)*beta_
+				validCount := math.Round(float64(boundAvgs[ic].ValidCount+boundAvgs[ic+nCols].ValidCount) / 2)
+				zone.avgs = append(zone.avgs, &pb.TimeSeries{Value: val, Count: int32(count[ic]), ValidCount: int64(validCount), TotalMaskedCount: boundAvgs[ic].TotalMaskedCount})
+			}
+			// Interpolated rows get interpolated timestamps
+			if len(bandTimes) > 0 {
+				t0, t1 := bandTimes[ibBgn], bandTimes[ibEnd-1]
+				t := t0 + int64(math.Round(float64(ip)*float64(t1-t0)/float64(nStrideBands-1)))
+				setRowTime(zone.avgs[len(zone.avgs)-nCols:], t)
 			}
+		}
+	}
 
-			iRes := iBand * nCols
-			if total > 0 {
-				boundAvgs[iRes] = &pb.TimeSeries{Value: float64(sum / float32(total)), Count: total}
-			} else {
-				boundAvgs[iRes] = &pb.TimeSeries{Value: 0, Count: 0}
+	if len(boundAvgs) > nCols {
+		zone.avgs = append(zone.avgs, boundAvgs[len(boundAvgs)-nCols:]...)
+	}
+}
+
+// finish interpolates the skipped bands of the zone once all the strides
+// are merged with PCHIP interpolation, measures the interpolation error
+// of the checked bands and smooths the rows.
+func (zone *drillZone) finish(plan *drillPlan) {
+	bandTimes, nCols, nodata := plan.bandTimes, plan.nCols, plan.nodata
+	if plan.pchip {
+		zone.avgs = interpolateAnchors(zone.anchors, len(plan.bands), nCols)
+		if plan.cvCol >= 0 {
+			maskUndefinedInterpolation(zone.avgs, zone.anchors, nCols, plan.cvCol, nodata)
+		}
+		if plan.minMaxCol >= 0 {
+			maskUndefinedInterpolation(zone.avgs, zone.anchors, nCols, plan.minMaxCol, nodata)
+			maskUndefinedInterpolation(zone.avgs, zone.anchors, nCols, plan.minMaxCol+1, nodata)
+		}
+		if zone.minValid > 0 {
+			maskUndefinedInterpolation(zone.avgs, zone.anchors, nCols, 0, nodata)
+		}
+		if len(bandTimes) > 0 {
+			for ib, t := range bandTimes {
+				setRowTime(zone.avgs[ib*nCols:(ib+1)*nCols], t)
 			}
+		}
+	}
 
-			if nCols > 1 {
-				if total > 0 {
-					deciles := computeDeciles(decileCount, dataBuf, bandSize, bandOffset, nodata, dsDscr)
-					for ic := 0; ic < len(deciles); ic++ {
-						iRes++
-						boundAvgs[iRes] = &pb.TimeSeries{Value: float64(deciles[ic]), Count: 1}
-					}
-				} else {
-					for ic := 0; ic < decileCount; ic++ {
-						iRes++
-						boundAvgs[iRes] = &pb.TimeSeries{Value: 0, Count: 0}
+	var sumError float64
+	for _, check := range zone.checks {
+		if check.row[0].Count == 0 {
+			continue
+		}
+		absError := math.Abs(zone.avgs[check.iBand*nCols].Value - check.row[0].Value)
+		zone.metrics.InterpolationMaxError = math.Max(zone.metrics.InterpolationMaxError, absError)
+		zone.metrics.InterpolationChecks++
+		sumError += absError
+	}
+	if zone.metrics.InterpolationChecks > 0 {
+		zone.metrics.InterpolationMeanError = sumError / float64(zone.metrics.InterpolationChecks)
+	}
+
+	// The rows are smoothed after the interpolation, i.e. along
+	// all the bands rather than the bands read
+	if plan.in.SmoothWindow > 1 {
+		smoothTimeSeries(zone.avgs, nCols, int(plan.in.SmoothWindow))
+	}
+}
+
+// readGroupsConcurrently reduces nGroups strides with nReaders goroutines,
+// each locked to its thread with the configuration options set and
+// reading with the reader returned by newReader. A reader whose handle
+// can't be opened, flagged by nil, leaves the strides to the others,
+// which is never the case of the first one. The groups are returned in
+// order along with the error of the first failed stride. A panic in any
+// of the goroutines is propagated to the caller. The GDAL errors raised
+// on the threads of the goroutines are captured and the last one of a
+// failed stride appended to its error, as the capture of the request
+// only covers the thread of the caller.
+func readGroupsConcurrently(nGroups int, nReaders int, configOptions []string, newReader func(int) *strideReader, reduce func(int, *strideReader) (*strideGroup, error)) ([]*strideGroup, error) {
+	groups := make([]*strideGroup, nGroups)
+	errs := make([]error, nGroups)
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicVal interface{}
+	for iReader := 0; iReader < nReaders; iReader++ {
+		wg.Add(1)
+		go func(iReader int) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					panicOnce.Do(func() { panicVal = r })
+					// Keep draining so the dispatch doesn't block
+					for range next {
 					}
 				}
+			}()
+
+			// GDAL configuration options are thread-local
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+			restoreConfig, err := setThreadConfigOptions(configOptions)
+			if err != nil {
+				panic(err)
+			}
+			defer restoreConfig()
+			cplErrors := captureCPLErrors()
+			defer cplErrors.release()
+
+			rd := newReader(iReader)
+			if rd == nil {
+				return
+			}
+			defer rd.release()
+			for iGroup := range next {
+				cplErrors.lastMsg = ""
+				groups[iGroup], errs[iGroup] = reduce(iGroup, rd)
+				if errs[iGroup] != nil && len(cplErrors.lastMsg) > 0 {
+					errs[iGroup] = fmt.Errorf("%v: GDAL error: %s", errs[iGroup], cplErrors.lastMsg)
+				}
 			}
+		}(iReader)
+	}
+	for iGroup := 0; iGroup < nGroups; iGroup++ {
+		next <- iGroup
+	}
+	close(next)
+	wg.Wait()
+
+	if panicVal != nil {
+		panic(panicVal)
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
+	}
+	return groups, nil
+}
 
-		avgs = append(avgs, boundAvgs[:nCols]...)
+// interpolateAnchors returns the rows of all the bands, filling the
+// bands skipped between the anchors with a PCHIP interpolant fitted to
+// each column. The counts of the skipped bands are linearly
+// interpolated between the neighbouring anchors and are estimates only.
+func interpolateAnchors(anchors []strideAnchor, nBands int, nCols int) []*pb.TimeSeries {
+	xs := make([]float64, len(anchors))
+	for ia, anchor := range anchors {
+		xs[ia] = float64(anchor.iBand)
+	}
+	x := make([]float64, nBands)
+	for ib := range x {
+		x[ib] = float64(ib)
+	}
 
-		if bandStrides > 2 && len(boundAvgs) > nCols {
-			var beta []float64
-			var count []float64
-			for ic := 0; ic < nCols; ic++ {
-				beta_ := (boundAvgs[ic+nCols].Value - boundAvgs[ic].Value) / float64(bandStrides-1)
-				beta = append(beta, beta_)
+	avgs := make([]*pb.TimeSeries, nBands*nCols)
+	for ic := 0; ic < nCols; ic++ {
+		ys := make([]float64, len(anchors))
+		for ia, anchor := range anchors {
+			ys[ia] = anchor.row[ic].Value
+		}
+		vals := pchipInterpolate(xs, ys, x)
 
-				count_ := math.Round(float64(boundAvgs[ic].Count+boundAvgs[ic+nCols].Count) / float64(2))
-				count = append(count, count_)
+		ia := 0
+		for ib := 0; ib < nBands; ib++ {
+			for ia < len(anchors)-1 && anchors[ia+1].iBand <= ib {
+				ia++
 			}
-			for ip := 1; ip < bandStrides-1; ip++ {
-				for ic := 0; ic < nCols; ic++ {
-					beta_ := beta[ic]
-					val := boundAvgs[ic].Value + float64(ip)*beta_
-					avgs = append(avgs, &pb.TimeSeries{Value: val, Count: int32(count[ic])})
-				}
+			if anchors[ia].iBand == ib {
+				avgs[ib*nCols+ic] = anchors[ia].row[ic]
+				continue
+			}
+
+			count := float64(anchors[ia].row[ic].Count)
+			validCount := float64(anchors[ia].row[ic].ValidCount)
+			if ia < len(anchors)-1 {
+				next := anchors[ia+1]
+				frac := float64(ib-anchors[ia].iBand) / float64(next.iBand-anchors[ia].iBand)
+				count += frac * (float64(next.row[ic].Count) - count)
+				validCount += frac * (float64(next.row[ic].ValidCount) - validCount)
+			}
+			avgs[ib*nCols+ic] = &pb.TimeSeries{Value: vals[ib], Count: int32(math.Round(count)), ValidCount: int64(math.Round(validCount)), TotalMaskedCount: anchors[ia].row[ic].TotalMaskedCount}
+		}
+	}
+	return avgs
+}
+
+// maskUndefinedInterpolation flags the values of column ic of the bands
+// interpolated next to an anchor with an undefined value, i.e. a zero
+// count, by the NoData value and a zero count.
+func maskUndefinedInterpolation(avgs []*pb.TimeSeries, anchors []strideAnchor, nCols int, ic int, nodata float64) {
+	for ia := 0; ia < len(anchors)-1; ia++ {
+		if anchors[ia].row[ic].Count != 0 && anchors[ia+1].row[ic].Count != 0 {
+			continue
+		}
+		for ib := anchors[ia].iBand + 1; ib < anchors[ia+1].iBand; ib++ {
+			avgs[ib*nCols+ic] = &pb.TimeSeries{Value: nodata, Count: 0}
+		}
+	}
+}
+
+// readWindow reads the window of the bands as Float32 into dataBuf,
+// one band after another. Windows on an overview are read from the
+// overview of each band. The buffer holds the values of the bands one
+// after another in the given buffer data type.
+func readWindow(ds C.GDALDatasetH, dsDscr *DrillFileDescriptor, bandsRead []int32, buf unsafe.Pointer, bufType C.GDALDataType, rasterIOArg *C.GDALRasterIOExtraArg) C.CPLErr {
+	if dsDscr.OvrLevel < 0 {
+		return C.GDALDatasetRasterIOEx(ds, C.GF_Read, C.int(dsDscr.OffX), C.int(dsDscr.OffY), C.int(dsDscr.CountX), C.int(dsDscr.CountY), buf, C.int(dsDscr.CountX), C.int(dsDscr.CountY), bufType, C.int(len(bandsRead)), (*C.int)(unsafe.Pointer(&bandsRead[0])), 0, 0, 0, rasterIOArg)
+	}
+
+	bandBytes := uintptr(dsDscr.CountX) * uintptr(dsDscr.CountY) * uintptr(C.GDALGetDataTypeSizeBytes(bufType))
+	for i, band := range bandsRead {
+		ovrH := C.GDALGetOverview(C.GDALGetRasterBand(ds, C.int(band)), C.int(dsDscr.OvrLevel))
+		if ovrH == nil {
+			return C.CE_Failure
+		}
+		gerr := C.GDALRasterIOEx(ovrH, C.GF_Read, C.int(dsDscr.OffX), C.int(dsDscr.OffY), C.int(dsDscr.CountX), C.int(dsDscr.CountY), unsafe.Pointer(uintptr(buf)+uintptr(i)*bandBytes), C.int(dsDscr.CountX), C.int(dsDscr.CountY), bufType, 0, 0, rasterIOArg)
+		if gerr != C.CE_None {
+			return gerr
+		}
+	}
+	return C.CE_None
+}
+
+// readPixelWeights reads the weight band over the window of the
+// descriptor into its pixel weights. Pixels whose weight is NoData, zero
+// or negative are removed from the mask, hence they're excluded from all
+// the statistics rather than only from the weighted mean.
+func readPixelWeights(ds C.GDALDatasetH, dsDscr *DrillFileDescriptor, weightBand int32, defaultNoData float64, nodataTol noDataTolerance) error {
+	weights := make([]float32, int(dsDscr.CountX)*int(dsDscr.CountY))
+	bandsRead := []int32{weightBand}
+	if gerr := readWindow(ds, dsDscr, bandsRead, unsafe.Pointer(&weights[0]), C.GDT_Float32, nil); gerr != C.CE_None {
+		return fmt.Errorf("failed to read weight band %d", weightBand)
+	}
+
+	noData := float32(getBandNoData(ds, weightBand, defaultNoData))
+	for i, w := range weights {
+		if isNoData(w, noData, nodataTol) || w <= 0 {
+			dsDscr.Mask.clear(i)
+			weights[i] = 0
+		}
+	}
+	dsDscr.PixelWeights = weights
+	return nil
+}
+
+// coversRaster reports whether the window of the descriptor spans the
+// whole full resolution raster with all of its pixels within the mask.
+func coversRaster(ds C.GDALDatasetH, dsDscr *DrillFileDescriptor) bool {
+	if dsDscr.OvrLevel >= 0 || dsDscr.OffX != 0 || dsDscr.OffY != 0 {
+		return false
+	}
+	if dsDscr.CountX != int32(C.GDALGetRasterXSize(ds)) || dsDscr.CountY != int32(C.GDALGetRasterYSize(ds)) {
+		return false
+	}
+	for i := 0; i < dsDscr.Mask.len(); i++ {
+		if !dsDscr.Mask.isSet(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// gdalHistogram returns the histogram of the whole integer band computed
+// by GDAL with the binning of histogram, or nil if it fails. GDAL drops
+// the values equal to max, which are counted separately into the last
+// bin. NoData values are excluded by GDAL.
+func gdalHistogram(ds C.GDALDatasetH, band int32, bins int, min, max float64) []int64 {
+	bandH := C.GDALGetRasterBand(ds, C.int(band))
+	counts := make([]C.GUIntBig, bins)
+	if C.GDALGetRasterHistogramEx(bandH, C.double(min), C.double(max), C.int(bins), &counts[0], 0, 0, nil, nil) != C.CE_None {
+		return nil
+	}
+	var atMax C.GUIntBig
+	if C.GDALGetRasterHistogramEx(bandH, C.double(max), C.double(math.Nextafter(max, math.Inf(1))), 1, &atMax, 0, 0, nil, nil) != C.CE_None {
+		return nil
+	}
+
+	res := make([]int64, bins)
+	for i, count := range counts {
+		res[i] = int64(count)
+	}
+	res[bins-1] += int64(atMax)
+	return res
+}
+
+// complexComponent sets dataBuf to the part of the interleaved complex
+// values of the bands, i.e. the amplitude sqrt(re²+im²), the intensity
+// re²+im², the real or imaginary part or the phase in (-π, π]. NoData is
+// matched against the real part, in which case the NoData value is kept.
+func complexComponent(dataBuf []float32, complexBuf []float32, bandInfos []bandInfo, nodataTol noDataTolerance, part pb.ComplexPart) {
+	bandSize := len(dataBuf) / len(bandInfos)
+	for i := range dataBuf {
+		re, im := float64(complexBuf[2*i]), float64(complexBuf[2*i+1])
+		if noData := bandInfos[i/bandSize].noData; isNoData(float32(re), noData, nodataTol) {
+			dataBuf[i] = noData
+			continue
+		}
+		switch part {
+		case pb.ComplexPart_INTENSITY:
+			dataBuf[i] = float32(re*re + im*im)
+		case pb.ComplexPart_REAL:
+			dataBuf[i] = float32(re)
+		case pb.ComplexPart_IMAG:
+			dataBuf[i] = float32(im)
+		case pb.ComplexPart_PHASE:
+			dataBuf[i] = float32(math.Atan2(im, re))
+		default:
+			dataBuf[i] = float32(math.Hypot(re, im))
+		}
+	}
+}
+
+// supportedDataType reports whether bands of the GDAL data type can be
+// drilled, i.e. the integer, floating point and complex types.
+func supportedDataType(dType C.GDALDataType) bool {
+	switch dType {
+	case C.GDT_Byte, C.GDT_Int8, C.GDT_UInt16, C.GDT_Int16, C.GDT_UInt32, C.GDT_Int32, C.GDT_UInt64, C.GDT_Int64,
+		C.GDT_Float32, C.GDT_Float64, C.GDT_CInt16, C.GDT_CInt32, C.GDT_CFloat32, C.GDT_CFloat64:
+		return true
+	default:
+		return false
+	}
+}
+
+// widenWindow converts the raw Int32 or UInt32 values of the bands to
+// their exact float64 values in wideBuf and to float32 values in dataBuf.
+// Without raw values, e.g. for 64-bit integers, wideBuf already holds
+// the float64 values. NoData is matched against the wide values within
+// the tolerance. NoData pixels are set to the float32 NoData in dataBuf whereas valid
+// values rounding onto it are nudged to the next float32 value so that
+// they remain valid.
+func widenWindow(wideBuf []float64, dataBuf []float32, rawBuf []uint32, unsigned bool, bandInfos []bandInfo, nodataTol noDataTolerance) {
+	bandSize := len(dataBuf) / len(bandInfos)
+	for i := range dataBuf {
+		val := wideBuf[i]
+		if rawBuf != nil {
+			val = float64(int32(rawBuf[i]))
+			if unsigned {
+				val = float64(rawBuf[i])
+			}
+			wideBuf[i] = val
+		}
+
+		band := bandInfos[i/bandSize]
+		if isNoData64(val, band.rawNoData, nodataTol) {
+			dataBuf[i] = band.noData
+			continue
+		}
+		dataBuf[i] = float32(val)
+		if dataBuf[i] == band.noData {
+			toward := float32(math.Inf(1))
+			if val < float64(band.noData) {
+				toward = float32(math.Inf(-1))
 			}
+			dataBuf[i] = math.Nextafter32(dataBuf[i], toward)
 		}
+	}
+}
+
+// getBandScaleOffset returns the scale and offset converting the raw
+// pixel values of the band to physical values.
+func getBandScaleOffset(ds C.GDALDatasetH, band int32) (float32, float32) {
+	bandH := C.GDALGetRasterBand(ds, C.int(band))
+	scale := float32(C.GDALGetRasterScale(bandH, nil))
+	offset := float32(C.GDALGetRasterOffset(bandH, nil))
+	return scale, offset
+}
 
-		if len(boundAvgs) > nCols {
-			avgs = append(avgs, boundAvgs[len(boundAvgs)-nCols:]...)
+// bandUnit returns the unit of the values of the band, i.e. its unit
+// type or else its units metadata item, which holds the units attribute
+// of NetCDF variables. The raw values of bands with a scale and offset
+// aren't in that unit, hence no unit is returned unless the scale and
+// offset are applied.
+func bandUnit(ds C.GDALDatasetH, band int32, applyScaleOffset bool) string {
+	if scale, offset := getBandScaleOffset(ds, band); !applyScaleOffset && (scale != 1 || offset != 0) {
+		return ""
+	}
+
+	bandH := C.GDALGetRasterBand(ds, C.int(band))
+	unit := C.GoString(C.GDALGetRasterUnitType(bandH))
+	if unit == "" {
+		cKey := C.CString("units")
+		defer C.free(unsafe.Pointer(cKey))
+		if value := C.GDALGetMetadataItem(C.GDALMajorObjectH(bandH), cKey, nil); value != nil {
+			unit = C.GoString(value)
 		}
+	}
+	return unit
+}
 
+// getBandNoData returns the NoData value declared by the given band.
+// Stacked datasets may be assembled from granules with different fill
+// values, hence we fall back to defaultNoData only if the band doesn't
+// declare one.
+func getBandNoData(ds C.GDALDatasetH, band int32, defaultNoData float64) float64 {
+	var hasNoData C.int
+	nodata := C.GDALGetRasterNoDataValue(C.GDALGetRasterBand(ds, C.int(band)), &hasNoData)
+	if hasNoData == 0 {
+		return defaultNoData
 	}
-	syscall.Getrusage(syscall.RUSAGE_SELF, &resUsage1)
-	metrics.UserTime = resUsage1.Utime.Nano() - resUsage0.Utime.Nano()
-	metrics.SysTime = resUsage1.Stime.Nano() - resUsage0.Stime.Nano()
+	return float64(nodata)
+}
 
-	nRows := len(avgs) / nCols
-	return &pb.Result{TimeSeries: avgs, Raster: &pb.Raster{NoData: float64(nodata)}, Shape: []int32{int32(nRows), int32(nCols)}, Error: "OK", Metrics: metrics}
+// createMask burns the geometry with the burn value onto a mask of the
+// window of the given type, either every pixel touched by the geometry
+// or only the pixels whose center is within it.
+func createMask(ds C.GDALDatasetH, geot []float64, g C.OGRGeometryH, offsetX, offsetY, countX, countY int32, allTouched bool, maskType pb.MaskType, burnValue float64) (*MaskBuffer, error) {
+	mask := newMaskBuffer(maskType, int(countX)*int(countY))
+	var canvas unsafe.Pointer
+	var dataType string
+	switch {
+	case mask.Int16 != nil:
+		canvas, dataType = unsafe.Pointer(&mask.Int16[0]), "Int16"
+	case mask.Float32 != nil:
+		canvas, dataType = unsafe.Pointer(&mask.Float32[0]), "Float32"
+	default:
+		canvas, dataType = unsafe.Pointer(&mask.Byte[0]), "Byte"
+	}
+	if err := burnGeometries(ds, geot, []C.OGRGeometryH{g}, []float64{burnValue}, canvas, dataType, offsetX, offsetY, countX, countY, 1, allTouched); err != nil {
+		return nil, err
+	}
+	return mask, nil
 }
 
-func computeDeciles(decileCount int, dataBuf []float32, bandSize int, bandOffset int, nodata float32, dsDscr *DrillFileDescriptor) []float32 {
-	deciles := make([]float32, decileCount)
+// createCoverageWeights estimates the fraction of each pixel of the
+// window covered by the geometry. The geometry is rasterized onto a
+// grid supersampled by coverageSupersampling in each direction using
+// pixel-center semantics, and the burnt sub-pixels are counted for
+// every pixel of the window.
+func createCoverageWeights(ds C.GDALDatasetH, geot []float64, g C.OGRGeometryH, offsetX, offsetY, countX, countY int32) ([]float32, error) {
+	const k = coverageSupersampling
+	canvas, err := rasterizeGeometry(ds, geot, g, offsetX, offsetY, countX, countY, k, false)
+	if err != nil {
+		return nil, err
+	}
 
-	var buf []float32
-	for i := 0; i < bandSize; i++ {
-		if dsDscr.Mask[i] == 255 && dataBuf[i+bandOffset] != nodata {
-			buf = append(buf, dataBuf[i+bandOffset])
+	weights := make([]float32, int(countX)*int(countY))
+	superX := int(countX) * k
+	for iy := 0; iy < int(countY)*k; iy++ {
+		for ix := 0; ix < superX; ix++ {
+			if canvas[iy*superX+ix] != 0 {
+				weights[(iy/k)*int(countX)+ix/k]++
+			}
 		}
 	}
 
-	sort.Slice(buf, func(i, j int) bool { return buf[i] <= buf[j] })
-	step := len(buf) / (decileCount + 1)
-	if step > 0 {
-		isEven := len(buf)%(decileCount+1) == 0
+	for i := range weights {
+		weights[i] /= k * k
+	}
 
-		for i := 0; i < decileCount; i++ {
-			iStep := (i + 1) * step
-			de := buf[iStep]
-			if isEven {
-				de = (buf[iStep] + buf[iStep+1]) / 2.0
-			}
+	return weights, nil
+}
 
-			deciles[i] = de
-		}
-	} else {
-		padding := make(map[int]int)
-		for i := 0; i < decileCount; i++ {
-			idx := i % len(buf)
-			if _, found := padding[idx]; !found {
-				padding[idx] = 0
-			}
-			padding[idx]++
-		}
+// rasterizeGeometry burns the geometry onto a window of the grid with
+// geotransform geot and each pixel split into supersample x supersample
+// sub-pixels.
+func rasterizeGeometry(ds C.GDALDatasetH, geot []float64, g C.OGRGeometryH, offsetX, offsetY, countX, countY int32, supersample int, allTouched bool) ([]uint8, error) {
+	canvas := make([]uint8, int(countX)*int(countY)*supersample*supersample)
+	if err := burnGeometries(ds, geot, []C.OGRGeometryH{g}, []float64{255}, unsafe.Pointer(&canvas[0]), "Byte", offsetX, offsetY, countX, countY, supersample, allTouched); err != nil {
+		return nil, err
+	}
+	return canvas, nil
+}
 
-		idx := 0
-		for i := 0; i < len(buf); i++ {
-			for p := 0; p < padding[i]; p++ {
-				deciles[idx] = buf[i]
-				idx++
-			}
-		}
+// createZones burns every feature of the collection with the zone of its
+// zone ID in a single pass, i.e. the index of the distinct ID plus one,
+// later features overwriting the earlier ones where they overlap. Pixels
+// outside of the features are left zero.
+func createZones(ds C.GDALDatasetH, geot []float64, features C.OGRGeometryH, zoneIDs []int32, offsetX, offsetY, countX, countY int32, allTouched bool) ([]int32, error) {
+	_, index := distinctZoneIDs(zoneIDs)
+	geoms := make([]C.OGRGeometryH, len(index))
+	burnValues := make([]float64, len(index))
+	for i, iZone := range index {
+		geoms[i] = C.OGR_G_GetGeometryRef(features, C.int(i))
+		burnValues[i] = float64(iZone + 1)
 	}
 
-	return deciles
+	zones := make([]int32, int(countX)*int(countY))
+	if err := burnGeometries(ds, geot, geoms, burnValues, unsafe.Pointer(&zones[0]), "Int32", offsetX, offsetY, countX, countY, 1, allTouched); err != nil {
+		return nil, err
+	}
+	return zones, nil
 }
 
-func createMask(ds C.GDALDatasetH, g C.OGRGeometryH, offsetX, offsetY, countX, countY int32) ([]uint8, error) {
-	canvas := make([]uint8, countX*countY)
+// burnGeometries burns the geometries with their burn values onto the
+// canvas of the given data type, which covers a window of the grid with
+// geotransform geot and each pixel split into supersample x supersample
+// sub-pixels.
+func burnGeometries(ds C.GDALDatasetH, geot []float64, geoms []C.OGRGeometryH, burnValues []float64, canvas unsafe.Pointer, dataType string, offsetX, offsetY, countX, countY int32, supersample int, allTouched bool) error {
+	countX *= int32(supersample)
+	countY *= int32(supersample)
 
-	memStr := fmt.Sprintf("MEM:::DATAPOINTER=%d,PIXELS=%d,LINES=%d,DATATYPE=Byte", unsafe.Pointer(&canvas[0]), countX, countY)
+	memStr := fmt.Sprintf("MEM:::DATAPOINTER=%d,PIXELS=%d,LINES=%d,DATATYPE=%s", canvas, countX, countY, dataType)
 	memStrC := C.CString(memStr)
 	defer C.free(unsafe.Pointer(memStrC))
 	hDstDS := C.GDALOpen(memStrC, C.GA_Update)
 	if hDstDS == nil {
-		return nil, fmt.Errorf("Couldn't create memory driver")
+		return fmt.Errorf("Couldn't create memory driver")
 	}
 	defer C.GDALClose(hDstDS)
 
@@ -288,58 +2098,63 @@ func createMask(ds C.GDALDatasetH, g C.OGRGeometryH, offsetX, offsetY, countX, c
 	if gdalErr = C.GDALSetProjection(hDstDS, C.GDALGetProjectionRef(ds)); gdalErr != 0 {
 		msg := fmt.Errorf("Couldn't set a projection in the mem raster %v", gdalErr)
 		log.Println(msg)
-		return nil, msg
+		return msg
 	}
 
-	geoTrans := make([]float64, 6)
-	if gdalErr = C.GDALGetGeoTransform(ds, (*C.double)(&geoTrans[0])); gdalErr != 0 {
-		msg := fmt.Errorf("Couldn't get the geotransform from the source dataset %v", gdalErr)
-		log.Println(msg)
-		return nil, msg
+	// The rotation terms shift the origin of rotated rasters as well
+	geoTrans := windowGeoTransform(geot, offsetX, offsetY)
+	for _, i := range []int{1, 2, 4, 5} {
+		geoTrans[i] /= float64(supersample)
 	}
 
-	geoTrans[0] += geoTrans[1] * float64(offsetX)
-	geoTrans[3] += geoTrans[5] * float64(offsetY)
-
 	if gdalErr = C.GDALSetGeoTransform(hDstDS, (*C.double)(&geoTrans[0])); gdalErr != 0 {
 		msg := fmt.Errorf("Couldn't set the geotransform on the destination dataset %v", gdalErr)
 		log.Println(msg)
-		return nil, msg
+		return msg
 	}
 
-	ic := C.OGR_G_Clone(g)
-	defer C.OGR_G_DestroyGeometry(ic)
-
-	geomBurnValue := C.double(255)
+	pahGeomList := make([]C.OGRGeometryH, len(geoms))
+	geomBurnValues := make([]C.double, len(geoms))
+	for i, g := range geoms {
+		pahGeomList[i] = C.OGR_G_Clone(g)
+		defer C.OGR_G_DestroyGeometry(pahGeomList[i])
+		geomBurnValues[i] = C.double(burnValues[i])
+	}
 	panBandList := []C.int{C.int(1)}
-	pahGeomList := []C.OGRGeometryH{ic}
 
-	opts := []*C.char{C.CString("ALL_TOUCHED=TRUE"), nil}
-	defer C.free(unsafe.Pointer(opts[0]))
+	opts := []*C.char{nil}
+	if allTouched {
+		opts = []*C.char{C.CString("ALL_TOUCHED=TRUE"), nil}
+		defer C.free(unsafe.Pointer(opts[0]))
+	}
 
-	if gdalErr = C.GDALRasterizeGeometries(hDstDS, 1, &panBandList[0], 1, &pahGeomList[0], nil, nil, &geomBurnValue, &opts[0], nil, nil); gdalErr != 0 {
+	if gdalErr = C.GDALRasterizeGeometries(hDstDS, 1, &panBandList[0], C.int(len(geoms)), &pahGeomList[0], nil, nil, &geomBurnValues[0], &opts[0], nil, nil); gdalErr != 0 {
 		msg := fmt.Errorf("GDALRasterizeGeometry error %v", gdalErr)
 		log.Println(msg)
-		return nil, msg
+		return msg
 	}
 
-	return canvas, nil
+	return nil
 }
 
+// envelopePolygon returns the footprint of the raster, i.e. the polygon
+// of its four corners, which is rotated along with rotated rasters.
 func envelopePolygon(hDS C.GDALDatasetH) (C.OGRGeometryH, error) {
 	geoTrans := make([]float64, 6)
 	C.GDALGetGeoTransform(hDS, (*C.double)(&geoTrans[0]))
 
-	var ulX, ulY C.double
-	C.GDALApplyGeoTransform((*C.double)(&geoTrans[0]), C.double(0), C.double(0), &ulX, &ulY)
-	var lrX, lrY C.double
-	C.GDALApplyGeoTransform((*C.double)(&geoTrans[0]), C.double(C.GDALGetRasterXSize(hDS)), C.double(C.GDALGetRasterYSize(hDS)), &lrX, &lrY)
+	xSize := C.double(C.GDALGetRasterXSize(hDS))
+	ySize := C.double(C.GDALGetRasterYSize(hDS))
+	var corners [4][2]C.double
+	for i, pixel := range [4][2]C.double{{0, 0}, {0, ySize}, {xSize, ySize}, {xSize, 0}} {
+		C.GDALApplyGeoTransform((*C.double)(&geoTrans[0]), pixel[0], pixel[1], &corners[i][0], &corners[i][1])
+	}
 
-	polyWKT := fmt.Sprintf("POLYGON ((%f %f,%f %f,%f %f,%f %f,%f %f))", ulX, ulY,
-		ulX, lrY,
-		lrX, lrY,
-		lrX, ulY,
-		ulX, ulY)
+	polyWKT := fmt.Sprintf("POLYGON ((%f %f,%f %f,%f %f,%f %f,%f %f))", corners[0][0], corners[0][1],
+		corners[1][0], corners[1][1],
+		corners[2][0], corners[2][1],
+		corners[3][0], corners[3][1],
+		corners[0][0], corners[0][1])
 
 	ppszData := C.CString(polyWKT)
 	ppszDataTmp := ppszData
@@ -360,23 +2175,122 @@ func envelopePolygon(hDS C.GDALDatasetH) (C.OGRGeometryH, error) {
 	return hGeom, nil
 }
 
-func getDrillFileDescriptor(ds C.GDALDatasetH, g C.OGRGeometryH) (*DrillFileDescriptor, error) {
-	gCopy := C.OGR_G_Buffer(g, C.double(0.0), C.int(30))
-	if C.OGR_G_IsEmpty(gCopy) == C.int(1) {
+func getDrillFileDescriptor(ds C.GDALDatasetH, g C.OGRGeometryH, in *pb.GeoRPCGranule) (*DrillFileDescriptor, error) {
+	isAreal := C.OGR_G_GetDimension(g) >= 2
+
+	// The number of segments approximating a quarter circle of the
+	// buffers trades the fidelity of curved boundaries for their cost
+	bufferSegments := int(in.BufferSegments)
+	if bufferSegments <= 0 {
+		bufferSegments = defaultBufferSegments
+	}
+
+	// The features of zones are burnt from a copy of the collection,
+	// whereas the window and the mask are computed from their union.
+	var zCopy C.OGRGeometryH
+	if len(in.ZoneIDs) > 0 {
+		zCopy = C.OGR_G_Clone(g)
+		defer func() { C.OGR_G_DestroyGeometry(zCopy) }()
+	}
+
+	// The zero-distance buffer fixes the topology of invalid polygons,
+	// e.g. self-intersecting rings, but may alter valid ones, hence
+	// valid geometries are used as they are. It also dissolves the
+	// features of zones, which may overlap.
+	var gCopy C.OGRGeometryH
+	if isAreal && (C.OGR_G_IsValid(g) == 0 || zCopy != nil) {
+		gCopy = fixTopology(g, bufferSegments)
+	} else {
 		gCopy = C.OGR_G_Clone(g)
 	}
+	if gCopy == nil {
+		return nil, fmt.Errorf("failed to copy the geometry")
+	}
 
-	defer C.OGR_G_DestroyGeometry(gCopy)
+	defer func() { C.OGR_G_DestroyGeometry(gCopy) }()
 
+	var warnings []string
 	if C.GoString(C.GDALGetProjectionRef(ds)) != "" {
-		desSRS := C.OSRNewSpatialReference(C.GDALGetProjectionRef(ds))
-		defer C.OSRDestroySpatialReference(desSRS)
-		srcSRS := C.OSRNewSpatialReference(cWGS84WKT)
-		defer C.OSRDestroySpatialReference(srcSRS)
-		C.OSRSetAxisMappingStrategy(srcSRS, C.OAMS_TRADITIONAL_GIS_ORDER)
-		trans := C.OCTNewCoordinateTransformation(srcSRS, desSRS)
-		C.OGR_G_Transform(gCopy, trans)
-		C.OCTDestroyCoordinateTransformation(trans)
+		srcSRS := C.OGR_G_GetSpatialReference(g)
+		if srcSRS == nil {
+			srcSRS = C.OSRNewSpatialReference(cWGS84WKT)
+			defer C.OSRDestroySpatialReference(srcSRS)
+			C.OSRSetAxisMappingStrategy(srcSRS, C.OAMS_TRADITIONAL_GIS_ORDER)
+		}
+
+		// A polygon crossing the antimeridian would otherwise be
+		// projected onto a window spanning the whole globe.
+		if isAreal && C.OSRIsGeographic(srcSRS) != 0 {
+			if split := splitAtAntimeridian(gCopy); split != nil {
+				C.OGR_G_DestroyGeometry(gCopy)
+				gCopy = split
+			}
+			if zCopy != nil {
+				features := C.OGR_G_CreateGeometry(C.wkbGeometryCollection)
+				for i := 0; i < int(C.OGR_G_GetGeometryCount(zCopy)); i++ {
+					feature := C.OGR_G_GetGeometryRef(zCopy, C.int(i))
+					if split := splitAtAntimeridian(feature); split != nil {
+						C.OGR_G_AddGeometryDirectly(features, split)
+					} else {
+						C.OGR_G_AddGeometry(features, feature)
+					}
+				}
+				C.OGR_G_DestroyGeometry(zCopy)
+				zCopy = features
+			}
+		}
+		ct, transKey, err := acquireTransform(srcSRS, C.GDALGetProjectionRef(ds), in.CoordinateOperation, in.AreaOfInterest)
+		if err != nil {
+			return nil, err
+		}
+		C.OGR_G_Transform(gCopy, ct.trans)
+		if zCopy != nil {
+			C.OGR_G_Transform(zCopy, ct.trans)
+		}
+		releaseTransform(ct, transKey)
+		if ct.ballpark {
+			warnings = append(warnings, "geometry reprojected with a ballpark transformation ignoring datum shifts, the mask may be off by tens of meters")
+		}
+
+		// Geographic datasets use either longitude convention whereas
+		// the geometry may use the other one, in which case it wouldn't
+		// overlap the dataset.
+		if shift := longitudeShift(ds); shift != nil {
+			mapVertices(gCopy, shift)
+			if zCopy != nil {
+				mapVertices(zCopy, shift)
+			}
+		}
+	}
+
+	// The geometry is buffered in the units of the dataset SRS, hence
+	// the distance is converted to degrees for geographic datasets.
+	// Buffered points and lines are drilled as polygons.
+	if in.BufferMeters != 0 {
+		dist := in.BufferMeters
+		if dstSRS := C.OSRNewSpatialReference(C.GDALGetProjectionRef(ds)); dstSRS != nil {
+			if C.OSRIsGeographic(dstSRS) != 0 {
+				dist /= metersPerDegree
+			}
+			C.OSRDestroySpatialReference(dstSRS)
+		}
+		if buffered := C.OGR_G_Buffer(gCopy, C.double(dist), C.int(bufferSegments)); buffered != nil {
+			C.OGR_G_DestroyGeometry(gCopy)
+			gCopy = buffered
+			isAreal = true
+		}
+	}
+
+	// Points and lines have no area to rasterize, hence the pixels
+	// they fall on are sampled directly.
+	if !isAreal {
+		dsDscr := getSampleFileDescriptor(ds, gCopy, in.Resampling)
+		if dsDscr == nil {
+			return nil, errNoOverlap
+		}
+		dsDscr.Warnings = warnings
+		dsDscr.AreaToM2 = areaToSquareMeters(ds, gCopy)
+		return dsDscr, nil
 	}
 
 	fileEnv, err := envelopePolygon(ds)
@@ -385,39 +2299,663 @@ func getDrillFileDescriptor(ds C.GDALDatasetH, g C.OGRGeometryH) (*DrillFileDesc
 	}
 	defer C.OGR_G_DestroyGeometry(fileEnv)
 
+	// The intersection fails on geometries whose topology couldn't be
+	// fixed, which isn't mistaken for a geometry outside the dataset
 	inters := C.OGR_G_Intersection(gCopy, fileEnv)
+	if inters == nil {
+		return nil, fmt.Errorf("failed to intersect the geometry with the dataset, the geometry may be invalid")
+	}
 	defer C.OGR_G_DestroyGeometry(inters)
+	if C.OGR_G_IsEmpty(inters) != 0 {
+		return nil, errNoOverlap
+	}
 
 	var env C.OGREnvelope
 	C.OGR_G_GetEnvelope(inters, &env)
 
 	geot := make([]float64, 6)
-	C.GDALGetGeoTransform(ds, (*C.double)(&geot[0]))
+	if gdalErr := C.GDALGetGeoTransform(ds, (*C.double)(&geot[0])); gdalErr != 0 {
+		msg := fmt.Errorf("Couldn't get the geotransform from the source dataset %v", gdalErr)
+		log.Println(msg)
+		return nil, msg
+	}
 
 	invGeot := make([]float64, 6)
 	C.GDALInvGeoTransform((*C.double)(&geot[0]), (*C.double)(&invGeot[0]))
 
-	var offMinX, offMinY, offMaxX, offMaxY C.double
-	C.GDALApplyGeoTransform((*C.double)(&invGeot[0]), env.MinX, env.MinY, &offMinX, &offMinY)
-	C.GDALApplyGeoTransform((*C.double)(&invGeot[0]), env.MaxX, env.MaxY, &offMaxX, &offMaxY)
+	// The window spans the four corners of the envelope, which aren't
+	// the extremes of the pixel coordinates for rotated rasters.
+	offMinX, offMinY := math.Inf(1), math.Inf(1)
+	offMaxX, offMaxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range [][2]C.double{{env.MinX, env.MinY}, {env.MaxX, env.MinY}, {env.MaxX, env.MaxY}, {env.MinX, env.MaxY}} {
+		var px, py C.double
+		C.GDALApplyGeoTransform((*C.double)(&invGeot[0]), corner[0], corner[1], &px, &py)
+		offMinX, offMaxX = math.Min(offMinX, float64(px)), math.Max(offMaxX, float64(px))
+		offMinY, offMaxY = math.Min(offMinY, float64(py)), math.Max(offMaxY, float64(py))
+	}
 
-	offsetX := int32(math.Min(float64(offMinX), float64(offMaxX)))
-	offsetY := int32(math.Min(float64(offMinY), float64(offMaxY)))
-	countX := int32(math.Max(float64(offMinX), float64(offMaxX))) - offsetX
-	countY := int32(math.Max(float64(offMinY), float64(offMaxY))) - offsetY
+	offsetX := int32(offMinX)
+	offsetY := int32(offMinY)
+	countX := int32(offMaxX) - offsetX
+	countY := int32(offMaxY) - offsetY
 	if countX == 0 {
 		countX++
 	}
 	if countY == 0 {
 		countY++
 	}
-	if offsetX < 0 {
-		offsetX = 0
+
+	// The envelope may overshoot the raster edges by rounding errors
+	offsetX, countX = clampWindow(offsetX, countX, int32(C.GDALGetRasterXSize(ds)))
+	offsetY, countY = clampWindow(offsetY, countY, int32(C.GDALGetRasterYSize(ds)))
+
+	// A fixed pyramid level keeps the scale consistent across datasets
+	if in.OverviewLevel < 0 {
+		return nil, fmt.Errorf("negative overview level: %d", in.OverviewLevel)
+	}
+	if nOvr := int32(C.GDALGetOverviewCount(C.GDALGetRasterBand(ds, C.int(1)))); in.OverviewLevel > nOvr {
+		return nil, fmt.Errorf("overview level %d requested but the dataset has %d overviews", in.OverviewLevel, nOvr)
+	}
+	ovrLevel := selectOverview(ds, in, int64(countX)*int64(countY))
+	if ovrLevel >= 0 {
+		offsetX, offsetY, countX, countY = scaleToOverview(ds, ovrLevel, geot, offsetX, offsetY, countX, countY)
+	}
+
+	// Reading whole blocks avoids decoding the blocks straddling the
+	// window edges twice for tiled and compressed formats. The mask is
+	// rasterized over the padded window, hence the padding is masked out.
+	if in.BlockAligned {
+		bandH := C.GDALGetRasterBand(ds, C.int(1))
+		if ovrLevel >= 0 {
+			bandH = C.GDALGetOverview(bandH, C.int(ovrLevel))
+		}
+		var blockX, blockY C.int
+		C.GDALGetBlockSize(bandH, &blockX, &blockY)
+		offsetX, countX = alignToBlocks(offsetX, countX, int32(blockX), int32(C.GDALGetRasterBandXSize(bandH)))
+		offsetY, countY = alignToBlocks(offsetY, countY, int32(blockY), int32(C.GDALGetRasterBandYSize(bandH)))
+	}
+
+	burnValue, err := maskBurnValue(in)
+	if err != nil {
+		return nil, err
+	}
+
+	// The mask and the coverage weights are the first buffers of the
+	// size of the window allocated
+	maskBytes := int64(1)
+	switch in.MaskType {
+	case pb.MaskType_MASK_INT16:
+		maskBytes = 2
+	case pb.MaskType_MASK_FLOAT32:
+		maskBytes = 4
+	}
+	if in.FractionalCoverage {
+		maskBytes += 4 + coverageSupersampling*coverageSupersampling
+	}
+	if zCopy != nil {
+		maskBytes += 4
+	}
+	interiorDeciles := in.InteriorDeciles && !in.PixelCenterMask
+	if interiorDeciles {
+		maskBytes++
+	}
+	if err := checkDrillMemory(countX, countY, maskBytes, in); err != nil {
+		return nil, err
+	}
+
+	// Every pixel touched by the geometry is included by default, which
+	// biases the statistics of small polygons towards their surroundings.
+	// Pixel center semantics include about as many pixels outside the
+	// boundary as they exclude within it, but small polygons may not
+	// contain any pixel center at all, in which case no pixel is drilled.
+	// The buffers derived from the geometry alone are reused across
+	// requests drilling the same geometry over the same grid.
+	maskKey := fmt.Sprintf("%x\n%v\n%d %d %d %d\n%v %v %v %v %v", maskDigest(ds, gCopy, zCopy, in.ZoneIDs), geot, offsetX, offsetY, countX, countY, in.PixelCenterMask, interiorDeciles, in.FractionalCoverage, in.MaskType, burnValue)
+	cached, maskCached := loadMask(maskKey)
+	if !maskCached {
+		cached = &cachedMask{}
+		cached.mask, err = createMask(ds, geot, gCopy, offsetX, offsetY, countX, countY, !in.PixelCenterMask, in.MaskType, burnValue)
+		if err != nil {
+			return nil, err
+		}
+
+		if interiorDeciles {
+			cached.interiorMask, err = createMask(ds, geot, gCopy, offsetX, offsetY, countX, countY, false, pb.MaskType_MASK_BYTE, defaultMaskBurnValue)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if in.FractionalCoverage {
+			cached.weights, err = createCoverageWeights(ds, geot, gCopy, offsetX, offsetY, countX, countY)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// The features are burnt with their zones in a single pass, hence
+		// the pixels of all the zones are reduced from the same reads.
+		if zCopy != nil {
+			cached.zones, err = createZones(ds, geot, zCopy, in.ZoneIDs, offsetX, offsetY, countX, countY, !in.PixelCenterMask)
+			if err != nil {
+				return nil, err
+			}
+		}
+		storeMask(maskKey, cached)
+	}
+
+	return &DrillFileDescriptor{
+		OffX: offsetX, OffY: offsetY, CountX: countX, CountY: countY,
+		Mask: cached.mask, Weights: cached.weights,
+		OvrLevel: ovrLevel, GeoTransform: geot,
+		Warnings:     warnings,
+		InteriorMask: cached.interiorMask,
+		Zones:        cached.zones,
+		GeometryArea: float64(C.OGR_G_Area(inters)),
+		AreaToM2:     areaToSquareMeters(ds, inters),
+		MaskCached:   maskCached,
+	}, nil
+}
+
+// areaToSquareMeters returns the number of square meters per square unit
+// of the dataset SRS around the geometry, or zero if unknown, e.g. for a
+// dataset without SRS. The scale of geographic datasets depends on the
+// latitude, hence it's the ratio of the area of the geometry on a
+// Lambert azimuthal equal-area projection centered on it to its area in
+// square degrees, which is unknown for points and lines.
+func areaToSquareMeters(ds C.GDALDatasetH, g C.OGRGeometryH) float64 {
+	dsSRS := C.OSRNewSpatialReference(C.GDALGetProjectionRef(ds))
+	if dsSRS == nil {
+		return 0
+	}
+	defer C.OSRDestroySpatialReference(dsSRS)
+	if C.OSRIsProjected(dsSRS) != 0 {
+		toMeters := float64(C.OSRGetLinearUnits(dsSRS, nil))
+		return toMeters * toMeters
+	}
+
+	area := float64(C.OGR_G_Area(g))
+	if C.OSRIsGeographic(dsSRS) == 0 || area == 0 {
+		return 0
+	}
+
+	// The geometry follows the axis order of the geotransform
+	C.OSRSetAxisMappingStrategy(dsSRS, C.OAMS_TRADITIONAL_GIS_ORDER)
+	var env C.OGREnvelope
+	C.OGR_G_GetEnvelope(g, &env)
+	laea := C.OSRClone(dsSRS)
+	if laea == nil {
+		return 0
+	}
+	defer C.OSRDestroySpatialReference(laea)
+	C.OSRSetAxisMappingStrategy(laea, C.OAMS_TRADITIONAL_GIS_ORDER)
+	if C.OSRSetLAEA(laea, (env.MinY+env.MaxY)/2, (env.MinX+env.MaxX)/2, 0, 0) != C.OGRERR_NONE {
+		return 0
+	}
+
+	ct := C.OCTNewCoordinateTransformation(dsSRS, laea)
+	if ct == nil {
+		return 0
+	}
+	defer C.OCTDestroyCoordinateTransformation(ct)
+	projected := C.OGR_G_Clone(g)
+	defer C.OGR_G_DestroyGeometry(projected)
+	if C.OGR_G_Transform(projected, ct) != C.OGRERR_NONE {
+		return 0
+	}
+	return float64(C.OGR_G_Area(projected)) / area
+}
+
+// maskDigest returns the SHA-256 digest of the geometry and of the
+// features of the zones along with their IDs, all in the dataset SRS,
+// and of the dataset SRS itself.
+func maskDigest(ds C.GDALDatasetH, g C.OGRGeometryH, zones C.OGRGeometryH, zoneIDs []int32) []byte {
+	h := sha256.New()
+	for _, geom := range []C.OGRGeometryH{g, zones} {
+		if geom == nil {
+			continue
+		}
+		wkb := make([]byte, int(C.OGR_G_WkbSize(geom)))
+		if len(wkb) > 0 {
+			C.OGR_G_ExportToWkb(geom, C.wkbNDR, (*C.uchar)(unsafe.Pointer(&wkb[0])))
+		}
+		h.Write(wkb)
+	}
+	fmt.Fprintf(h, "%v\n%s", zoneIDs, C.GoString(C.GDALGetProjectionRef(ds)))
+	return h.Sum(nil)
+}
+
+// defaultBufferSegments is the default number of segments approximating
+// a quarter circle of the buffered geometries.
+const defaultBufferSegments = 30
+
+// defaultMaxDrillMemory is the default cap in bytes on the buffers of
+// the size of the window allocated by a drill.
+const defaultMaxDrillMemory = 4 << 30
+
+// checkDrillMemory returns an error if a window of countX x countY pixels
+// needing bytesPerPixel bytes per pixel exceeds the memory cap of the
+// request. The size is computed in int64 as it may overflow int32 for
+// continental geometries drilled at the full resolution.
+func checkDrillMemory(countX, countY int32, bytesPerPixel int64, in *pb.GeoRPCGranule) error {
+	maxMemory := in.MaxDrillMemory
+	if maxMemory <= 0 {
+		maxMemory = defaultMaxDrillMemory
+	}
+	size := int64(countX) * int64(countY) * bytesPerPixel
+	if size > maxMemory {
+		return fmt.Errorf("window of %dx%d pixels needs %d bytes, exceeding the cap of %d bytes: drill an overview with ApproxScale or split the geometry", countX, countY, size, maxMemory)
+	}
+	return nil
+}
+
+// defaultMaxBands is the default cap on the number of bands read by a
+// drill, each of them being a RasterIO call over the window.
+const defaultMaxBands = 10000
+
+// stridedBandCount returns the number of bands read out of nBands with
+// the given strides, i.e. the first and the last band of every stride.
+func stridedBandCount(nBands int, bandStrides int) int {
+	if bandStrides <= 1 {
+		return nBands
+	}
+	count := (nBands / bandStrides) * 2
+	if rem := nBands % bandStrides; rem == 1 {
+		count++
+	} else if rem > 1 {
+		count += 2
+	}
+	return count
+}
+
+// checkDrillBands returns an error if drilling nBands bands with the
+// given strides reads more bands than the cap of the request, which
+// would otherwise crawl through as many RasterIO calls until timing out.
+func checkDrillBands(nBands int, bandStrides int, in *pb.GeoRPCGranule) error {
+	maxBands := int(in.MaxBands)
+	if maxBands <= 0 {
+		maxBands = defaultMaxBands
+	}
+	nRead := stridedBandCount(nBands, bandStrides)
+	if nRead <= maxBands {
+		return nil
+	}
+
+	// The smallest strides reading at most maxBands bands, if any
+	minStrides := 3
+	for minStrides < nBands && stridedBandCount(nBands, minStrides) > maxBands {
+		minStrides++
+	}
+	if stridedBandCount(nBands, minStrides) > maxBands {
+		return fmt.Errorf("%d bands to read, exceeding the cap of %d bands: split the request", nRead, maxBands)
+	}
+	return fmt.Errorf("%d bands to read, exceeding the cap of %d bands: set bandStrides to at least %d or split the request", nRead, maxBands, minStrides)
+}
+
+// windowGeoTransform returns the geotransform of the window of the grid
+// with geotransform geot whose top left pixel is (offX, offY).
+func windowGeoTransform(geot []float64, offX, offY int32) []float64 {
+	winGeot := make([]float64, 6)
+	copy(winGeot, geot)
+	winGeot[0] += geot[1]*float64(offX) + geot[2]*float64(offY)
+	winGeot[3] += geot[4]*float64(offX) + geot[5]*float64(offY)
+	return winGeot
+}
+
+// clampWindow bounds the window [offset, offset+count) along an axis
+// to the raster size, keeping at least one pixel.
+func clampWindow(offset, count, size int32) (int32, int32) {
+	end := offset + count
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > size-1 {
+		offset = size - 1
+	}
+	if end > size {
+		end = size
+	}
+	if end <= offset {
+		end = offset + 1
+	}
+	return offset, end - offset
+}
+
+// alignToBlocks expands the window [offset, offset+count) along an axis
+// outwards to the boundaries of the blocks of the given size, within the
+// raster size.
+func alignToBlocks(offset, count, block, size int32) (int32, int32) {
+	if block <= 1 {
+		return offset, count
+	}
+
+	end := (offset + count + block - 1) / block * block
+	if end > size {
+		end = size
+	}
+	offset = offset / block * block
+	return offset, end - offset
+}
+
+// selectOverview returns the index of the coarsest overview whose
+// downsampling factor doesn't exceed the approximation requested by the
+// granule, or -1 if the full resolution raster should be read. The
+// approximation is either given as a scale factor or derived from the
+// ratio between the full resolution window size and the pixel budget.
+// An overview level requested explicitly, numbered from 1 like the
+// overview level of the result, takes precedence over the approximation.
+func selectOverview(ds C.GDALDatasetH, in *pb.GeoRPCGranule, windowPixels int64) int {
+	if in.OverviewLevel > 0 {
+		return int(in.OverviewLevel) - 1
+	}
+
+	scale := float64(in.ApproxScale)
+	if in.PixelBudget > 0 && windowPixels > in.PixelBudget {
+		scale = math.Max(scale, math.Sqrt(float64(windowPixels)/float64(in.PixelBudget)))
+	}
+	if scale <= 1 {
+		return -1
+	}
+
+	bandH := C.GDALGetRasterBand(ds, C.int(1))
+	xSize := float64(C.GDALGetRasterBandXSize(bandH))
+
+	ovrLevel := -1
+	bestFactor := 1.0
+	for i := 0; i < int(C.GDALGetOverviewCount(bandH)); i++ {
+		ovrH := C.GDALGetOverview(bandH, C.int(i))
+		if ovrH == nil {
+			continue
+		}
+		factor := xSize / float64(C.GDALGetRasterBandXSize(ovrH))
+		if factor <= scale && factor > bestFactor {
+			ovrLevel = i
+			bestFactor = factor
+		}
+	}
+	return ovrLevel
+}
+
+// scaleToOverview converts a full resolution window into the pixel space
+// of the overview, expanding it outwards to whole overview pixels. The
+// geotransform is scaled in place to the overview grid.
+func scaleToOverview(ds C.GDALDatasetH, ovrLevel int, geot []float64, offsetX, offsetY, countX, countY int32) (int32, int32, int32, int32) {
+	ovrH := C.GDALGetOverview(C.GDALGetRasterBand(ds, C.int(1)), C.int(ovrLevel))
+	ovrXSize := int32(C.GDALGetRasterBandXSize(ovrH))
+	ovrYSize := int32(C.GDALGetRasterBandYSize(ovrH))
+	fx := float64(C.GDALGetRasterXSize(ds)) / float64(ovrXSize)
+	fy := float64(C.GDALGetRasterYSize(ds)) / float64(ovrYSize)
+
+	geot[1] *= fx
+	geot[4] *= fx
+	geot[2] *= fy
+	geot[5] *= fy
+
+	ovrOffX := int32(math.Floor(float64(offsetX) / fx))
+	ovrOffY := int32(math.Floor(float64(offsetY) / fy))
+	ovrEndX := int32(math.Ceil(float64(offsetX+countX) / fx))
+	ovrEndY := int32(math.Ceil(float64(offsetY+countY) / fy))
+	if ovrEndX > ovrXSize {
+		ovrEndX = ovrXSize
+	}
+	if ovrEndY > ovrYSize {
+		ovrEndY = ovrYSize
+	}
+	if ovrEndX <= ovrOffX {
+		ovrEndX = ovrOffX + 1
+	}
+	if ovrEndY <= ovrOffY {
+		ovrEndY = ovrOffY + 1
+	}
+
+	return ovrOffX, ovrOffY, ovrEndX - ovrOffX, ovrEndY - ovrOffY
+}
+
+// getSampleFileDescriptor computes the window and mask of the pixels a
+// point or line geometry falls on, or returns nil if it doesn't fall on
+// any pixel of the dataset. Lines are densified to half the pixel
+// size so that every pixel crossed by a segment gets sampled.
+// Zero-area geometries are sampled using nearest neighbour semantics, so
+// the mean, deciles and the other statistics are computed over the sampled
+// pixels as usual, whereas fractional coverage doesn't apply. Points may
+// be interpolated from their neighbours with another resampling method
+// instead, in which case the window is expanded to the neighbours.
+func getSampleFileDescriptor(ds C.GDALDatasetH, g C.OGRGeometryH, method pb.Resampling) *DrillFileDescriptor {
+	geot := make([]float64, 6)
+	C.GDALGetGeoTransform(ds, (*C.double)(&geot[0]))
+
+	invGeot := make([]float64, 6)
+	C.GDALInvGeoTransform((*C.double)(&geot[0]), (*C.double)(&invGeot[0]))
+
+	pixelSize := math.Min(math.Hypot(geot[1], geot[4]), math.Hypot(geot[2], geot[5]))
+	C.OGR_G_Segmentize(g, C.double(pixelSize/2))
+
+	xSize := int32(C.GDALGetRasterXSize(ds))
+	ySize := int32(C.GDALGetRasterYSize(ds))
+
+	resample := method != pb.Resampling_NEAREST && C.OGR_G_GetDimension(g) == 0
+
+	var pixels [][2]int32
+	var points [][2]float64
+	forEachVertex(g, func(x, y float64) {
+		var px, py C.double
+		C.GDALApplyGeoTransform((*C.double)(&invGeot[0]), C.double(x), C.double(y), &px, &py)
+		ix := int32(math.Floor(float64(px)))
+		iy := int32(math.Floor(float64(py)))
+		if ix < 0 || iy < 0 || ix >= xSize || iy >= ySize {
+			return
+		}
+		pixels = append(pixels, [2]int32{ix, iy})
+		if resample {
+			points = append(points, [2]float64{float64(px), float64(py)})
+		}
+	})
+
+	if len(pixels) == 0 {
+		return nil
+	}
+
+	minX, minY := pixels[0][0], pixels[0][1]
+	maxX, maxY := minX, minY
+	for _, p := range pixels[1:] {
+		if p[0] < minX {
+			minX = p[0]
+		}
+		if p[0] > maxX {
+			maxX = p[0]
+		}
+		if p[1] < minY {
+			minY = p[1]
+		}
+		if p[1] > maxY {
+			maxY = p[1]
+		}
+	}
+
+	countX := maxX - minX + 1
+	countY := maxY - minY + 1
+	if resample {
+		radius := int32(resampleRadius(method))
+		minX, countX = clampWindow(minX-radius, countX+2*radius, xSize)
+		minY, countY = clampWindow(minY-radius, countY+2*radius, ySize)
+		for i := range points {
+			points[i][0] -= float64(minX)
+			points[i][1] -= float64(minY)
+		}
+	}
+	mask := newMaskBuffer(pb.MaskType_MASK_BYTE, int(countX)*int(countY))
+	for _, p := range pixels {
+		mask.Byte[(p[1]-minY)*countX+p[0]-minX] = defaultMaskBurnValue
+	}
+
+	return &DrillFileDescriptor{OffX: minX, OffY: minY, CountX: countX, CountY: countY, Mask: mask, OvrLevel: -1, GeoTransform: geot, Samples: points, Resampling: method}
+}
+
+// resampleWindow interpolates the bands of the window at the resampled
+// points of the descriptor into buf, which holds one value per point and
+// band. Points without valid neighbours are set to the band NoData.
+func resampleWindow(buf []float32, dataBuf []float32, dsDscr *DrillFileDescriptor, bandInfos []bandInfo, nodataTol noDataTolerance) {
+	nPoints := len(dsDscr.Samples)
+	bandSize := int(dsDscr.CountX) * int(dsDscr.CountY)
+	for iBand, band := range bandInfos {
+		noData := band.noData
+		valid := func(val float32) bool { return !isNoData(val, noData, nodataTol) }
+		window := dataBuf[iBand*bandSize : (iBand+1)*bandSize]
+		for ip, p := range dsDscr.Samples {
+			val, ok := resamplePoint(window, int(dsDscr.CountX), int(dsDscr.CountY), p[0], p[1], dsDscr.Resampling, valid)
+			if !ok {
+				val = noData
+			}
+			buf[iBand*nPoints+ip] = val
+		}
+	}
+}
+
+// fixTopology returns a copy of the polygon g buffered by zero, or a
+// clone of g if the buffer fails or comes back empty, e.g. for rings
+// collapsed onto a line.
+func fixTopology(g C.OGRGeometryH, segments int) C.OGRGeometryH {
+	fixed := C.OGR_G_Buffer(g, C.double(0.0), C.int(segments))
+	if fixed != nil && C.OGR_G_IsEmpty(fixed) == 0 {
+		return fixed
+	}
+	if fixed != nil {
+		C.OGR_G_DestroyGeometry(fixed)
+	}
+	return C.OGR_G_Clone(g)
+}
+
+// splitAtAntimeridian returns a copy of the geographic geometry g split
+// into the parts east and west of the antimeridian, or nil if g doesn't
+// cross it. Longitudes are expected within [-180, 180], thus a geometry
+// crossing the antimeridian has an envelope spanning more than 180
+// degrees. Its negative longitudes are shifted by 360 degrees to make it
+// continuous before splitting it at 180 degrees.
+func splitAtAntimeridian(g C.OGRGeometryH) C.OGRGeometryH {
+	var env C.OGREnvelope
+	C.OGR_G_GetEnvelope(g, &env)
+	if env.MaxX-env.MinX <= 180 {
+		return nil
+	}
+
+	shifted := C.OGR_G_Clone(g)
+	defer C.OGR_G_DestroyGeometry(shifted)
+	mapVertices(shifted, func(x, y float64) (float64, float64) {
+		if x < 0 {
+			x += 360
+		}
+		return x, y
+	})
+
+	eastBox, err := createGeometryFromWkt("POLYGON ((0 -90,180 -90,180 90,0 90,0 -90))")
+	if err != nil {
+		return nil
+	}
+	defer C.OGR_G_DestroyGeometry(eastBox)
+	westBox, err := createGeometryFromWkt("POLYGON ((180 -90,360 -90,360 90,180 90,180 -90))")
+	if err != nil {
+		return nil
+	}
+	defer C.OGR_G_DestroyGeometry(westBox)
+
+	east := C.OGR_G_Intersection(shifted, eastBox)
+	if east == nil {
+		return nil
+	}
+	defer C.OGR_G_DestroyGeometry(east)
+	west := C.OGR_G_Intersection(shifted, westBox)
+	if west == nil {
+		return nil
 	}
-	if offsetY < 0 {
-		offsetY = 0
+	defer C.OGR_G_DestroyGeometry(west)
+	mapVertices(west, func(x, y float64) (float64, float64) {
+		return x - 360, y
+	})
+
+	return C.OGR_G_Union(east, west)
+}
+
+// longitudeShift returns the mapping of longitudes into the convention of
+// a geographic dataset, or nil for projected datasets. Datasets extending
+// east of the antimeridian without extending west of -180, e.g. global
+// models in 0..360 or regional grids across the Pacific, use the 0..360
+// convention, hence western longitudes are shifted by 360 degrees. The
+// other datasets use the -180..180 convention, hence longitudes beyond
+// 180 are shifted back.
+func longitudeShift(ds C.GDALDatasetH) func(x, y float64) (float64, float64) {
+	dstSRS := C.OSRNewSpatialReference(C.GDALGetProjectionRef(ds))
+	if dstSRS == nil {
+		return nil
+	}
+	defer C.OSRDestroySpatialReference(dstSRS)
+	if C.OSRIsGeographic(dstSRS) == 0 {
+		return nil
+	}
+
+	footprint, err := envelopePolygon(ds)
+	if err != nil {
+		return nil
+	}
+	defer C.OGR_G_DestroyGeometry(footprint)
+	var env C.OGREnvelope
+	C.OGR_G_GetEnvelope(footprint, &env)
+
+	if env.MaxX > 180 && env.MinX > -180 {
+		return func(x, y float64) (float64, float64) {
+			if x < 0 {
+				x += 360
+			}
+			return x, y
+		}
+	}
+	return func(x, y float64) (float64, float64) {
+		if x > 180 {
+			x -= 360
+		}
+		return x, y
+	}
+}
+
+func createGeometryFromWkt(wkt string) (C.OGRGeometryH, error) {
+	ppszData := C.CString(wkt)
+	ppszDataTmp := ppszData
+	defer C.free(unsafe.Pointer(ppszDataTmp))
+
+	var hGeom C.OGRGeometryH
+	// OGR_G_CreateFromWkt internally updates &ppszData pointer value
+	if errC := C.OGR_G_CreateFromWkt(&ppszData, nil, &hGeom); errC != C.OGRERR_NONE {
+		return nil, fmt.Errorf("failed to create geometry: %v", wkt)
+	}
+	return hGeom, nil
+}
+
+// mapVertices replaces the coordinates of every vertex of the geometry
+// with the ones returned by fn.
+func mapVertices(g C.OGRGeometryH, fn func(x, y float64) (float64, float64)) {
+	if nGeoms := int(C.OGR_G_GetGeometryCount(g)); nGeoms > 0 {
+		for i := 0; i < nGeoms; i++ {
+			mapVertices(C.OGR_G_GetGeometryRef(g, C.int(i)), fn)
+		}
+		return
+	}
+
+	for i := 0; i < int(C.OGR_G_GetPointCount(g)); i++ {
+		x, y := fn(float64(C.OGR_G_GetX(g, C.int(i))), float64(C.OGR_G_GetY(g, C.int(i))))
+		C.OGR_G_SetPoint_2D(g, C.int(i), C.double(x), C.double(y))
+	}
+}
+
+// forEachVertex calls fn with the coordinates of every vertex of the
+// geometry, recursing into geometry collections.
+func forEachVertex(g C.OGRGeometryH, fn func(x, y float64)) {
+	if nGeoms := int(C.OGR_G_GetGeometryCount(g)); nGeoms > 0 {
+		for i := 0; i < nGeoms; i++ {
+			forEachVertex(C.OGR_G_GetGeometryRef(g, C.int(i)), fn)
+		}
+		return
 	}
 
-	mask, err := createMask(ds, gCopy, offsetX, offsetY, countX, countY)
-	return &DrillFileDescriptor{offsetX, offsetY, countX, countY, mask}, err
+	for i := 0; i < int(C.OGR_G_GetPointCount(g)); i++ {
+		fn(float64(C.OGR_G_GetX(g, C.int(i))), float64(C.OGR_G_GetY(g, C.int(i))))
+	}
 }
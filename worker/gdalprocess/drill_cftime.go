@@ -0,0 +1,68 @@
+package gdalprocess
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// cfTimeSteps maps the CF time units to their duration. Months and years
+// aren't fixed durations, hence they aren't supported.
+var cfTimeSteps = map[string]time.Duration{
+	"seconds": time.Second, "second": time.Second, "secs": time.Second, "sec": time.Second, "s": time.Second,
+	"minutes": time.Minute, "minute": time.Minute, "mins": time.Minute, "min": time.Minute,
+	"hours": time.Hour, "hour": time.Hour, "hrs": time.Hour, "hr": time.Hour, "h": time.Hour,
+	"days": 24 * time.Hour, "day": 24 * time.Hour, "d": 24 * time.Hour,
+}
+
+// cfTimeLayouts are the layouts of the reference time of the CF time
+// units, the time and the zone being optional.
+var cfTimeLayouts = []string{
+	"2006-1-2 15:4:5.999999999Z07:00",
+	"2006-1-2 15:4:5.999999999Z0700",
+	"2006-1-2 15:4:5.999999999 Z07:00",
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2T15:4:5.999999999Z07:00",
+	"2006-1-2T15:4:5.999999999",
+	"2006-1-2 15:4",
+	"2006-1-2",
+}
+
+// parseCFTimeUnits parses the CF time units of a time coordinate, e.g.
+// "days since 1970-01-01 00:00:00", into the duration of a step and the
+// reference time. Only the calendars matching the Gregorian calendar
+// after 1582 are supported.
+func parseCFTimeUnits(units string, calendar string) (time.Duration, time.Time, error) {
+	switch strings.ToLower(calendar) {
+	case "", "standard", "gregorian", "proleptic_gregorian":
+	default:
+		return 0, time.Time{}, fmt.Errorf("unsupported time calendar: %s", calendar)
+	}
+
+	fields := strings.SplitN(strings.TrimSpace(units), " since ", 2)
+	if len(fields) != 2 {
+		return 0, time.Time{}, fmt.Errorf("time units must be given as <unit> since <time>: %s", units)
+	}
+	step, found := cfTimeSteps[strings.ToLower(strings.TrimSpace(fields[0]))]
+	if !found {
+		return 0, time.Time{}, fmt.Errorf("unsupported time unit: %s", fields[0])
+	}
+
+	ref := strings.TrimSpace(fields[1])
+	for _, layout := range cfTimeLayouts {
+		if t, err := time.ParseInLocation(layout, ref, time.UTC); err == nil {
+			return step, t, nil
+		}
+	}
+	return 0, time.Time{}, fmt.Errorf("could not parse the reference time: %s", ref)
+}
+
+// cfTimeToUnix converts a value of a time coordinate to Unix seconds,
+// rounded to the nearest second.
+func cfTimeToUnix(value float64, step time.Duration, ref time.Time) int64 {
+	// The offset is added in seconds, which doesn't overflow for
+	// reference times centuries away unlike a duration
+	secs := value*step.Seconds() + float64(ref.Nanosecond())/1e9
+	return ref.Unix() + int64(math.Round(secs))
+}
@@ -0,0 +1,40 @@
+package gdalprocess
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCFTimeUnits(t *testing.T) {
+	tests := []struct {
+		units    string
+		value    float64
+		expected string
+	}{
+		{"days since 1970-01-01 00:00:00", 1.5, "1970-01-02T12:00:00Z"},
+		{"hours since 2000-1-1", 25, "2000-01-02T01:00:00Z"},
+		{"seconds since 2020-06-30T12:00:00Z", 30, "2020-06-30T12:00:30Z"},
+		{"minutes since 2010-03-04 05:06:07.5", 1, "2010-03-04T05:07:08Z"},
+		{"days since 0001-01-01 00:00:00", 730119, "2000-01-01T00:00:00Z"},
+	}
+	for _, tc := range tests {
+		step, ref, err := parseCFTimeUnits(tc.units, "")
+		if err != nil {
+			t.Errorf("%s: %v", tc.units, err)
+			continue
+		}
+		actual := time.Unix(cfTimeToUnix(tc.value, step, ref), 0).UTC().Format(time.RFC3339)
+		if actual != tc.expected {
+			t.Errorf("%s: expected %s, actual %s", tc.units, tc.expected, actual)
+		}
+	}
+
+	for _, units := range []string{"months since 2000-01-01", "days after 2000-01-01", "days since yesterday"} {
+		if _, _, err := parseCFTimeUnits(units, "standard"); err == nil {
+			t.Errorf("%s: expected an error", units)
+		}
+	}
+	if _, _, err := parseCFTimeUnits("days since 2000-01-01", "360_day"); err == nil {
+		t.Errorf("expected an error for the 360_day calendar")
+	}
+}
@@ -0,0 +1,133 @@
+package gdalprocess
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// maxEchoedGeometry is the maximum length of the geometries echoed in
+// the error messages, which may otherwise dump megabytes of vertices.
+const maxEchoedGeometry = 200
+
+// geoJSONDepths maps the GeoJSON geometry types to the nesting depth of
+// their coordinates, a position having depth 1.
+var geoJSONDepths = map[string]int{
+	"Point": 1, "MultiPoint": 2,
+	"LineString": 2, "MultiLineString": 3,
+	"Polygon": 3, "MultiPolygon": 4,
+}
+
+// truncateGeometry returns the geometry truncated to maxEchoedGeometry
+// bytes along with its full length.
+func truncateGeometry(geometry string) string {
+	if len(geometry) <= maxEchoedGeometry {
+		return geometry
+	}
+	return fmt.Sprintf("%s... (%d bytes)", geometry[:maxEchoedGeometry], len(geometry))
+}
+
+// describeJSONError returns the error of decoding data with the offset
+// of syntax errors along with the text around it.
+func describeJSONError(err error, data []byte) error {
+	syntaxErr, ok := err.(*json.SyntaxError)
+	if !ok {
+		return err
+	}
+
+	bgn, end := syntaxErr.Offset-20, syntaxErr.Offset+20
+	if bgn < 0 {
+		bgn = 0
+	}
+	if end > int64(len(data)) {
+		end = int64(len(data))
+	}
+	return fmt.Errorf("%v at byte %d near %q", err, syntaxErr.Offset, data[bgn:end])
+}
+
+// validateGeoJSONGeometry checks the structure of a GeoJSON geometry,
+// returning an error naming the type or the path of the coordinates at
+// fault, e.g. coordinates[0][2] for the third position of the exterior
+// ring of a polygon.
+func validateGeoJSONGeometry(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || string(trimmed) == "null" {
+		return fmt.Errorf("missing geometry")
+	}
+
+	var geom struct {
+		Type        string            `json:"type"`
+		Coordinates interface{}       `json:"coordinates"`
+		Geometries  []json.RawMessage `json:"geometries"`
+	}
+	if err := json.Unmarshal(data, &geom); err != nil {
+		return describeJSONError(err, data)
+	}
+
+	if geom.Type == "GeometryCollection" {
+		if len(geom.Geometries) == 0 {
+			return fmt.Errorf("empty geometry collection")
+		}
+		for i, sub := range geom.Geometries {
+			if err := validateGeoJSONGeometry(sub); err != nil {
+				return fmt.Errorf("geometries[%d]: %v", i, err)
+			}
+		}
+		return nil
+	}
+
+	depth, found := geoJSONDepths[geom.Type]
+	if !found {
+		return fmt.Errorf("unsupported geometry type %q", geom.Type)
+	}
+	if geom.Coordinates == nil {
+		return fmt.Errorf("%s without coordinates", geom.Type)
+	}
+	if err := validateCoordinates(geom.Coordinates, depth, geom.Type, "coordinates"); err != nil {
+		return fmt.Errorf("%s %v", geom.Type, err)
+	}
+	return nil
+}
+
+// validateCoordinates checks the coordinates at path nested depth times,
+// i.e. the arrays of positions with at least 2 positions for lines and
+// 4 for polygon rings.
+func validateCoordinates(coords interface{}, depth int, geomType string, path string) error {
+	arr, ok := coords.([]interface{})
+	if !ok {
+		return fmt.Errorf("%s: expected an array", path)
+	}
+
+	if depth == 1 {
+		if len(arr) < 2 {
+			return fmt.Errorf("%s: position with %d numbers, at least 2 expected", path, len(arr))
+		}
+		for i, val := range arr {
+			if _, ok := val.(float64); !ok {
+				return fmt.Errorf("%s[%d]: expected a number", path, i)
+			}
+		}
+		return nil
+	}
+
+	if len(arr) == 0 {
+		return fmt.Errorf("%s: empty coordinates", path)
+	}
+	if depth == 2 {
+		minPositions := 1
+		switch geomType {
+		case "LineString", "MultiLineString":
+			minPositions = 2
+		case "Polygon", "MultiPolygon":
+			minPositions = 4
+		}
+		if len(arr) < minPositions {
+			return fmt.Errorf("%s: %d positions, at least %d expected", path, len(arr), minPositions)
+		}
+	}
+	for i, sub := range arr {
+		if err := validateCoordinates(sub, depth-1, geomType, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package gdalprocess
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateGeoJSONGeometry(t *testing.T) {
+	valid := []string{
+		`{"type":"Point","coordinates":[1,2]}`,
+		`{"type":"LineString","coordinates":[[0,0],[1,1]]}`,
+		`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`,
+		`{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]]}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2,3]}]}`,
+	}
+	for _, geom := range valid {
+		if err := validateGeoJSONGeometry([]byte(geom)); err != nil {
+			t.Errorf("%s: %v", geom, err)
+		}
+	}
+
+	invalid := []struct {
+		geom     string
+		expected string
+	}{
+		{`null`, "missing geometry"},
+		{`{"type":"Circle","coordinates":[1,2]}`, `unsupported geometry type "Circle"`},
+		{`{"type":"Polygon"}`, "Polygon without coordinates"},
+		{`{"type":"Polygon","coordinates":[]}`, "Polygon coordinates: empty coordinates"},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}`, "Polygon coordinates[0]: 3 positions, at least 4 expected"},
+		{`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1],[0,0]]]}`, "Polygon coordinates[0][2]: position with 1 numbers"},
+		{`{"type":"LineString","coordinates":[[0,0],[1,"a"]]}`, "LineString coordinates[1][1]: expected a number"},
+		{`{"type":"MultiPoint","coordinates":[1,2]}`, "MultiPoint coordinates[0]: expected an array"},
+		{`{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[]}]}`, "geometries[0]: Point coordinates: position with 0 numbers"},
+		{`{"type":"Point","coordinates":[1,2}`, "at byte"},
+	}
+	for _, tc := range invalid {
+		err := validateGeoJSONGeometry([]byte(tc.geom))
+		if err == nil {
+			t.Errorf("%s: expected an error", tc.geom)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%s: expected %q in the error, actual %q", tc.geom, tc.expected, err)
+		}
+	}
+}
+
+func TestTruncateGeometry(t *testing.T) {
+	short := `{"type":"Point","coordinates":[1,2]}`
+	if actual := truncateGeometry(short); actual != short {
+		t.Errorf("expected %s, actual %s", short, actual)
+	}
+
+	long := strings.Repeat("[0,0],", 1000)
+	actual := truncateGeometry(long)
+	if len(actual) > maxEchoedGeometry+20 || !strings.HasSuffix(actual, "... (6000 bytes)") {
+		t.Errorf("expected a truncated geometry, actual %s", actual)
+	}
+}
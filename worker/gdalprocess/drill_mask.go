@@ -0,0 +1,147 @@
+package gdalprocess
+
+// #include <stdlib.h>
+// #include "gdal.h"
+// #include "gdal_utils.h"
+// #include "gdalwarper.h"
+// #cgo pkg-config: gdal
+import "C"
+
+import (
+	"fmt"
+	"strconv"
+	"unsafe"
+
+	pb "github.com/nci/gsky/worker/gdalservice"
+)
+
+// applyValidityMask removes the pixels flagged as invalid by the
+// validity mask of the request, e.g. a cloud mask, from the mask of the
+// descriptor, hence they're excluded from all the statistics. Following
+// the GDAL mask band convention, pixels are invalid where the mask band
+// is zero or NoData, unless a value range is given, in which case they're
+// invalid where the mask band is out of [MaskMin, MaskMax] or NoData,
+// e.g. to drill the pixels above an NDVI threshold. The mask band is read
+// from the dataset drilled unless a mask path is given, whose first band
+// is used by default. It returns the number of bytes read.
+func applyValidityMask(ds C.GDALDatasetH, dsDscr *DrillFileDescriptor, in *pb.GeoRPCGranule) (int64, error) {
+	useRange := hasMaskRange(in)
+	if useRange && in.MaskMin > in.MaskMax {
+		return 0, fmt.Errorf("invalid mask range [%v, %v]", in.MaskMin, in.MaskMax)
+	}
+
+	band := in.MaskBand
+	if band == 0 {
+		band = 1
+	}
+
+	maskDS := ds
+	if len(in.MaskPath) > 0 {
+		cPath := C.CString(in.MaskPath)
+		defer C.free(unsafe.Pointer(cPath))
+		maskDS = C.GDALOpenEx(cPath, C.GDAL_OF_READONLY|C.GDAL_OF_RASTER, nil, nil, nil)
+		if maskDS == nil {
+			return 0, fmt.Errorf("GDAL could not open mask dataset: %s", in.MaskPath)
+		}
+		defer C.GDALClose(maskDS)
+	}
+	nMaskBands := int32(C.GDALGetRasterCount(maskDS))
+	if band < 1 || band > nMaskBands {
+		return 0, fmt.Errorf("mask band %d out of range [1, %d] of the mask dataset", band, nMaskBands)
+	}
+
+	vals := make([]float32, int(dsDscr.CountX)*int(dsDscr.CountY))
+	// A mask on another grid is warped onto the window, whereas a mask
+	// on the same grid is read like the bands drilled
+	if maskDS == ds || (dsDscr.OvrLevel < 0 && sameGrid(ds, maskDS)) {
+		if gerr := readWindow(maskDS, dsDscr, []int32{band}, unsafe.Pointer(&vals[0]), C.GDT_Float32, nil); gerr != C.CE_None {
+			return 0, fmt.Errorf("failed to read mask band %d", band)
+		}
+	} else if err := warpMaskWindow(maskDS, band, ds, dsDscr, vals); err != nil {
+		return 0, err
+	}
+
+	noData := float32(getBandNoData(maskDS, band, 0))
+	for i, val := range vals {
+		valid := val != 0
+		if useRange {
+			valid = float64(val) >= in.MaskMin && float64(val) <= in.MaskMax
+		}
+		if !valid || isNoData(val, noData, noDataTolerance{}) {
+			dsDscr.Mask.clear(i)
+		}
+	}
+	return int64(len(vals)) * 4, nil
+}
+
+// hasMaskRange reports whether the validity mask of the request is a
+// value range of the mask band, i.e. any of its bounds is non-zero.
+func hasMaskRange(in *pb.GeoRPCGranule) bool {
+	return in.MaskMin != 0 || in.MaskMax != 0
+}
+
+// sameGrid reports whether both datasets have the same size,
+// geotransform and projection.
+func sameGrid(ds C.GDALDatasetH, other C.GDALDatasetH) bool {
+	if C.GDALGetRasterXSize(ds) != C.GDALGetRasterXSize(other) || C.GDALGetRasterYSize(ds) != C.GDALGetRasterYSize(other) {
+		return false
+	}
+
+	var geot, otherGeot [6]float64
+	if C.GDALGetGeoTransform(ds, (*C.double)(&geot[0])) != C.CE_None || C.GDALGetGeoTransform(other, (*C.double)(&otherGeot[0])) != C.CE_None {
+		return false
+	}
+	if geot != otherGeot {
+		return false
+	}
+	return C.GoString(C.GDALGetProjectionRef(ds)) == C.GoString(C.GDALGetProjectionRef(other))
+}
+
+// warpMaskWindow warps the band of the mask dataset onto the window of
+// the descriptor with nearest neighbour resampling into vals. The pixels
+// of the window not covered by the mask dataset are left zero, i.e.
+// invalid.
+func warpMaskWindow(maskDS C.GDALDatasetH, band int32, ds C.GDALDatasetH, dsDscr *DrillFileDescriptor, vals []float32) error {
+	// Only the mask band is warped, which is extracted into a VRT
+	srcDS := maskDS
+	if C.GDALGetRasterCount(maskDS) > 1 {
+		argv, freeArgv := cStringList([]string{"-b", strconv.Itoa(int(band))})
+		defer freeArgv()
+		opts := C.GDALBuildVRTOptionsNew(argv, nil)
+		if opts == nil {
+			return fmt.Errorf("failed to create the GDALBuildVRT options")
+		}
+		defer C.GDALBuildVRTOptionsFree(opts)
+
+		cEmpty := C.CString("")
+		defer C.free(unsafe.Pointer(cEmpty))
+		var usageErr C.int
+		srcDS = C.GDALBuildVRT(cEmpty, 1, &maskDS, nil, opts, &usageErr)
+		if srcDS == nil {
+			return fmt.Errorf("failed to extract mask band %d", band)
+		}
+		defer C.GDALClose(srcDS)
+	}
+
+	cMEM := C.CString("MEM")
+	defer C.free(unsafe.Pointer(cMEM))
+	cEmpty := C.CString("")
+	defer C.free(unsafe.Pointer(cEmpty))
+	dstDS := C.GDALCreate(C.GDALGetDriverByName(cMEM), cEmpty, C.int(dsDscr.CountX), C.int(dsDscr.CountY), 1, C.GDT_Float32, nil)
+	if dstDS == nil {
+		return fmt.Errorf("Couldn't create memory driver")
+	}
+	defer C.GDALClose(dstDS)
+
+	geot := windowGeoTransform(dsDscr.GeoTransform, dsDscr.OffX, dsDscr.OffY)
+	C.GDALSetGeoTransform(dstDS, (*C.double)(&geot[0]))
+	C.GDALSetProjection(dstDS, C.GDALGetProjectionRef(ds))
+
+	if gerr := C.GDALReprojectImage(srcDS, nil, dstDS, nil, C.GRA_NearestNeighbour, 0, 0, nil, nil, nil); gerr != C.CE_None {
+		return fmt.Errorf("failed to warp the mask onto the window")
+	}
+	if gerr := C.GDALRasterIO(C.GDALGetRasterBand(dstDS, 1), C.GF_Read, 0, 0, C.int(dsDscr.CountX), C.int(dsDscr.CountY), unsafe.Pointer(&vals[0]), C.int(dsDscr.CountX), C.int(dsDscr.CountY), C.GDT_Float32, 0, 0); gerr != C.CE_None {
+		return fmt.Errorf("failed to read the warped mask")
+	}
+	return nil
+}
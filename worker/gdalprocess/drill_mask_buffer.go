@@ -0,0 +1,123 @@
+package gdalprocess
+
+import (
+	"fmt"
+	"math"
+
+	pb "github.com/nci/gsky/worker/gdalservice"
+)
+
+// defaultMaskBurnValue is the value the geometries are burnt with unless
+// a burn value is requested.
+const defaultMaskBurnValue = 255
+
+// MaskBuffer holds the mask of a window in the data type it's burnt in,
+// only one of its buffers being set. Pixels within the geometry hold
+// any non-zero value, e.g. the burn value of the request, whereas pixels
+// outside of the geometry or masked out are zero.
+type MaskBuffer struct {
+	Byte    []uint8
+	Int16   []int16
+	Float32 []float32
+}
+
+// newMaskBuffer returns a zeroed mask of n pixels of the given type.
+func newMaskBuffer(maskType pb.MaskType, n int) *MaskBuffer {
+	switch maskType {
+	case pb.MaskType_MASK_INT16:
+		return &MaskBuffer{Int16: make([]int16, n)}
+	case pb.MaskType_MASK_FLOAT32:
+		return &MaskBuffer{Float32: make([]float32, n)}
+	default:
+		return &MaskBuffer{Byte: make([]uint8, n)}
+	}
+}
+
+// newFullMask returns a Byte mask of n pixels all within the geometry.
+func newFullMask(n int) *MaskBuffer {
+	m := &MaskBuffer{Byte: make([]uint8, n)}
+	for i := range m.Byte {
+		m.Byte[i] = defaultMaskBurnValue
+	}
+	return m
+}
+
+// maskBurnValue returns the value the geometries are burnt with, which
+// must be non-zero and representable in the mask type of the request.
+func maskBurnValue(in *pb.GeoRPCGranule) (float64, error) {
+	val := in.MaskBurnValue
+	if val == 0 {
+		return defaultMaskBurnValue, nil
+	}
+
+	switch in.MaskType {
+	case pb.MaskType_MASK_INT16:
+		if val != math.Trunc(val) || val < math.MinInt16 || val > math.MaxInt16 {
+			return 0, fmt.Errorf("mask burn value %v out of the Int16 range", val)
+		}
+	case pb.MaskType_MASK_FLOAT32:
+		if math.IsNaN(val) || math.Abs(val) > math.MaxFloat32 {
+			return 0, fmt.Errorf("mask burn value %v out of the Float32 range", val)
+		}
+	default:
+		if val != math.Trunc(val) || val < 1 || val > math.MaxUint8 {
+			return 0, fmt.Errorf("mask burn value %v out of the Byte range", val)
+		}
+	}
+	return val, nil
+}
+
+func (m *MaskBuffer) len() int {
+	switch {
+	case m.Byte != nil:
+		return len(m.Byte)
+	case m.Int16 != nil:
+		return len(m.Int16)
+	default:
+		return len(m.Float32)
+	}
+}
+
+// isSet reports whether pixel i is within the mask.
+func (m *MaskBuffer) isSet(i int) bool {
+	switch {
+	case m.Byte != nil:
+		return m.Byte[i] != 0
+	case m.Int16 != nil:
+		return m.Int16[i] != 0
+	default:
+		return m.Float32[i] != 0
+	}
+}
+
+// clear masks pixel i out.
+func (m *MaskBuffer) clear(i int) {
+	switch {
+	case m.Byte != nil:
+		m.Byte[i] = 0
+	case m.Int16 != nil:
+		m.Int16[i] = 0
+	default:
+		m.Float32[i] = 0
+	}
+}
+
+// size returns the size of the mask in bytes.
+func (m *MaskBuffer) size() int {
+	return len(m.Byte) + 2*len(m.Int16) + 4*len(m.Float32)
+}
+
+// clone returns a deep copy of the mask.
+func (m *MaskBuffer) clone() *MaskBuffer {
+	c := &MaskBuffer{}
+	if m.Byte != nil {
+		c.Byte = append([]uint8{}, m.Byte...)
+	}
+	if m.Int16 != nil {
+		c.Int16 = append([]int16{}, m.Int16...)
+	}
+	if m.Float32 != nil {
+		c.Float32 = append([]float32{}, m.Float32...)
+	}
+	return c
+}
@@ -0,0 +1,69 @@
+package gdalprocess
+
+// maskCacheBytes is the maximum total size of the masks kept across
+// requests.
+const maskCacheBytes = 256 << 20
+
+// maskCache holds the rasterized masks of the windows keyed by the
+// digest of the geometry in the dataset SRS along with the grid, the
+// window and the options the masks depend on. Granules sharing a grid,
+// e.g. the tiles of a mosaic or the timesteps of a stack polled for the
+// same area, hence rasterize the geometry only once.
+var maskCache = newSizedLRUCache(maskCacheBytes, func(value interface{}) int {
+	return value.(*cachedMask).size()
+}, func(interface{}) {})
+
+// cachedMask holds the buffers of a descriptor derived from the
+// geometry alone.
+type cachedMask struct {
+	mask         *MaskBuffer
+	interiorMask *MaskBuffer
+	weights      []float32
+	zones        []int32
+}
+
+func (m *cachedMask) size() int {
+	size := 4*len(m.weights) + 4*len(m.zones)
+	if m.mask != nil {
+		size += m.mask.size()
+	}
+	if m.interiorMask != nil {
+		size += m.interiorMask.size()
+	}
+	return size
+}
+
+// clone returns a deep copy of the buffers, which the drills modify,
+// e.g. when applying a validity mask.
+func (m *cachedMask) clone() *cachedMask {
+	c := &cachedMask{}
+	if m.mask != nil {
+		c.mask = m.mask.clone()
+	}
+	if m.interiorMask != nil {
+		c.interiorMask = m.interiorMask.clone()
+	}
+	if m.weights != nil {
+		c.weights = append([]float32{}, m.weights...)
+	}
+	if m.zones != nil {
+		c.zones = append([]int32{}, m.zones...)
+	}
+	return c
+}
+
+// loadMask returns a copy of the cached mask of key, if any.
+func loadMask(key string) (*cachedMask, bool) {
+	value, found := maskCache.take(key)
+	if !found {
+		return nil, false
+	}
+	m := value.(*cachedMask)
+	maskCache.put(key, m)
+	return m.clone(), true
+}
+
+// storeMask caches a copy of the mask of key.
+func storeMask(key string, m *cachedMask) {
+	maskCache.put(key, m.clone())
+}
@@ -0,0 +1,59 @@
+package gdalprocess
+
+// #include <stdlib.h>
+// #include "gdal.h"
+// #cgo pkg-config: gdal
+// extern int goDrillProgress(double, char *, void *);
+import "C"
+
+import (
+	"context"
+	"sync"
+	"unsafe"
+)
+
+// progressContexts maps the opaque progress argument handed to GDAL to
+// the context of the request. Go pointers can't be retained by C, hence
+// the argument is a C allocation used as a key.
+var progressContexts = struct {
+	sync.Mutex
+	ctxs map[unsafe.Pointer]context.Context
+}{ctxs: make(map[unsafe.Pointer]context.Context)}
+
+//export goDrillProgress
+func goDrillProgress(complete C.double, msg *C.char, arg unsafe.Pointer) C.int {
+	progressContexts.Lock()
+	ctx := progressContexts.ctxs[arg]
+	progressContexts.Unlock()
+
+	if ctx != nil && ctx.Err() != nil {
+		return 0
+	}
+	return 1
+}
+
+// newCancellableRasterIOArg returns the extra RasterIO argument whose
+// progress callback aborts the read once ctx is done. The returned
+// function must be called to release the argument.
+func newCancellableRasterIOArg(ctx context.Context) (*C.GDALRasterIOExtraArg, func()) {
+	key := C.malloc(1)
+	progressContexts.Lock()
+	progressContexts.ctxs[key] = ctx
+	progressContexts.Unlock()
+
+	// Equivalent to INIT_RASTERIO_EXTRA_ARG which isn't callable from cgo
+	extraArg := &C.GDALRasterIOExtraArg{
+		nVersion:      1,
+		eResampleAlg:  C.GRIORA_NearestNeighbour,
+		pfnProgress:   C.GDALProgressFunc(C.goDrillProgress),
+		pProgressData: key,
+	}
+
+	release := func() {
+		progressContexts.Lock()
+		delete(progressContexts.ctxs, key)
+		progressContexts.Unlock()
+		C.free(key)
+	}
+	return extraArg, release
+}
@@ -0,0 +1,87 @@
+package gdalprocess
+
+import (
+	"math"
+
+	pb "github.com/nci/gsky/worker/gdalservice"
+)
+
+// resampleRadius returns the number of neighbours on each side of a
+// point the resampling kernel reaches along an axis.
+func resampleRadius(method pb.Resampling) int {
+	switch method {
+	case pb.Resampling_BILINEAR:
+		return 1
+	case pb.Resampling_CUBIC:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// cubicWeight is the cubic convolution kernel with a = -0.5, the one
+// used by GDAL for cubic resampling.
+func cubicWeight(t float64) float64 {
+	const a = -0.5
+	t = math.Abs(t)
+	switch {
+	case t <= 1:
+		return ((a+2)*t-(a+3))*t*t + 1
+	case t < 2:
+		return ((a*t-5*a)*t+8*a)*t - 4*a
+	default:
+		return 0
+	}
+}
+
+// resampleTaps returns the first neighbour along an axis and the weights
+// of the neighbours of the fractional pixel coordinate x, where pixel
+// centers lie at half-integer coordinates.
+func resampleTaps(x float64, method pb.Resampling) (int, []float64) {
+	switch method {
+	case pb.Resampling_BILINEAR:
+		x0 := math.Floor(x - 0.5)
+		f := x - 0.5 - x0
+		return int(x0), []float64{1 - f, f}
+	case pb.Resampling_CUBIC:
+		x0 := math.Floor(x - 0.5)
+		f := x - 0.5 - x0
+		return int(x0) - 1, []float64{cubicWeight(f + 1), cubicWeight(f), cubicWeight(1 - f), cubicWeight(2 - f)}
+	default:
+		return int(math.Floor(x)), []float64{1}
+	}
+}
+
+// resamplePoint interpolates the band values of a width x height window
+// at the fractional pixel coordinates (x, y) relative to the window.
+// Neighbours outside the window or rejected by valid are skipped and the
+// weights of the remaining ones renormalised. It returns false if none
+// of the neighbours is valid.
+func resamplePoint(buf []float32, width, height int, x, y float64, method pb.Resampling, valid func(float32) bool) (float32, bool) {
+	ix0, wx := resampleTaps(x, method)
+	iy0, wy := resampleTaps(y, method)
+
+	var sum, wSum float64
+	for j, w := range wy {
+		iy := iy0 + j
+		if iy < 0 || iy >= height {
+			continue
+		}
+		for i, v := range wx {
+			ix := ix0 + i
+			if ix < 0 || ix >= width {
+				continue
+			}
+			val := buf[iy*width+ix]
+			if !valid(val) {
+				continue
+			}
+			sum += w * v * float64(val)
+			wSum += w * v
+		}
+	}
+	if wSum == 0 {
+		return 0, false
+	}
+	return float32(sum / wSum), true
+}
@@ -0,0 +1,48 @@
+package gdalprocess
+
+import (
+	"math"
+	"testing"
+
+	pb "github.com/nci/gsky/worker/gdalservice"
+)
+
+func TestResamplePoint(t *testing.T) {
+	// The values are linear in the pixel center coordinates
+	const width, height = 4, 4
+	buf := make([]float32, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			buf[y*width+x] = float32(x + 10*y)
+		}
+	}
+	valid := func(val float32) bool { return val != -999 }
+
+	tests := []struct {
+		method   pb.Resampling
+		expected float32
+	}{
+		{pb.Resampling_NEAREST, 21},
+		{pb.Resampling_BILINEAR, 18.75},
+		{pb.Resampling_CUBIC, 18.75},
+	}
+	for _, tc := range tests {
+		val, ok := resamplePoint(buf, width, height, 1.75, 2.25, tc.method, valid)
+		if !ok || math.Abs(float64(val-tc.expected)) > 1e-5 {
+			t.Errorf("%v: expected %v, actual %v (%v)", tc.method, tc.expected, val, ok)
+		}
+	}
+
+	// NoData neighbours are skipped and the weights renormalised
+	buf[1*width+1] = -999
+	val, ok := resamplePoint(buf, width, height, 1.75, 1.75, pb.Resampling_BILINEAR, valid)
+	expected := (12*0.1875 + 21*0.1875 + 22*0.0625) / 0.4375
+	if !ok || math.Abs(float64(val)-expected) > 1e-5 {
+		t.Errorf("expected %v, actual %v (%v)", expected, val, ok)
+	}
+
+	// The point at the center of a NoData pixel has no valid neighbours
+	if _, ok := resamplePoint(buf, width, height, 1.5, 1.5, pb.Resampling_BILINEAR, valid); ok {
+		t.Errorf("expected no valid neighbours")
+	}
+}
@@ -0,0 +1,1484 @@
+package gdalprocess
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+
+	pb "github.com/nci/gsky/worker/gdalservice"
+)
+
+// applyStatsMask sets the flags of the statistics selected by the stats
+// bitmask of the request, which add to the ones flagged individually.
+// The mean is always the first column of the rows, followed by the
+// selected statistics in the order of their flags, some of which select
+// a pair of columns, e.g. the standard deviation and the variance.
+func applyStatsMask(in *pb.GeoRPCGranule) {
+	flags := []struct {
+		stat pb.Statistic
+		flag *bool
+	}{
+		{pb.Statistic_STAT_STDDEV, &in.ComputeStdDev},
+		{pb.Statistic_STAT_MEDIAN, &in.ComputeMedian},
+		{pb.Statistic_STAT_MINMAX, &in.ComputeMinMax},
+		{pb.Statistic_STAT_MODE, &in.ComputeMode},
+		{pb.Statistic_STAT_IQR, &in.ComputeIQR},
+		{pb.Statistic_STAT_MAD, &in.ComputeMAD},
+		{pb.Statistic_STAT_SUM, &in.ComputeSum},
+		{pb.Statistic_STAT_STDERROR, &in.ComputeStdError},
+		{pb.Statistic_STAT_CV, &in.ComputeCV},
+		{pb.Statistic_STAT_NODATA_FRACTION, &in.ComputeNoDataFraction},
+		{pb.Statistic_STAT_MOMENTS, &in.ComputeMoments},
+	}
+	for _, f := range flags {
+		if in.Stats&uint32(f.stat) != 0 {
+			*f.flag = true
+		}
+	}
+}
+
+// meanSum accumulates the weighted sum of the pixel values and the sum
+// of the weights the mean is divided by. Both are accumulated in float64
+// regardless of the float32 values read, which would otherwise drift by
+// whole percents over the millions of pixels of large geometries.
+// Compensated sums additionally carry the rounding error of every
+// addition with the Kahan-Babuska (Neumaier) summation, which keeps tiny
+// signals summed over huge areas exact at about twice the cost.
+type meanSum struct {
+	sum, sumW   float64
+	compensated bool
+	// running compensations of sum and sumW
+	c, cW float64
+}
+
+// add accumulates the value with weight w.
+func (s *meanSum) add(val, w float64) {
+	s.addValue(w * val)
+	s.addWeight(w)
+}
+
+// addValue accumulates an already weighted value into the sum only.
+func (s *meanSum) addValue(val float64) {
+	if s.compensated {
+		s.sum, s.c = neumaierAdd(s.sum, s.c, val)
+		return
+	}
+	s.sum += val
+}
+
+// addWeight accumulates a weight into the sum of the weights only.
+func (s *meanSum) addWeight(w float64) {
+	if s.compensated {
+		s.sumW, s.cW = neumaierAdd(s.sumW, s.cW, w)
+		return
+	}
+	s.sumW += w
+}
+
+// total returns the sum of the values, including its compensation.
+func (s *meanSum) total() float64 {
+	return s.sum + s.c
+}
+
+// totalWeight returns the sum of the weights, including its compensation.
+func (s *meanSum) totalWeight() float64 {
+	return s.sumW + s.cW
+}
+
+// neumaierAdd adds val to sum and returns the new sum along with the
+// compensation c updated with the low order bits lost by the addition.
+func neumaierAdd(sum, c, val float64) (float64, float64) {
+	t := sum + val
+	if math.Abs(sum) >= math.Abs(val) {
+		c += (sum - t) + val
+	} else {
+		c += (val - t) + sum
+	}
+	return t, c
+}
+
+// mean returns the weighted mean of the accumulated values.
+func (s *meanSum) mean() float64 {
+	return s.total() / s.totalWeight()
+}
+
+// welford accumulates the running mean and the sum of squared
+// deviations from the mean using Welford's numerically stable
+// single pass algorithm.
+type welford struct {
+	n    int64
+	mean float64
+	m2   float64
+}
+
+func (w *welford) add(val float64) {
+	w.n++
+	delta := val - w.mean
+	w.mean += delta / float64(w.n)
+	w.m2 += delta * (val - w.mean)
+}
+
+// variance returns the population variance of the accumulated values.
+func (w *welford) variance() float64 {
+	if w.n == 0 {
+		return 0
+	}
+	return w.m2 / float64(w.n)
+}
+
+// stdError returns the standard error of the mean of the accumulated
+// values, using the sample standard deviation. It's undefined for less
+// than two values, in which case 0 is returned.
+func (w *welford) stdError() float64 {
+	if w.n < 2 {
+		return 0
+	}
+	return math.Sqrt(w.m2/float64(w.n-1)) / math.Sqrt(float64(w.n))
+}
+
+// moments accumulates the central moments up to the fourth order in a
+// single pass with the update of Terriberry, which extends the one of
+// Welford.
+type moments struct {
+	n    int64
+	mean float64
+	m2   float64
+	m3   float64
+	m4   float64
+}
+
+func (m *moments) add(val float64) {
+	n1 := float64(m.n)
+	m.n++
+	n := float64(m.n)
+	delta := val - m.mean
+	deltaN := delta / n
+	deltaN2 := deltaN * deltaN
+	term1 := delta * deltaN * n1
+	m.mean += deltaN
+	m.m4 += term1*deltaN2*(n*n-3*n+3) + 6*deltaN2*m.m2 - 4*deltaN*m.m3
+	m.m3 += term1*deltaN*(n-2) - 3*deltaN*m.m2
+	m.m2 += term1
+}
+
+// skewness returns the population skewness g1 = m3/m2^1.5 of the
+// accumulated values without bias correction. It's undefined for less
+// than three values or constant values, in which case false is returned.
+func (m *moments) skewness() (float64, bool) {
+	if m.n < 3 || m.m2 == 0 {
+		return 0, false
+	}
+	n := float64(m.n)
+	return math.Sqrt(n) * m.m3 / math.Pow(m.m2, 1.5), true
+}
+
+// kurtosis returns the population excess kurtosis g2 = m4/m2^2 - 3 of
+// the accumulated values without bias correction. It's undefined for
+// less than four values or constant values, in which case false is
+// returned.
+func (m *moments) kurtosis() (float64, bool) {
+	if m.n < 4 || m.m2 == 0 {
+		return 0, false
+	}
+	n := float64(m.n)
+	return n*m.m4/(m.m2*m.m2) - 3, true
+}
+
+// pixelChecksum returns the 64-bit FNV-1a hash of the bits of the
+// values sorted in place, hence it only depends on the set of values.
+func pixelChecksum(buf []float32) uint64 {
+	sort.Slice(buf, func(i, j int) bool { return buf[i] < buf[j] })
+	h := fnv.New64a()
+	var b [4]byte
+	for _, val := range buf {
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(val))
+		h.Write(b[:])
+	}
+	return h.Sum64()
+}
+
+// minCVMean is the smallest magnitude of the mean for which the
+// coefficient of variation is defined.
+const minCVMean = 1e-9
+
+// coefVariation returns the ratio of the population standard deviation
+// to the mean of the accumulated values. It's undefined for a mean too
+// close to zero, in which case false is returned.
+func (w *welford) coefVariation() (float64, bool) {
+	if w.n == 0 || math.Abs(w.mean) < minCVMean {
+		return 0, false
+	}
+	return math.Sqrt(w.variance()) / w.mean, true
+}
+
+// decilePercentiles returns the cut points in percent which split a
+// distribution into decileCount+1 equally sized groups.
+func decilePercentiles(decileCount int) []float64 {
+	percentiles := make([]float64, decileCount)
+	for i := range percentiles {
+		percentiles[i] = 100 * float64(i+1) / float64(decileCount+1)
+	}
+	return percentiles
+}
+
+// minMaxAccumulator tracks the extrema of the accumulated values.
+type minMaxAccumulator struct {
+	n   int32
+	min float64
+	max float64
+}
+
+func (m *minMaxAccumulator) add(val float64) {
+	if m.n == 0 || val < m.min {
+		m.min = val
+	}
+	if m.n == 0 || val > m.max {
+		m.max = val
+	}
+	m.n++
+}
+
+// computePercentiles returns the requested percentiles (0-100) of the
+// sorted buffer. The estimator is the linear interpolation between the
+// closest ranks, i.e. type 7 of Hyndman and Fan, which is the default
+// method of NumPy and R: pos = q*(n-1) interpolated between
+// sorted[floor(pos)] and sorted[ceil(pos)].
+func computePercentiles(sorted []float32, percentiles []float64) []float32 {
+	res := make([]float32, len(percentiles))
+	if len(sorted) == 0 {
+		return res
+	}
+
+	for i, pct := range percentiles {
+		pos := pct / 100 * float64(len(sorted)-1)
+		lo := int(math.Floor(pos))
+		hi := int(math.Ceil(pos))
+		frac := pos - float64(lo)
+		res[i] = float32(float64(sorted[lo]) + frac*float64(sorted[hi]-sorted[lo]))
+	}
+	return res
+}
+
+// parallelFor calls fn for every index in [0, n) using at most nWorkers
+// goroutines and returns once all the calls have completed. A panic in
+// fn is propagated to the caller once the other calls have completed,
+// where it can be recovered.
+func parallelFor(n int, nWorkers int, fn func(i int)) {
+	if nWorkers > n {
+		nWorkers = n
+	}
+	if nWorkers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicVal interface{}
+	call := func(i int) {
+		defer func() {
+			if r := recover(); r != nil {
+				panicOnce.Do(func() { panicVal = r })
+			}
+		}()
+		fn(i)
+	}
+	for iw := 0; iw < nWorkers; iw++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				call(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	if panicVal != nil {
+		panic(panicVal)
+	}
+}
+
+// computeMedian returns the median of buf using quickselect, which avoids
+// sorting the whole buffer. The order of buf is modified in place.
+func computeMedian(buf []float32) float32 {
+	n := len(buf)
+	if n == 0 {
+		return 0
+	}
+
+	k := (n - 1) / 2
+	selectNth(buf, k)
+	median := buf[k]
+	if n%2 == 0 {
+		// the upper middle value is the smallest one after the k-th
+		next := buf[k+1]
+		for _, val := range buf[k+2:] {
+			if val < next {
+				next = val
+			}
+		}
+		median = (median + next) / 2
+	}
+	return median
+}
+
+// selectNth partially sorts buf such that buf[k] holds the value it would
+// have if buf was fully sorted, with no greater values before it and no
+// lesser values after it.
+func selectNth(buf []float32, k int) {
+	lo, hi := 0, len(buf)-1
+	for lo < hi {
+		pivot := buf[lo+(hi-lo)/2]
+		i, j := lo, hi
+		for i <= j {
+			for buf[i] < pivot {
+				i++
+			}
+			for buf[j] > pivot {
+				j--
+			}
+			if i <= j {
+				buf[i], buf[j] = buf[j], buf[i]
+				i++
+				j--
+			}
+		}
+
+		if k <= j {
+			hi = j
+		} else if k >= i {
+			lo = i
+		} else {
+			return
+		}
+	}
+}
+
+// histogram counts values into fixed-width bins over [min, max].
+// Values outside the range are ignored and max falls into the last bin.
+type histogram struct {
+	min, max float64
+	counts   []int64
+}
+
+func newHistogram(bins int, min, max float64) *histogram {
+	return &histogram{min: min, max: max, counts: make([]int64, bins)}
+}
+
+func (h *histogram) add(val float64) {
+	if val < h.min || val > h.max || math.IsNaN(val) {
+		return
+	}
+
+	bins := len(h.counts)
+	iBin := bins - 1
+	if h.max > h.min {
+		// binned the same way as GDAL histograms so that values at
+		// the bin edges fall into the same bins
+		iBin = int((val - h.min) * (float64(bins) / (h.max - h.min)))
+		if iBin >= bins {
+			iBin = bins - 1
+		}
+	}
+	h.counts[iBin]++
+}
+
+// edges returns the len(counts)+1 bin edges.
+func (h *histogram) edges() []float64 {
+	bins := len(h.counts)
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = h.min + float64(i)*(h.max-h.min)/float64(bins)
+	}
+	edges[bins] = h.max
+	return edges
+}
+
+// positiveMeans accumulates the weighted geometric and harmonic means
+// of positive values. Other values are ignored since neither mean is
+// defined for them.
+type positiveMeans struct {
+	n      int32
+	wSum   float64
+	logSum float64
+	invSum float64
+}
+
+func (p *positiveMeans) add(val, w float64) {
+	if !(val > 0) {
+		return
+	}
+	p.n++
+	p.wSum += w
+	p.logSum += w * math.Log(val)
+	p.invSum += w / val
+}
+
+func (p *positiveMeans) geometric() float64 {
+	return math.Exp(p.logSum / p.wSum)
+}
+
+func (p *positiveMeans) harmonic() float64 {
+	return p.wSum / p.invSum
+}
+
+// pchipInterpolate evaluates at x the monotone piecewise cubic Hermite
+// interpolant (PCHIP) of the points (xs, ys) with strictly increasing
+// xs. The slopes follow Fritsch and Carlson as in SciPy's
+// PchipInterpolator, hence the interpolant doesn't overshoot the data.
+// Values outside the range of xs are clamped to the end points.
+func pchipInterpolate(xs, ys []float64, x []float64) []float64 {
+	n := len(xs)
+	res := make([]float64, len(x))
+	if n == 0 {
+		return res
+	}
+	if n == 1 {
+		for i := range res {
+			res[i] = ys[0]
+		}
+		return res
+	}
+
+	h := make([]float64, n-1)
+	delta := make([]float64, n-1)
+	for k := 0; k < n-1; k++ {
+		h[k] = xs[k+1] - xs[k]
+		delta[k] = (ys[k+1] - ys[k]) / h[k]
+	}
+
+	d := make([]float64, n)
+	if n == 2 {
+		d[0], d[1] = delta[0], delta[0]
+	} else {
+		for k := 1; k < n-1; k++ {
+			if delta[k-1]*delta[k] <= 0 {
+				continue
+			}
+			w1 := 2*h[k] + h[k-1]
+			w2 := h[k] + 2*h[k-1]
+			d[k] = (w1 + w2) / (w1/delta[k-1] + w2/delta[k])
+		}
+		d[0] = pchipEndSlope(h[0], h[1], delta[0], delta[1])
+		d[n-1] = pchipEndSlope(h[n-2], h[n-3], delta[n-2], delta[n-3])
+	}
+
+	for i, xi := range x {
+		if xi <= xs[0] {
+			res[i] = ys[0]
+			continue
+		}
+		if xi >= xs[n-1] {
+			res[i] = ys[n-1]
+			continue
+		}
+
+		k := 0
+		for xi > xs[k+1] {
+			k++
+		}
+		t := (xi - xs[k]) / h[k]
+		t2 := t * t
+		t3 := t2 * t
+		res[i] = (2*t3-3*t2+1)*ys[k] + (t3-2*t2+t)*h[k]*d[k] + (-2*t3+3*t2)*ys[k+1] + (t3-t2)*h[k]*d[k+1]
+	}
+	return res
+}
+
+// pchipEndSlope returns the one-sided three point estimate of the slope
+// at an end point, limited to preserve monotonicity.
+func pchipEndSlope(h0, h1, delta0, delta1 float64) float64 {
+	d := ((2*h0+h1)*delta0 - h0*delta1) / (h0 + h1)
+	if sign(d) != sign(delta0) {
+		return 0
+	}
+	if sign(delta0) != sign(delta1) && math.Abs(d) > 3*math.Abs(delta0) {
+		return 3 * delta0
+	}
+	return d
+}
+
+func sign(val float64) int {
+	switch {
+	case val > 0:
+		return 1
+	case val < 0:
+		return -1
+	}
+	return 0
+}
+
+// classCounter counts the occurrences of the integer classes of the
+// accumulated values, along with the sum of their weights.
+type classCounter struct {
+	counts  map[int32]int64
+	weights map[int32]float64
+	wTotal  float64
+}
+
+func newClassCounter() *classCounter {
+	return &classCounter{counts: make(map[int32]int64), weights: make(map[int32]float64)}
+}
+
+func (c *classCounter) add(val float64, w float64) {
+	class := int32(math.Round(val))
+	c.counts[class]++
+	c.weights[class] += w
+	c.wTotal += w
+}
+
+// fractions returns the classes in ascending order, or the requested
+// classes in the given order, with their weighted fractions of all the
+// accumulated values and their counts.
+func (c *classCounter) fractions(classes []int32) ([]int32, []float64, []int64) {
+	if len(classes) == 0 {
+		for class := range c.counts {
+			classes = append(classes, class)
+		}
+		sort.Slice(classes, func(i, j int) bool { return classes[i] < classes[j] })
+	}
+
+	fractions := make([]float64, len(classes))
+	counts := make([]int64, len(classes))
+	for i, class := range classes {
+		counts[i] = c.counts[class]
+		if c.wTotal > 0 {
+			fractions[i] = c.weights[class] / c.wTotal
+		}
+	}
+	return classes, fractions, counts
+}
+
+// majorityMinority returns the classes with the largest and the smallest
+// weighted fractions of the accumulated values, i.e. the zonal majority
+// and minority, among all the classes present. Ties resolve to the
+// lowest class. Non-integer values are rounded to their nearest class
+// when accumulated, hence they're only meaningful for integer rasters.
+func (c *classCounter) majorityMinority() (int32, int32) {
+	var majority, minority int32
+	first := true
+	for class, w := range c.weights {
+		if first {
+			majority, minority = class, class
+			first = false
+			continue
+		}
+		if mw := c.weights[majority]; w > mw || (w == mw && class < majority) {
+			majority = class
+		}
+		if mw := c.weights[minority]; w < mw || (w == mw && class < minority) {
+			minority = class
+		}
+	}
+	return majority, minority
+}
+
+// mode returns the center of the most populated bin and its count. Ties
+// resolve to the lowest bin.
+func (h *histogram) mode() (float64, int64) {
+	iMode := 0
+	for i, count := range h.counts {
+		if count > h.counts[iMode] {
+			iMode = i
+		}
+	}
+	width := (h.max - h.min) / float64(len(h.counts))
+	return h.min + (float64(iMode)+0.5)*width, h.counts[iMode]
+}
+
+// exactMode returns the most frequent of the values and its count. Ties
+// resolve to the smallest value so that the result doesn't depend on
+// the map iteration order.
+func exactMode(vals []float32) (float32, int64) {
+	counts := make(map[float32]int64)
+	for _, val := range vals {
+		counts[val]++
+	}
+
+	var mode float32
+	var modeCount int64
+	for val, count := range counts {
+		if count > modeCount || (count == modeCount && val < mode) {
+			mode = val
+			modeCount = count
+		}
+	}
+	return mode, modeCount
+}
+
+// defaultModeBins is the number of bins floating point values are
+// binned into to find their mode unless histogram bins are requested.
+const defaultModeBins = 256
+
+// computeMode returns the most frequent value of the valid pixels and its
+// count. Integer bands are counted by exact value whereas floating point
+// values are binned first, using the requested histogram bins if any,
+// and the center of the most populated bin is returned.
+func computeMode(buf []float32, isInteger bool, in *pb.GeoRPCGranule) (float64, int64) {
+	if isInteger {
+		mode, count := exactMode(buf)
+		return float64(mode), count
+	}
+
+	bins := int(in.HistogramBins)
+	if bins <= 0 {
+		bins = defaultModeBins
+	}
+	hMin, hMax := in.HistogramMin, in.HistogramMax
+	if hMin >= hMax {
+		var valRange minMaxAccumulator
+		for _, val := range buf {
+			valRange.add(float64(val))
+		}
+		hMin, hMax = valRange.min, valRange.max
+	}
+
+	hist := newHistogram(bins, hMin, hMax)
+	for _, val := range buf {
+		hist.add(float64(val))
+	}
+	return hist.mode()
+}
+
+// isClipped reports whether val falls outside the clip bounds. Values
+// equal to the bounds are kept unless clipping is inclusive.
+func isClipped(val, lower, upper float32, inclusive bool) bool {
+	if inclusive {
+		return !(val > lower && val < upper)
+	}
+	return val < lower || val > upper
+}
+
+// sigmaClipBounds narrows the clip bounds to k standard deviations
+// around the mean of the values within them, which is repeated with the
+// values left until none is rejected or after the given iterations. It
+// returns the bounds along with the number of values within the
+// original bounds rejected.
+func sigmaClipBounds(vals []float32, lower, upper float32, inclusive bool, k float64, iterations int) (float32, float32, int) {
+	kept := make([]float32, 0, len(vals))
+	for _, val := range vals {
+		if !isClipped(val, lower, upper, inclusive) {
+			kept = append(kept, val)
+		}
+	}
+	nKept := len(kept)
+
+	for it := 0; it < iterations && len(kept) > 1; it++ {
+		var w welford
+		for _, val := range kept {
+			w.add(float64(val))
+		}
+		// Identical values are no outliers
+		dev := k * math.Sqrt(w.variance())
+		if dev == 0 {
+			break
+		}
+		lower = float32(math.Max(float64(lower), w.mean-dev))
+		upper = float32(math.Min(float64(upper), w.mean+dev))
+
+		n := 0
+		for _, val := range kept {
+			if !isClipped(val, lower, upper, inclusive) {
+				kept[n] = val
+				n++
+			}
+		}
+		if n == len(kept) {
+			break
+		}
+		kept = kept[:n]
+	}
+	return lower, upper, nKept - len(kept)
+}
+
+// trimmedMean returns the mean of the sorted values after discarding
+// floor(trimFraction*n) values from each tail, along with the number of
+// values retained.
+func trimmedMean(sorted []float32, trimFraction float64) (float64, int) {
+	k := int(math.Floor(trimFraction * float64(len(sorted))))
+	retained := sorted[k : len(sorted)-k]
+	if len(retained) == 0 {
+		return 0, 0
+	}
+
+	sum := 0.0
+	for _, val := range retained {
+		sum += float64(val)
+	}
+	return sum / float64(len(retained)), len(retained)
+}
+
+// interquartileRange returns Q3-Q1 of the sorted values using the same
+// linear interpolation as the deciles.
+func interquartileRange(sorted []float32) float32 {
+	q := computePercentiles(sorted, []float64{25, 75})
+	return q[1] - q[0]
+}
+
+// medianAbsDeviation returns the median of the absolute deviations of
+// the sorted values from their median.
+func medianAbsDeviation(sorted []float32) float32 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	median := computePercentiles(sorted, []float64{50})[0]
+	devs := make([]float32, len(sorted))
+	for i, val := range sorted {
+		devs[i] = float32(math.Abs(float64(val - median)))
+	}
+	return computeMedian(devs)
+}
+
+// noDataTolerance is the tolerance pixels match the NoData within: the
+// larger of the absolute one and the relative one scaled by the NoData
+// magnitude, the latter suiting large NoData values such as -3.4e38
+// whose float32 spacing dwarfs any absolute tolerance.
+type noDataTolerance struct {
+	abs, rel float32
+}
+
+// isZero reports whether pixels must match the NoData exactly.
+func (tol noDataTolerance) isZero() bool {
+	return tol.abs <= 0 && tol.rel <= 0
+}
+
+// isNoData reports whether val matches nodata within the tolerance tol.
+// A NoData value that doesn't round-trip through the Float32 RasterIO
+// conversion would otherwise leak fill pixels into the statistics. NaN
+// pixels are never valid, whatever the declared NoData, as they would
+// poison the sums.
+func isNoData(val float32, nodata float32, tol noDataTolerance) bool {
+	return isNoData64(float64(val), float64(nodata), tol)
+}
+
+// isNoData64 is isNoData for the exact float64 values of wide integer
+// bands, whose NoData, e.g. 2147483647, may not be a float32 value.
+func isNoData64(val float64, nodata float64, tol noDataTolerance) bool {
+	if math.IsNaN(val) {
+		return true
+	}
+	if tol.isZero() || math.IsInf(nodata, 0) {
+		return val == nodata
+	}
+	return math.Abs(val-nodata) <= math.Max(float64(tol.abs), float64(tol.rel)*math.Abs(nodata))
+}
+
+// smoothTimeSeries replaces the values of every column of the rows of
+// nCols columns by their centred moving average over window bands. The
+// window shrinks at the edges of the series rather than padding it. The
+// rows without a valid value, i.e. of zero count, are left out of the
+// averages and left as they are, and the counts are preserved.
+func smoothTimeSeries(avgs []*pb.TimeSeries, nCols int, window int) {
+	nBands := len(avgs) / nCols
+	vals := make([]float64, nBands)
+	for ic := 0; ic < nCols; ic++ {
+		for ib := range vals {
+			vals[ib] = avgs[ib*nCols+ic].Value
+		}
+		for ib := 0; ib < nBands; ib++ {
+			row := avgs[ib*nCols+ic]
+			if row.Count == 0 {
+				continue
+			}
+
+			bgn, end := ib-(window-1)/2, ib+window/2+1
+			if bgn < 0 {
+				bgn = 0
+			}
+			if end > nBands {
+				end = nBands
+			}
+			var sum float64
+			var n int
+			for jb := bgn; jb < end; jb++ {
+				if avgs[jb*nCols+ic].Count > 0 {
+					sum += vals[jb]
+					n++
+				}
+			}
+			row.Value = sum / float64(n)
+		}
+	}
+}
+
+// p2Quantile estimates a quantile of a stream of values with the P²
+// algorithm of Jain and Chlamtac, which tracks five markers at the
+// minimum, the quantile, the maximum and halfway in between, adjusting
+// their heights by piecewise parabolic interpolation as values arrive.
+// It needs constant memory and no sort, at the cost of a small error
+// compared with the exact quantile.
+type p2Quantile struct {
+	p       float64
+	n       int
+	heights [5]float64
+	pos     [5]float64
+	desired [5]float64
+	incr    [5]float64
+}
+
+func newP2Quantile(p float64) *p2Quantile {
+	return &p2Quantile{
+		p:       p,
+		pos:     [5]float64{1, 2, 3, 4, 5},
+		desired: [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5},
+		incr:    [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+func (e *p2Quantile) add(x float64) {
+	// The first five values are the initial heights of the markers
+	if e.n < 5 {
+		e.heights[e.n] = x
+		e.n++
+		if e.n == 5 {
+			sort.Float64s(e.heights[:])
+		}
+		return
+	}
+	e.n++
+
+	// The cell k of the value, whose upper markers move up one position
+	var k int
+	switch {
+	case x < e.heights[0]:
+		e.heights[0] = x
+		k = 0
+	case x >= e.heights[4]:
+		e.heights[4] = x
+		k = 3
+	default:
+		for x >= e.heights[k+1] {
+			k++
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		e.pos[i]++
+	}
+	for i := range e.desired {
+		e.desired[i] += e.incr[i]
+	}
+
+	// The middle markers off their desired position by a step or more
+	// move one step towards it if their neighbours leave room
+	for i := 1; i < 4; i++ {
+		d := e.desired[i] - e.pos[i]
+		if (d >= 1 && e.pos[i+1]-e.pos[i] > 1) || (d <= -1 && e.pos[i-1]-e.pos[i] < -1) {
+			step := 1.0
+			if d < 0 {
+				step = -1
+			}
+			height := e.parabolic(i, step)
+			if height <= e.heights[i-1] || height >= e.heights[i+1] {
+				height = e.linear(i, step)
+			}
+			e.heights[i] = height
+			e.pos[i] += step
+		}
+	}
+}
+
+// parabolic returns the height of marker i moved by step with the
+// piecewise parabolic prediction.
+func (e *p2Quantile) parabolic(i int, step float64) float64 {
+	h, n := e.heights, e.pos
+	return h[i] + step/(n[i+1]-n[i-1])*((n[i]-n[i-1]+step)*(h[i+1]-h[i])/(n[i+1]-n[i])+(n[i+1]-n[i]-step)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+// linear returns the height of marker i moved by step with the linear
+// prediction from the neighbour towards which it moves.
+func (e *p2Quantile) linear(i int, step float64) float64 {
+	j := i + int(step)
+	return e.heights[i] + step*(e.heights[j]-e.heights[i])/(e.pos[j]-e.pos[i])
+}
+
+// value returns the estimated quantile, which is exact for less than
+// five values and for the minimum and the maximum, or zero without
+// values.
+func (e *p2Quantile) value() float64 {
+	if e.n == 0 {
+		return 0
+	}
+	if e.n < 5 {
+		sorted := make([]float32, e.n)
+		for i := range sorted {
+			sorted[i] = float32(e.heights[i])
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		return float64(computePercentiles(sorted, []float64{100 * e.p})[0])
+	}
+	// The extreme markers are the exact minimum and maximum
+	switch {
+	case e.p <= 0:
+		return e.heights[0]
+	case e.p >= 1:
+		return e.heights[4]
+	}
+	return e.heights[2]
+}
+
+// bandInfo holds the metadata of a band needed by the reductions.
+// NoData is matched against the raw pixel values, which are then
+// converted to physical values as val*scale + offset.
+type bandInfo struct {
+	noData        float32
+	scale, offset float32
+
+	// rawNoData is the NoData value as declared by the band, which
+	// 32-bit integer values are matched against exactly.
+	rawNoData float64
+}
+
+// bandReducer reduces the pixels of a band within a zone of the window
+// to a row of statistics. The mean and the streaming statistics are
+// accumulated in a single pass over the pixels, whereas the order
+// statistics are computed from a copy of the valid pixels. Each
+// statistic fills its columns of the row in its own method.
+type bandReducer struct {
+	in        *pb.GeoRPCGranule
+	band      bandInfo
+	nodataTol noDataTolerance
+	isInteger bool
+	// nodata flags the undefined statistics of the row
+	nodata    float64
+	pixelArea float64
+
+	// The pixel values of the band and, for wide integer bands, their
+	// exact values, along with the mask and the optional weights of the
+	// zone. The deciles are computed over the pixels of decileMask.
+	vals         []float32
+	wide         []float64
+	mask         *MaskBuffer
+	decileMask   *MaskBuffer
+	weights      []float32
+	pixelWeights []float32
+
+	// gdalHist holds the histogram computed by GDAL, if any
+	gdalHist []int64
+
+	clipLower, clipUpper float32
+	sigmaRejected        int
+
+	// weighted sum of the values and of the weights the mean is
+	// divided by
+	acc meanSum
+	// sums of the weighted unit vectors of the phase
+	sumSin, sumCos float64
+	total          int32
+	// valid pixels dropped by the clip bounds
+	clipped int32
+	// weighted pixel count, equal to total without fractional coverage
+	wTotal      float32
+	validPixels int64
+	spread      welford
+	shape       moments
+	minMax      minMaxAccumulator
+	// Without an explicit range, the histogram spans the values of the
+	// band and is filled in a second pass.
+	hist     *histogram
+	fillHist bool
+	valRange minMaxAccumulator
+	// Geometric and harmonic means are only defined for positive
+	// values, hence the other values are skipped.
+	posMeans positiveMeans
+	classes  *classCounter
+	pixels   *pb.BandPixels
+}
+
+// value returns the value of pixel i with the band scale and offset
+// applied along with its exact float64 value, which differs for wide
+// integer bands only. The pixel is valid if within the mask with
+// non-zero coverage and not NoData, the NoData of wide integer bands
+// being matched against their exact values.
+func (r *bandReducer) value(mask *MaskBuffer, i int) (float32, float64, bool) {
+	if !mask.isSet(i) || (r.weights != nil && r.weights[i] == 0) {
+		return 0, 0, false
+	}
+	if r.wide != nil {
+		if isNoData64(r.wide[i], r.band.rawNoData, r.nodataTol) {
+			return 0, 0, false
+		}
+		val := r.vals[i]*r.band.scale + r.band.offset
+		return val, r.wide[i]*float64(r.band.scale) + float64(r.band.offset), true
+	}
+	if isNoData(r.vals[i], r.band.noData, r.nodataTol) {
+		return 0, 0, false
+	}
+	val := r.vals[i]*r.band.scale + r.band.offset
+	return val, float64(val), true
+}
+
+// validValues returns a copy of the values of the valid pixels within
+// the mask.
+func (r *bandReducer) validValues(mask *MaskBuffer) []float32 {
+	var buf []float32
+	for i := range r.vals {
+		if val, _, ok := r.value(mask, i); ok {
+			buf = append(buf, val)
+		}
+	}
+	return buf
+}
+
+// checksum returns the checksum of the valid pixels, which lets runs
+// which should be identical, e.g. approximate and exact drills, be
+// compared without returning the pixels.
+func (r *bandReducer) checksum(band int32) *pb.BandChecksum {
+	buf := r.validValues(r.mask)
+	return &pb.BandChecksum{Band: band, Checksum: pixelChecksum(buf), Count: int64(len(buf))}
+}
+
+// setClipBounds sets the clip bounds of the band. Percentile bounds are
+// computed from the valid pixels, and sigma clipping narrows the bounds
+// further, rejecting the outliers, e.g. cloud or shadow, around the mean
+// of the band.
+func (r *bandReducer) setClipBounds(lower, upper float32, sigmaIterations int) {
+	if r.in.ClipByPercentile {
+		buf := r.validValues(r.mask)
+		sort.Slice(buf, func(i, j int) bool { return buf[i] < buf[j] })
+		bounds := computePercentiles(buf, []float64{float64(lower), float64(upper)})
+		lower, upper = bounds[0], bounds[1]
+	}
+	if r.in.SigmaClip > 0 {
+		buf := r.validValues(r.mask)
+		lower, upper, r.sigmaRejected = sigmaClipBounds(buf, lower, upper, r.in.ClipInclusive, r.in.SigmaClip, sigmaIterations)
+	}
+	r.clipLower, r.clipUpper = lower, upper
+}
+
+// accumulate reduces the valid pixels to the mean and the streaming
+// statistics in a single pass. The values are accumulated in float64
+// from the exact values of wide integer bands.
+func (r *bandReducer) accumulate(band int32, returnPixels bool) {
+	in := r.in
+	r.acc = meanSum{compensated: in.CompensatedSum}
+	if returnPixels {
+		r.pixels = &pb.BandPixels{Band: band}
+	}
+	if in.HistogramBins > 0 && in.HistogramMin < in.HistogramMax {
+		r.hist = newHistogram(int(in.HistogramBins), in.HistogramMin, in.HistogramMax)
+	}
+	// histograms computed by GDAL are already filled
+	r.fillHist = r.hist != nil
+	if r.hist != nil && r.gdalHist != nil {
+		r.hist.counts = r.gdalHist
+		r.fillHist = false
+	}
+	if in.Categorical {
+		r.classes = newClassCounter()
+	}
+	phaseMean := in.ComplexPart == pb.ComplexPart_PHASE
+
+	for i := range r.vals {
+		val, val64, ok := r.value(r.mask, i)
+		if !ok {
+			continue
+		}
+		w := float32(1)
+		if r.weights != nil {
+			w = r.weights[i]
+		}
+		pw := float64(w)
+		if r.pixelWeights != nil {
+			pw *= float64(r.pixelWeights[i])
+		}
+		r.validPixels++
+		if r.pixels != nil {
+			r.pixels.Index = append(r.pixels.Index, int32(i))
+			r.pixels.Value = append(r.pixels.Value, val)
+		}
+
+		if in.PixelCount != 0 {
+			r.total++
+			r.wTotal += w
+			r.acc.addWeight(float64(w))
+		}
+
+		if isClipped(val, r.clipLower, r.clipUpper, in.ClipInclusive) {
+			r.clipped++
+			continue
+		}
+		if in.PixelCount == 0 {
+			r.acc.add(val64, pw)
+			if phaseMean {
+				r.sumSin += pw * math.Sin(val64)
+				r.sumCos += pw * math.Cos(val64)
+			}
+			r.total++
+			r.wTotal += w
+			if in.Aggregation != pb.Aggregation_ARITHMETIC {
+				r.posMeans.add(val64, pw)
+			}
+		} else {
+			r.acc.addValue(float64(w))
+		}
+		if in.ComputeStdDev || in.ComputeStdError || in.ComputeCV {
+			r.spread.add(val64)
+		}
+		if in.ComputeMoments {
+			r.shape.add(val64)
+		}
+		if in.ComputeMinMax {
+			r.minMax.add(val64)
+		}
+		if r.hist != nil {
+			if r.fillHist {
+				r.hist.add(val64)
+			}
+		} else if in.HistogramBins > 0 {
+			r.valRange.add(val64)
+		}
+		if r.classes != nil {
+			r.classes.add(val64, float64(w))
+		}
+	}
+
+	// With fractional coverage the count is the rounded sum of the
+	// pixel weights, but at least one if any pixel contributed.
+	if r.weights != nil && r.total > 0 {
+		r.total = int32(math.Max(1, math.Round(float64(r.wTotal))))
+	}
+}
+
+// histogram returns the histogram of the pixels within the clip bounds,
+// filling it in a second pass over the range of their values if no
+// explicit range is given.
+func (r *bandReducer) histogram(band int32) *pb.Histogram {
+	if r.hist == nil {
+		r.hist = newHistogram(int(r.in.HistogramBins), r.valRange.min, r.valRange.max)
+		if r.valRange.n > 0 {
+			for i := range r.vals {
+				val, val64, ok := r.value(r.mask, i)
+				if ok && !isClipped(val, r.clipLower, r.clipUpper, r.in.ClipInclusive) {
+					r.hist.add(val64)
+				}
+			}
+		}
+	}
+	return &pb.Histogram{Band: band, Edges: r.hist.edges(), Counts: r.hist.counts}
+}
+
+// classFractions returns the fractions of the classes of a categorical
+// band.
+func (r *bandReducer) classFractions(band int32) *pb.ClassFractions {
+	classValues, fractions, counts := r.classes.fractions(r.in.Classes)
+	majority, minority := r.classes.majorityMinority()
+	return &pb.ClassFractions{Band: band, Classes: classValues, Fractions: fractions, Counts: counts,
+		Majority: majority, Minority: minority, DistinctClasses: int32(len(r.classes.counts))}
+}
+
+// mean returns the mean column along with the counts of the pixels, i.e.
+// the arithmetic, geometric, harmonic or trimmed mean, the circular mean
+// of the phase or the fraction of the pixels within the clip bounds in
+// the pixel count mode. Bands with fewer valid pixels than minValid get
+// the NoData value.
+func (r *bandReducer) mean(maskedPixels int, minValid int64) *pb.TimeSeries {
+	in := r.in
+	mean := &pb.TimeSeries{Value: 0, Count: 0}
+	if r.total > 0 && in.ComplexPart == pb.ComplexPart_PHASE {
+		mean = &pb.TimeSeries{Value: math.Atan2(r.sumSin, r.sumCos), Count: r.total}
+	} else if r.total > 0 {
+		mean = &pb.TimeSeries{Value: r.acc.mean(), Count: r.total}
+	}
+	if in.PixelCount == 0 && in.Aggregation != pb.Aggregation_ARITHMETIC {
+		mean = &pb.TimeSeries{Value: 0, Count: 0}
+		if r.posMeans.n > 0 {
+			count := r.posMeans.n
+			if r.weights != nil && r.pixelWeights == nil {
+				count = int32(math.Max(1, math.Round(r.posMeans.wSum)))
+			}
+			val := r.posMeans.geometric()
+			if in.Aggregation == pb.Aggregation_HARMONIC {
+				val = r.posMeans.harmonic()
+			}
+			mean = &pb.TimeSeries{Value: val, Count: count}
+		}
+	}
+
+	// The trimmed mean discards the tails of the pixels within the clip
+	// bounds, i.e. clipping applies first. The pixels are equally
+	// weighted regardless of fractional coverage.
+	if in.PixelCount == 0 && in.TrimFraction > 0 {
+		mean = &pb.TimeSeries{Value: 0, Count: 0}
+		if r.total > 0 {
+			var buf []float32
+			for _, val := range r.validValues(r.mask) {
+				if !isClipped(val, r.clipLower, r.clipUpper, in.ClipInclusive) {
+					buf = append(buf, val)
+				}
+			}
+			sort.Slice(buf, func(i, j int) bool { return buf[i] < buf[j] })
+			val, n := trimmedMean(buf, in.TrimFraction)
+			mean = &pb.TimeSeries{Value: val, Count: int32(n)}
+		}
+	}
+	if in.PixelCount == 0 && r.validPixels < minValid {
+		mean = &pb.TimeSeries{Value: r.nodata, Count: 0}
+	}
+
+	// A large share of clipped pixels often flags a quality problem of
+	// the band rather than genuine outliers. Only the bands read report
+	// it, interpolated bands don't. The counts of the valid pixels and of
+	// the pixels within the geometry are returned along with the mean,
+	// whichever statistic it holds.
+	mean.ClippedCount = r.clipped
+	mean.SigmaRejectedCount = int64(r.sigmaRejected)
+	mean.ValidCount = r.validPixels
+	mean.TotalMaskedCount = int64(maskedPixels)
+	return mean
+}
+
+// deciles returns the columns of the requested percentiles of the valid
+// pixels within the decile mask, or of decileCount evenly spaced
+// quantiles if no explicit percentiles are given. Approximate
+// percentiles are estimated in a single pass by the P² algorithm instead
+// of sorting a copy of the pixels, which trades a small error for
+// constant memory.
+func (r *bandReducer) deciles(decileCount int) []*pb.TimeSeries {
+	var deciles []float32
+	if r.total > 0 {
+		percentiles := r.in.Percentiles
+		if len(percentiles) == 0 {
+			percentiles = decilePercentiles(decileCount)
+		}
+		if r.in.ApproxQuantiles {
+			deciles = r.approxPercentiles(r.decileMask, percentiles)
+		} else if buf := r.validValues(r.decileMask); len(buf) > 0 {
+			sort.Slice(buf, func(i, j int) bool { return buf[i] < buf[j] })
+			deciles = computePercentiles(buf, percentiles)
+		}
+	}
+
+	cols := make([]*pb.TimeSeries, decileCount)
+	for ic := range cols {
+		cols[ic] = &pb.TimeSeries{Value: 0, Count: 0}
+		if deciles != nil {
+			cols[ic] = &pb.TimeSeries{Value: float64(deciles[ic]), Count: 1}
+		}
+	}
+	return cols
+}
+
+// approxPercentiles estimates the requested percentiles of the valid
+// pixels within the mask with a P² estimator per percentile. It returns
+// nil if there are no valid pixels.
+func (r *bandReducer) approxPercentiles(mask *MaskBuffer, percentiles []float64) []float32 {
+	estimators := make([]*p2Quantile, len(percentiles))
+	for i, pct := range percentiles {
+		estimators[i] = newP2Quantile(pct / 100)
+	}
+	n := 0
+	for i := range r.vals {
+		val, _, ok := r.value(mask, i)
+		if !ok {
+			continue
+		}
+		for _, est := range estimators {
+			est.add(float64(val))
+		}
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+
+	res := make([]float32, len(estimators))
+	for i, est := range estimators {
+		res[i] = float32(est.value())
+	}
+	return res
+}
+
+// stdDev returns the standard deviation and the variance columns.
+func (r *bandReducer) stdDev() (*pb.TimeSeries, *pb.TimeSeries) {
+	n := int32(r.spread.n)
+	return &pb.TimeSeries{Value: math.Sqrt(r.spread.variance()), Count: n}, &pb.TimeSeries{Value: r.spread.variance(), Count: n}
+}
+
+// median returns the median column, estimated by the P² algorithm for
+// approximate quantiles.
+func (r *bandReducer) median() *pb.TimeSeries {
+	if r.total == 0 {
+		return &pb.TimeSeries{Value: 0, Count: 0}
+	}
+	if r.in.ApproxQuantiles {
+		if median := r.approxPercentiles(r.mask, []float64{50}); median != nil {
+			return &pb.TimeSeries{Value: float64(median[0]), Count: 1}
+		}
+	} else if buf := r.validValues(r.mask); len(buf) > 0 {
+		return &pb.TimeSeries{Value: float64(computeMedian(buf)), Count: 1}
+	}
+	return &pb.TimeSeries{Value: 0, Count: 0}
+}
+
+// minMaxColumns returns the min and max columns of the pixels within the
+// clip bounds, flagged by the NoData value and a zero count without any.
+func (r *bandReducer) minMaxColumns() (*pb.TimeSeries, *pb.TimeSeries) {
+	if r.minMax.n == 0 {
+		return &pb.TimeSeries{Value: r.nodata, Count: 0}, &pb.TimeSeries{Value: r.nodata, Count: 0}
+	}
+	return &pb.TimeSeries{Value: r.minMax.min, Count: r.minMax.n}, &pb.TimeSeries{Value: r.minMax.max, Count: r.minMax.n}
+}
+
+// mode returns the mode column.
+func (r *bandReducer) mode() *pb.TimeSeries {
+	if r.total > 0 {
+		if buf := r.validValues(r.mask); len(buf) > 0 {
+			mode, count := computeMode(buf, r.isInteger, r.in)
+			return &pb.TimeSeries{Value: mode, Count: int32(count)}
+		}
+	}
+	return &pb.TimeSeries{Value: 0, Count: 0}
+}
+
+// robustSpread returns the interquartile range and the median absolute
+// deviation columns, which share the sorted copy of the valid pixels.
+func (r *bandReducer) robustSpread() (*pb.TimeSeries, *pb.TimeSeries) {
+	iqr, mad := &pb.TimeSeries{Value: 0, Count: 0}, &pb.TimeSeries{Value: 0, Count: 0}
+	if r.total == 0 {
+		return iqr, mad
+	}
+	buf := r.validValues(r.mask)
+	if len(buf) > 0 {
+		sort.Slice(buf, func(i, j int) bool { return buf[i] < buf[j] })
+		if r.in.ComputeIQR {
+			iqr = &pb.TimeSeries{Value: float64(interquartileRange(buf)), Count: 1}
+		}
+		if r.in.ComputeMAD {
+			mad = &pb.TimeSeries{Value: float64(medianAbsDeviation(buf)), Count: 1}
+		}
+	}
+	return iqr, mad
+}
+
+// sum returns the sum of the weighted pixel values within the clip
+// bounds, optionally multiplied by the pixel area to integrate over the
+// area of the geometry.
+func (r *bandReducer) sum() *pb.TimeSeries {
+	val := r.acc.total()
+	if r.in.SumByArea {
+		val *= r.pixelArea
+	}
+	return &pb.TimeSeries{Value: val, Count: r.total}
+}
+
+// stdError returns the standard error of the mean, which isn't defined
+// for less than two pixels, flagged by a zero count.
+func (r *bandReducer) stdError() *pb.TimeSeries {
+	if r.spread.n < 2 {
+		return &pb.TimeSeries{Value: 0, Count: 0}
+	}
+	return &pb.TimeSeries{Value: r.spread.stdError(), Count: int32(r.spread.n)}
+}
+
+// coefVariation returns the coefficient of variation, flagged by the
+// NoData value and a zero count for a mean about zero.
+func (r *bandReducer) coefVariation() *pb.TimeSeries {
+	if cv, ok := r.spread.coefVariation(); ok {
+		return &pb.TimeSeries{Value: cv, Count: int32(r.spread.n)}
+	}
+	return &pb.TimeSeries{Value: r.nodata, Count: 0}
+}
+
+// noDataFraction returns the fraction of the pixels within the geometry
+// which are NoData, e.g. filled clouds, counted over those pixels.
+func (r *bandReducer) noDataFraction(maskedPixels int) *pb.TimeSeries {
+	if maskedPixels == 0 {
+		return &pb.TimeSeries{Value: 0, Count: 0}
+	}
+	fraction := 1 - float64(r.validPixels)/float64(maskedPixels)
+	return &pb.TimeSeries{Value: fraction, Count: int32(maskedPixels)}
+}
+
+// moments returns the skewness and the excess kurtosis columns, biased
+// population estimates flagged by a zero count for too few pixels.
+func (r *bandReducer) moments() (*pb.TimeSeries, *pb.TimeSeries) {
+	skewCol, kurtCol := &pb.TimeSeries{Value: 0, Count: 0}, &pb.TimeSeries{Value: 0, Count: 0}
+	if skew, ok := r.shape.skewness(); ok {
+		skewCol = &pb.TimeSeries{Value: skew, Count: int32(r.shape.n)}
+	}
+	if kurt, ok := r.shape.kurtosis(); ok {
+		kurtCol = &pb.TimeSeries{Value: kurt, Count: int32(r.shape.n)}
+	}
+	return skewCol, kurtCol
+}
+
+// coherence returns the length of the mean unit vector of the phase,
+// which is 1 for a constant phase and about 0 for a uniformly spread one.
+func (r *bandReducer) coherence() *pb.TimeSeries {
+	if r.total == 0 || r.acc.totalWeight() == 0 {
+		return &pb.TimeSeries{Value: 0, Count: 0}
+	}
+	return &pb.TimeSeries{Value: math.Hypot(r.sumSin, r.sumCos) / r.acc.totalWeight(), Count: r.total}
+}
+
+// reduce fills the row of the band after the pixels are accumulated,
+// i.e. the mean followed by the optional columns in the order of the
+// flags of the request, see readData.
+func (r *bandReducer) reduce(row []*pb.TimeSeries, decileCount int, maskedPixels int, minValid int64) {
+	in := r.in
+	row[0] = r.mean(maskedPixels, minValid)
+	iCol := 1
+	iCol += copy(row[iCol:], r.deciles(decileCount))
+	if in.ComputeStdDev {
+		row[iCol], row[iCol+1] = r.stdDev()
+		iCol += 2
+	}
+	if in.ComputeMedian {
+		row[iCol] = r.median()
+		iCol++
+	}
+	if in.ComputeMinMax {
+		row[iCol], row[iCol+1] = r.minMaxColumns()
+		iCol += 2
+	}
+	if in.ComputeMode {
+		row[iCol] = r.mode()
+		iCol++
+	}
+	if in.ComputeIQR || in.ComputeMAD {
+		iqr, mad := r.robustSpread()
+		if in.ComputeIQR {
+			row[iCol] = iqr
+			iCol++
+		}
+		if in.ComputeMAD {
+			row[iCol] = mad
+			iCol++
+		}
+	}
+	if in.ComputeSum {
+		row[iCol] = r.sum()
+		iCol++
+	}
+	if in.ComputeStdError {
+		row[iCol] = r.stdError()
+		iCol++
+	}
+	if in.ComputeCV {
+		row[iCol] = r.coefVariation()
+		iCol++
+	}
+	if in.ComputeNoDataFraction {
+		row[iCol] = r.noDataFraction(maskedPixels)
+		iCol++
+	}
+	if in.ComputeMoments {
+		row[iCol], row[iCol+1] = r.moments()
+		iCol += 2
+	}
+	if in.ComplexPart == pb.ComplexPart_PHASE {
+		row[iCol] = r.coherence()
+	}
+}
@@ -0,0 +1,710 @@
+package gdalprocess
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	pb "github.com/nci/gsky/worker/gdalservice"
+)
+
+func TestMeanSumDrift(t *testing.T) {
+	// A constant field of 10M pixels, whose float32 sum stalls long
+	// before all the pixels are added
+	const n = 10000000
+	val := float32(0.1)
+
+	var sum32 float32
+	var acc meanSum
+	for i := 0; i < n; i++ {
+		sum32 += val
+		acc.add(float64(val), 1)
+	}
+
+	if drift := math.Abs(float64(sum32)/n-float64(val)) / float64(val); drift < 0.01 {
+		t.Errorf("expected the float32 mean to drift, actual drift %v", drift)
+	}
+	if drift := math.Abs(acc.mean()-float64(val)) / float64(val); drift > 1e-9 {
+		t.Errorf("unexpected drift of the mean: %v", drift)
+	}
+}
+
+func TestMeanSumCompensated(t *testing.T) {
+	// Every small value is lost next to the large ones, which cancel
+	// out, unless the rounding errors are carried
+	plain := meanSum{}
+	compensated := meanSum{compensated: true}
+	for i := 0; i < 1000; i++ {
+		for _, val := range []float64{1e16, 1, -1e16} {
+			plain.addValue(val)
+			compensated.addValue(val)
+		}
+	}
+
+	if plain.total() == 1000 {
+		t.Errorf("expected the plain sum to lose the small values")
+	}
+	if compensated.total() != 1000 {
+		t.Errorf("unexpected compensated sum: expected 1000, actual %v", compensated.total())
+	}
+}
+
+func BenchmarkMeanSum(b *testing.B) {
+	for _, compensated := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compensated=%v", compensated), func(b *testing.B) {
+			acc := meanSum{compensated: compensated}
+			for i := 0; i < b.N; i++ {
+				acc.add(float64(i%256)*0.1, 1)
+			}
+		})
+	}
+}
+
+func TestPixelChecksum(t *testing.T) {
+	// The checksum only depends on the set of values
+	sum := pixelChecksum([]float32{3, 1, 2})
+	if other := pixelChecksum([]float32{2, 3, 1}); other != sum {
+		t.Errorf("expected the same checksum regardless of order, got %x and %x", sum, other)
+	}
+	if other := pixelChecksum([]float32{3, 1, 2.5}); other == sum {
+		t.Errorf("expected distinct checksums for distinct values")
+	}
+}
+
+func TestApplyStatsMask(t *testing.T) {
+	in := &pb.GeoRPCGranule{Stats: uint32(pb.Statistic_STAT_MINMAX | pb.Statistic_STAT_SUM), ComputeMedian: true}
+	applyStatsMask(in)
+	if !in.ComputeMinMax || !in.ComputeSum || !in.ComputeMedian {
+		t.Errorf("expected the min/max, sum and median flags set: %v", in)
+	}
+	if in.ComputeStdDev || in.ComputeMode || in.ComputeCV {
+		t.Errorf("unexpected flags set: %v", in)
+	}
+}
+
+func TestWelfordVariance(t *testing.T) {
+	var w welford
+	for _, val := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		w.add(val)
+	}
+
+	if w.mean != 5 {
+		t.Errorf("unexpected mean: expected 5, actual %v", w.mean)
+	}
+	if math.Abs(w.variance()-4) > 1e-12 {
+		t.Errorf("unexpected variance: expected 4, actual %v", w.variance())
+	}
+
+	// a large offset must not degrade the result
+	var wo welford
+	for _, val := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		wo.add(val + 1e9)
+	}
+	if math.Abs(wo.variance()-4) > 1e-6 {
+		t.Errorf("unexpected variance with offset: expected 4, actual %v", wo.variance())
+	}
+
+	var empty welford
+	if empty.variance() != 0 {
+		t.Errorf("unexpected variance of empty accumulator: %v", empty.variance())
+	}
+}
+
+func TestWelfordStdError(t *testing.T) {
+	var w welford
+	for _, val := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		w.add(val)
+	}
+	// sample variance 32/7 over 8 values
+	expected := math.Sqrt(32.0/7) / math.Sqrt(8)
+	if math.Abs(w.stdError()-expected) > 1e-12 {
+		t.Errorf("unexpected standard error: expected %v, actual %v", expected, w.stdError())
+	}
+
+	var single welford
+	single.add(3)
+	if single.stdError() != 0 {
+		t.Errorf("unexpected standard error of a single value: %v", single.stdError())
+	}
+}
+
+func TestWelfordCoefVariation(t *testing.T) {
+	var w welford
+	for _, val := range []float64{2, 4, 4, 4, 5, 5, 7, 9} {
+		w.add(val)
+	}
+	// population standard deviation 2 over a mean of 5
+	if cv, ok := w.coefVariation(); !ok || math.Abs(cv-0.4) > 1e-12 {
+		t.Errorf("unexpected coefficient of variation: expected 0.4, actual %v (%v)", cv, ok)
+	}
+
+	var centered welford
+	for _, val := range []float64{-1, 1} {
+		centered.add(val)
+	}
+	if _, ok := centered.coefVariation(); ok {
+		t.Errorf("expected an undefined coefficient of variation for a zero mean")
+	}
+}
+
+func TestMoments(t *testing.T) {
+	vals := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	var m moments
+	for _, val := range vals {
+		m.add(val)
+	}
+
+	// two-pass population moments
+	var mean, m2, m3, m4 float64
+	for _, val := range vals {
+		mean += val
+	}
+	mean /= float64(len(vals))
+	for _, val := range vals {
+		d := val - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	n := float64(len(vals))
+	expectedSkew := (m3 / n) / math.Pow(m2/n, 1.5)
+	expectedKurt := (m4/n)/((m2/n)*(m2/n)) - 3
+
+	if skew, ok := m.skewness(); !ok || math.Abs(skew-expectedSkew) > 1e-12 {
+		t.Errorf("unexpected skewness: expected %v, actual %v (%v)", expectedSkew, skew, ok)
+	}
+	if kurt, ok := m.kurtosis(); !ok || math.Abs(kurt-expectedKurt) > 1e-12 {
+		t.Errorf("unexpected kurtosis: expected %v, actual %v (%v)", expectedKurt, kurt, ok)
+	}
+
+	var few moments
+	for _, val := range []float64{1, 2, 3} {
+		few.add(val)
+	}
+	if _, ok := few.skewness(); !ok {
+		t.Errorf("expected the skewness of 3 values")
+	}
+	if _, ok := few.kurtosis(); ok {
+		t.Errorf("expected an undefined kurtosis of 3 values")
+	}
+}
+
+func TestComputePercentiles(t *testing.T) {
+	sorted := []float32{1, 2, 3, 4, 5}
+	res := computePercentiles(sorted, []float64{0, 5, 50, 95, 100})
+	expected := []float32{1, 1.2, 3, 4.8, 5}
+	for i := range expected {
+		if math.Abs(float64(res[i]-expected[i])) > 1e-6 {
+			t.Errorf("unexpected percentiles: expected %v, actual %v", expected, res)
+			break
+		}
+	}
+
+	res = computePercentiles([]float32{7}, []float64{10, 90})
+	if res[0] != 7 || res[1] != 7 {
+		t.Errorf("unexpected percentiles for a single value: %v", res)
+	}
+}
+
+func TestComputeDeciles(t *testing.T) {
+	// expected values are numpy.percentile(data[:n], [10, 20, ..., 90])
+	data := []float32{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5, 8, 9, 7, 9, 3, 2, 3, 8, 4}
+	tests := []struct {
+		n        int
+		expected []float32
+	}{
+		{1, []float32{3, 3, 3, 3, 3, 3, 3, 3, 3}},
+		{2, []float32{1.2, 1.4, 1.6, 1.8, 2, 2.2, 2.4, 2.6, 2.8}},
+		{3, []float32{1.4, 1.8, 2.2, 2.6, 3, 3.2, 3.4, 3.6, 3.8}},
+		{4, []float32{1, 1, 1, 1.4, 2, 2.6, 3.1, 3.4, 3.7}},
+		{5, []float32{1, 1, 1.4, 2.2, 3, 3.4, 3.8, 4.2, 4.6}},
+		{6, []float32{1, 1, 2, 3, 3.5, 4, 4.5, 5, 7}},
+		{7, []float32{1, 1.2, 1.8, 2.4, 3, 3.6, 4.2, 4.8, 6.6}},
+		{8, []float32{1, 1.4, 2.1, 2.8, 3.5, 4.2, 4.9, 5.6, 6.9}},
+		{9, []float32{1, 1.6, 2.4, 3.2, 4, 4.8, 5, 5.4, 6.6}},
+		{10, []float32{1, 1.8, 2.7, 3, 3.5, 4.4, 5, 5.2, 6.3}},
+		{11, []float32{1, 2, 3, 3, 4, 5, 5, 5, 6}},
+		{12, []float32{1.1, 2.2, 3, 3.4, 4.5, 5, 5, 5.8, 7.8}},
+		{13, []float32{1.2, 2.4, 3, 3.8, 5, 5, 5.4, 7.2, 8.8}},
+		{14, []float32{1.3, 2.6, 3, 4.2, 5, 5, 6.1, 7.4, 8.7}},
+		{15, []float32{1.4, 2.8, 3.2, 4.6, 5, 5.4, 6.8, 8.2, 9}},
+		{16, []float32{1.5, 3, 3, 4, 5, 5, 6.5, 8, 9}},
+		{17, []float32{1.6, 2.2, 3, 3.4, 5, 5, 6.2, 7.8, 9}},
+		{18, []float32{1.7, 2.4, 3, 3, 4.5, 5, 5.9, 7.6, 9}},
+		{19, []float32{1.8, 2.6, 3, 3.2, 5, 5, 6.6, 8, 9}},
+		{20, []float32{1.9, 2.8, 3, 3.6, 4.5, 5, 6.3, 8, 9}},
+	}
+
+	for _, tc := range tests {
+		sorted := make([]float32, tc.n)
+		copy(sorted, data[:tc.n])
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		res := computePercentiles(sorted, decilePercentiles(9))
+		for i := range tc.expected {
+			if math.Abs(float64(res[i]-tc.expected[i])) > 1e-5 {
+				t.Errorf("n=%d: expected %v, actual %v", tc.n, tc.expected, res)
+				break
+			}
+		}
+	}
+}
+
+func TestComputeDecilesSmallBuffers(t *testing.T) {
+	// Every buffer smaller than the number of cut points, including the
+	// empty one, with expected values from numpy.percentile
+	tests := []struct {
+		sorted      []float32
+		decileCount int
+		expected    []float32
+	}{
+		{nil, 3, []float32{0, 0, 0}},
+		{[]float32{7}, 3, []float32{7, 7, 7}},
+		{[]float32{7}, 9, []float32{7, 7, 7, 7, 7, 7, 7, 7, 7}},
+		{[]float32{2, 4}, 3, []float32{2.5, 3, 3.5}},
+		{[]float32{2, 4}, 4, []float32{2.4, 2.8, 3.2, 3.6}},
+		{[]float32{1, 2, 4}, 3, []float32{1.5, 2, 3}},
+		{[]float32{1, 2, 4}, 4, []float32{1.4, 1.8, 2.4, 3.2}},
+		{[]float32{1, 1, 1}, 9, []float32{1, 1, 1, 1, 1, 1, 1, 1, 1}},
+		{[]float32{1, 2, 4, 8}, 9, []float32{1.3, 1.6, 1.9, 2.4, 3, 3.6, 4.4, 5.6, 6.8}},
+	}
+
+	for _, tc := range tests {
+		res := computePercentiles(tc.sorted, decilePercentiles(tc.decileCount))
+		if len(res) != tc.decileCount {
+			t.Fatalf("%v: expected %d deciles, actual %v", tc.sorted, tc.decileCount, res)
+		}
+		for i := range tc.expected {
+			if math.Abs(float64(res[i]-tc.expected[i])) > 1e-5 {
+				t.Errorf("%v with %d deciles: expected %v, actual %v", tc.sorted, tc.decileCount, tc.expected, res)
+				break
+			}
+		}
+	}
+}
+
+func TestParallelFor(t *testing.T) {
+	for _, nWorkers := range []int{0, 1, 3, 100} {
+		res := make([]int, 50)
+		parallelFor(len(res), nWorkers, func(i int) { res[i] = i * i })
+		for i := range res {
+			if res[i] != i*i {
+				t.Errorf("nWorkers=%d: unexpected value at %d: %v", nWorkers, i, res[i])
+				break
+			}
+		}
+	}
+}
+
+func TestParallelForPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("expected the panic to be propagated, got %v", r)
+		}
+	}()
+	parallelFor(10, 3, func(i int) {
+		if i == 5 {
+			panic("boom")
+		}
+	})
+}
+
+func TestComputeMedian(t *testing.T) {
+	tests := []struct {
+		buf      []float32
+		expected float32
+	}{
+		{[]float32{}, 0},
+		{[]float32{4}, 4},
+		{[]float32{4, 1}, 2.5},
+		{[]float32{3, 1, 2}, 2},
+		{[]float32{5, 5, 5, 1, 5}, 5},
+		{[]float32{9, 2, 7, 4, 1, 8}, 5.5},
+		{[]float32{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}, 4},
+	}
+
+	for _, tc := range tests {
+		buf := append([]float32{}, tc.buf...)
+		if res := computeMedian(buf); res != tc.expected {
+			t.Errorf("median of %v: expected %v, actual %v", tc.buf, tc.expected, res)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram(4, 0, 8)
+	for _, val := range []float64{-1, 0, 1, 2, 3.5, 4, 7.9, 8, 9, math.NaN()} {
+		h.add(val)
+	}
+
+	expCounts := []int64{2, 2, 1, 2}
+	for i, count := range h.counts {
+		if count != expCounts[i] {
+			t.Errorf("expected counts %v, got %v", expCounts, h.counts)
+			break
+		}
+	}
+
+	expEdges := []float64{0, 2, 4, 6, 8}
+	for i, edge := range h.edges() {
+		if edge != expEdges[i] {
+			t.Errorf("expected edges %v, got %v", expEdges, h.edges())
+			break
+		}
+	}
+
+	// A degenerate range puts every value equal to it in the last bin
+	h = newHistogram(3, 5, 5)
+	h.add(5)
+	h.add(6)
+	if h.counts[0] != 0 || h.counts[2] != 1 {
+		t.Errorf("unexpected counts for a degenerate range: %v", h.counts)
+	}
+}
+
+func TestPositiveMeans(t *testing.T) {
+	var means positiveMeans
+	for _, val := range []float64{1, 2, 4, 0, -3, math.NaN()} {
+		means.add(val, 1)
+	}
+
+	if means.n != 3 {
+		t.Errorf("expected non-positive values to be skipped, got %d values", means.n)
+	}
+	if math.Abs(means.geometric()-2) > 1e-12 {
+		t.Errorf("expected geometric mean 2, got %v", means.geometric())
+	}
+	if math.Abs(means.harmonic()-12.0/7) > 1e-12 {
+		t.Errorf("expected harmonic mean 12/7, got %v", means.harmonic())
+	}
+}
+
+func TestPCHIPInterpolate(t *testing.T) {
+	x := []float64{0, 0.5, 1, 2.5, 3, 4, 5.5, 6}
+
+	// Linear data is reproduced exactly
+	res := pchipInterpolate([]float64{0, 3, 6}, []float64{1, 4, 7}, x)
+	for i, val := range res {
+		if math.Abs(val-(x[i]+1)) > 1e-12 {
+			t.Errorf("expected %v at %v, got %v", x[i]+1, x[i], val)
+		}
+	}
+
+	// A step is interpolated monotonically without overshooting
+	xs := []float64{0, 1, 2, 3, 4}
+	ys := []float64{0, 0, 1, 1, 1}
+	dense := make([]float64, 41)
+	for i := range dense {
+		dense[i] = float64(i) / 10
+	}
+	res = pchipInterpolate(xs, ys, dense)
+	for i := range res {
+		if res[i] < 0 || res[i] > 1 || (i > 0 && res[i] < res[i-1]) {
+			t.Errorf("interpolant isn't monotone within the data range: %v", res)
+			break
+		}
+	}
+	for i, xi := range xs {
+		if res[int(xi)*10] != ys[i] {
+			t.Errorf("expected the interpolant to pass through the data, got %v at %v", res[int(xi)*10], xi)
+		}
+	}
+}
+
+func TestClassCounter(t *testing.T) {
+	c := newClassCounter()
+	for _, val := range []float64{3, 1, 3, 2, 3, 1, 2.0000001, 7} {
+		c.add(val, 1)
+	}
+
+	classes, fractions, counts := c.fractions(nil)
+	expClasses := []int32{1, 2, 3, 7}
+	expCounts := []int64{2, 2, 3, 1}
+	for i := range expClasses {
+		if classes[i] != expClasses[i] || counts[i] != expCounts[i] || fractions[i] != float64(expCounts[i])/8 {
+			t.Errorf("unexpected class fractions %v %v %v", classes, fractions, counts)
+			break
+		}
+	}
+
+	classes, fractions, counts = c.fractions([]int32{7, 5})
+	if classes[0] != 7 || fractions[0] != 1.0/8 || counts[0] != 1 || fractions[1] != 0 || counts[1] != 0 {
+		t.Errorf("unexpected fractions of the requested classes %v %v %v", classes, fractions, counts)
+	}
+
+	// The minority tie between 7 and 9 resolves to the lowest class
+	c.add(9, 1)
+	for i := 0; i < 10; i++ {
+		if majority, minority := c.majorityMinority(); majority != 3 || minority != 7 {
+			t.Fatalf("expected majority 3 and minority 7, got %v and %v", majority, minority)
+		}
+	}
+}
+
+func TestMode(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		mode, count := exactMode([]float32{5, 2, 7, 5, 2, 9})
+		if mode != 2 || count != 2 {
+			t.Fatalf("expected tie to resolve to 2 with count 2, got %v with count %v", mode, count)
+		}
+	}
+
+	h := newHistogram(4, 0, 8)
+	for _, val := range []float64{1, 6.5, 7, 3, 2.5} {
+		h.add(val)
+	}
+	mode, count := h.mode()
+	if mode != 3 || count != 2 {
+		t.Errorf("expected the lowest of the tied bins centred at 3 with count 2, got %v with count %v", mode, count)
+	}
+}
+
+func TestIsClipped(t *testing.T) {
+	tests := []struct {
+		val       float32
+		inclusive bool
+		clipped   bool
+	}{
+		{0, false, true},
+		{1, false, false},
+		{2, false, false},
+		{3, false, false},
+		{4, false, true},
+		{1, true, true},
+		{2, true, false},
+		{3, true, true},
+	}
+
+	for _, tc := range tests {
+		if clipped := isClipped(tc.val, 1, 3, tc.inclusive); clipped != tc.clipped {
+			t.Errorf("isClipped(%v, 1, 3, %v) = %v, expected %v", tc.val, tc.inclusive, clipped, tc.clipped)
+		}
+	}
+}
+
+func TestTrimmedMean(t *testing.T) {
+	sorted := []float32{-100, 1, 2, 3, 4, 5, 6, 7, 8, 1000}
+
+	mean, n := trimmedMean(sorted, 0.1)
+	if mean != 4.5 || n != 8 {
+		t.Errorf("expected 4.5 over 8 values, got %v over %v", mean, n)
+	}
+
+	// Fractions not reaching a whole value don't trim anything
+	mean, n = trimmedMean(sorted, 0.05)
+	if n != 10 || math.Abs(mean-93.6) > 1e-9 {
+		t.Errorf("expected 93.6 over 10 values, got %v over %v", mean, n)
+	}
+
+	if _, n = trimmedMean(nil, 0.2); n != 0 {
+		t.Errorf("expected no values retained from an empty buffer")
+	}
+}
+
+func TestRobustSpread(t *testing.T) {
+	sorted := []float32{1, 1, 2, 2, 4, 6, 9}
+
+	if iqr := interquartileRange(sorted); iqr != 3.5 {
+		t.Errorf("expected IQR 3.5, actual %v", iqr)
+	}
+	// deviations from the median 2 are 1, 1, 0, 0, 2, 4, 7
+	if mad := medianAbsDeviation(sorted); mad != 1 {
+		t.Errorf("expected MAD 1, actual %v", mad)
+	}
+
+	if iqr, mad := interquartileRange(nil), medianAbsDeviation(nil); iqr != 0 || mad != 0 {
+		t.Errorf("expected zero spread of an empty buffer, got IQR %v and MAD %v", iqr, mad)
+	}
+}
+
+func TestIsNoData(t *testing.T) {
+	nan := float32(math.NaN())
+	// The float32 values next to the large NoData are 2e31 and 9e12 apart
+	below := math.Nextafter32(-3.4e38, 0)
+	above := math.Nextafter32(1e20, math.MaxFloat32)
+	tests := []struct {
+		val, nodata float32
+		tol         noDataTolerance
+		expected    bool
+	}{
+		{-9999, -9999, noDataTolerance{}, true},
+		{1, -9999, noDataTolerance{}, false},
+		{nan, -9999, noDataTolerance{}, true},
+		{nan, nan, noDataTolerance{}, true},
+		{1, nan, noDataTolerance{}, false},
+		{-9998.5, -9999, noDataTolerance{abs: 1}, true},
+		{-9997, -9999, noDataTolerance{abs: 1}, false},
+		{-9998.5, -9999, noDataTolerance{rel: 1e-4}, true},
+		{-9997, -9999, noDataTolerance{abs: 1, rel: 1e-6}, false},
+		{-3.4e38, -3.4e38, noDataTolerance{}, true},
+		{below, -3.4e38, noDataTolerance{}, false},
+		{below, -3.4e38, noDataTolerance{abs: 1}, false},
+		{below, -3.4e38, noDataTolerance{abs: 1, rel: 1e-6}, true},
+		{above, 1e20, noDataTolerance{abs: 1e6}, false},
+		{above, 1e20, noDataTolerance{rel: 1e-6}, true},
+		{1.01e20, 1e20, noDataTolerance{rel: 1e-6}, false},
+		{1, float32(math.Inf(-1)), noDataTolerance{rel: 1e-6}, false},
+	}
+
+	for _, tc := range tests {
+		if res := isNoData(tc.val, tc.nodata, tc.tol); res != tc.expected {
+			t.Errorf("isNoData(%v, %v, %+v) = %v, expected %v", tc.val, tc.nodata, tc.tol, res, tc.expected)
+		}
+	}
+}
+
+func TestSigmaClipBounds(t *testing.T) {
+	vals := make([]float32, 0, 22)
+	for i := 0; i < 20; i++ {
+		vals = append(vals, float32(9+i%3))
+	}
+	// The outlier is rejected at first, which exposes the next one
+	vals = append(vals, 40, 1000)
+
+	lower, upper, rejected := sigmaClipBounds(vals, -1e6, 1e6, false, 3, 5)
+	if rejected != 2 {
+		t.Errorf("expected 2 rejected values, actual %d", rejected)
+	}
+	if lower > 9 || upper < 11 || upper >= 40 {
+		t.Errorf("expected bounds within (-inf, 9] and [11, 40), actual %v, %v", lower, upper)
+	}
+
+	// A single iteration only rejects the largest outlier
+	if _, _, rejected := sigmaClipBounds(vals, -1e6, 1e6, false, 3, 1); rejected != 1 {
+		t.Errorf("expected 1 rejected value, actual %d", rejected)
+	}
+
+	// Values already outside the bounds aren't counted as rejected
+	if _, _, rejected := sigmaClipBounds(vals, -1e6, 500, false, 3, 5); rejected != 1 {
+		t.Errorf("expected 1 rejected value, actual %d", rejected)
+	}
+
+	// Identical values are kept even with inclusive bounds
+	if _, _, rejected := sigmaClipBounds([]float32{5, 5, 5}, 0, 10, true, 1, 5); rejected != 0 {
+		t.Errorf("expected no rejected value, actual %d", rejected)
+	}
+}
+
+func TestSmoothTimeSeries(t *testing.T) {
+	means := []float64{1, 2, 3, 4, 10}
+	others := []float64{5, 5, -1, 7, 7}
+	avgs := make([]*pb.TimeSeries, 0, 2*len(means))
+	for ib := range means {
+		count := int32(1)
+		if ib == 2 {
+			count = 0
+		}
+		avgs = append(avgs, &pb.TimeSeries{Value: means[ib], Count: 3}, &pb.TimeSeries{Value: others[ib], Count: count})
+	}
+
+	smoothTimeSeries(avgs, 2, 3)
+	// The window shrinks at the edges and skips the invalid row
+	expectedMeans := []float64{1.5, 2, 3, 17.0 / 3, 7}
+	expectedOthers := []float64{5, 5, -1, 7, 7}
+	for ib := range means {
+		if math.Abs(avgs[2*ib].Value-expectedMeans[ib]) > 1e-9 || avgs[2*ib].Count != 3 {
+			t.Errorf("band %d: expected mean %v, actual %v", ib, expectedMeans[ib], avgs[2*ib])
+		}
+		if math.Abs(avgs[2*ib+1].Value-expectedOthers[ib]) > 1e-9 {
+			t.Errorf("band %d: expected %v, actual %v", ib, expectedOthers[ib], avgs[2*ib+1])
+		}
+	}
+	if avgs[5].Count != 0 {
+		t.Errorf("expected the count of the invalid row preserved, actual %d", avgs[5].Count)
+	}
+}
+
+func TestP2Quantile(t *testing.T) {
+	// A deterministic shuffle of 0..9999, whose exact quantiles are
+	// known, estimated within a fraction of a percent of the range
+	const n = 10000
+	vals := make([]float64, n)
+	for i := range vals {
+		vals[i] = float64((i * 7919) % n)
+	}
+	for _, p := range []float64{0, 0.1, 0.25, 0.5, 0.9, 0.99, 1} {
+		est := newP2Quantile(p)
+		for _, val := range vals {
+			est.add(val)
+		}
+		expected := p * (n - 1)
+		if actual := est.value(); math.Abs(actual-expected) > 0.005*n {
+			t.Errorf("quantile %v: expected about %v, actual %v", p, expected, actual)
+		}
+	}
+
+	// Less than five values give the exact quantile
+	est := newP2Quantile(0.5)
+	for _, val := range []float64{4, 1, 3} {
+		est.add(val)
+	}
+	if actual := est.value(); actual != 3 {
+		t.Errorf("expected the exact median 3, actual %v", actual)
+	}
+	if actual := newP2Quantile(0.5).value(); actual != 0 {
+		t.Errorf("expected 0 without values, actual %v", actual)
+	}
+}
+
+func TestBandReducer(t *testing.T) {
+	// 1..8 with a NoData pixel and a pixel outside of the mask
+	vals := []float32{1, 2, 3, 4, -9999, 5, 6, 7, 8, 100}
+	mask := &MaskBuffer{Byte: []uint8{1, 1, 1, 1, 1, 1, 1, 1, 1, 0}}
+	in := &pb.GeoRPCGranule{ComputeStdDev: true, ComputeMedian: true, ComputeMinMax: true, ComputeSum: true, ComputeNoDataFraction: true,
+		ClipLower: -math.MaxFloat32, ClipUpper: math.MaxFloat32}
+	r := &bandReducer{in: in, band: bandInfo{noData: -9999, rawNoData: -9999, scale: 1}, nodata: -9999, pixelArea: 2,
+		vals: vals, mask: mask, decileMask: mask}
+
+	r.setClipBounds(in.ClipLower, in.ClipUpper, 5)
+	r.accumulate(1, false)
+	row := make([]*pb.TimeSeries, 8)
+	r.reduce(row, 0, 9, 0)
+
+	expected := []float64{4.5, math.Sqrt(5.25), 5.25, 4.5, 1, 8, 36, 1.0 / 9}
+	for ic, val := range expected {
+		if math.Abs(row[ic].Value-val) > 1e-9 {
+			t.Errorf("column %d: expected %v, actual %v", ic, val, row[ic].Value)
+		}
+	}
+	if row[0].Count != 8 || row[0].ValidCount != 8 || row[0].TotalMaskedCount != 9 {
+		t.Errorf("unexpected counts of the mean: %v", row[0])
+	}
+
+	// The min and max of a band without valid pixels are NoData, unlike
+	// an actual min or max of zero
+	r = &bandReducer{in: in, band: bandInfo{noData: 100, rawNoData: 100, scale: 1}, nodata: -9999, pixelArea: 2,
+		vals: vals, mask: &MaskBuffer{Byte: []uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 1}}}
+	r.setClipBounds(in.ClipLower, in.ClipUpper, 5)
+	r.accumulate(1, false)
+	r.reduce(row, 0, 1, 0)
+	if min, max := row[4], row[5]; min.Value != -9999 || min.Count != 0 || max.Value != -9999 || max.Count != 0 {
+		t.Errorf("expected NoData min and max, got %v over %v and %v over %v", min.Value, min.Count, max.Value, max.Count)
+	}
+}
+
+func TestBandReducerWide(t *testing.T) {
+	// The NoData 2^24+3 and the valid 2^24+5 both round to the float32
+	// 2^24+4, which the exact values tell apart
+	wide := []float64{16777217, 16777217, 16777219, 16777221}
+	vals := make([]float32, len(wide))
+	for i, val := range wide {
+		vals[i] = float32(val)
+	}
+	mask := &MaskBuffer{Float32: []float32{1, 0.5, 1, 1000}}
+	in := &pb.GeoRPCGranule{ComputeMinMax: true, ClipLower: -math.MaxFloat32, ClipUpper: math.MaxFloat32}
+	r := &bandReducer{in: in, band: bandInfo{noData: float32(16777219), rawNoData: 16777219, scale: 1},
+		vals: vals, wide: wide, mask: mask, decileMask: mask}
+
+	r.setClipBounds(in.ClipLower, in.ClipUpper, 5)
+	r.accumulate(1, false)
+	row := make([]*pb.TimeSeries, 3)
+	r.reduce(row, 0, 4, 0)
+
+	if row[0].Value != 50331655.0/3 || row[0].Count != 3 {
+		t.Errorf("unexpected mean: %v", row[0])
+	}
+	if row[1].Value != 16777217 || row[2].Value != 16777221 {
+		t.Errorf("expected min 16777217 and max 16777221, got %v and %v", row[1].Value, row[2].Value)
+	}
+}
@@ -0,0 +1,1385 @@
+package gdalprocess
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+	pb "github.com/nci/gsky/worker/gdalservice"
+)
+
+// writeTestGrid writes the rows as an Arc/Info ASCII grid with unit
+// pixels and the lower left corner at the origin. The grid has no
+// projection, hence geometries are given in pixel coordinates with y
+// pointing up.
+func writeTestGrid(t *testing.T, rows [][]float32, nodata float32) string {
+	dir, err := ioutil.TempDir("", "gsky_drill_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "ncols %d\nnrows %d\nxllcorner 0\nyllcorner 0\ncellsize 1\nNODATA_value %g\n", len(rows[0]), len(rows), nodata)
+	for _, row := range rows {
+		for ic, val := range row {
+			if ic > 0 {
+				sb.WriteString(" ")
+			}
+			fmt.Fprintf(&sb, "%g", val)
+		}
+		sb.WriteString("\n")
+	}
+
+	path := filepath.Join(dir, "grid.asc")
+	if err := ioutil.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newTestGrid returns a grid of the given size filled with val.
+func newTestGrid(width, height int, val float32) [][]float32 {
+	rows := make([][]float32, height)
+	for iy := range rows {
+		rows[iy] = make([]float32, width)
+		for ix := range rows[iy] {
+			rows[iy][ix] = val
+		}
+	}
+	return rows
+}
+
+// newRampGrid returns a grid of the given size whose pixel in row iy
+// and column ix is iy*width+ix+offset, i.e. a ramp along the rows.
+func newRampGrid(width, height int, offset float32) [][]float32 {
+	rows := newTestGrid(width, height, 0)
+	for iy := range rows {
+		for ix := range rows[iy] {
+			rows[iy][ix] = float32(iy*width+ix) + offset
+		}
+	}
+	return rows
+}
+
+// drillTestGrid drills the grid with the GeoJSON geometry using the
+// options of in, without clipping unless requested.
+func drillTestGrid(t *testing.T, path string, geometry string, in *pb.GeoRPCGranule) *pb.Result {
+	in.Operation = "drill"
+	in.Path = path
+	in.Geometry = fmt.Sprintf(`{"type":"Feature","geometry":%s,"properties":{}}`, geometry)
+	if len(in.Bands) == 0 {
+		in.Bands = []int32{1}
+	}
+	if in.ClipLower == 0 && in.ClipUpper == 0 {
+		in.ClipLower = -math.MaxFloat32
+		in.ClipUpper = math.MaxFloat32
+	}
+
+	res := DrillDataset(context.Background(), in)
+	if res.Error != "OK" {
+		t.Fatalf("drill failed: %s", res.Error)
+	}
+	return res
+}
+
+// writeTestBands writes a VRT next to the grid of path whose nBands
+// bands are the grid offset by the index of the band.
+func writeTestBands(t *testing.T, path string, nBands int) string {
+	var sb strings.Builder
+	sb.WriteString(`<VRTDataset rasterXSize="10" rasterYSize="10">
+  <GeoTransform>0, 1, 0, 10, 0, -1</GeoTransform>
+`)
+	for ib := 0; ib < nBands; ib++ {
+		fmt.Fprintf(&sb, `  <VRTRasterBand dataType="Float32" band="%d">
+    <NoDataValue>-9999</NoDataValue>
+    <ComplexSource>
+      <SourceFilename relativeToVRT="1">%s</SourceFilename>
+      <SourceBand>1</SourceBand>
+      <ScaleOffset>%d</ScaleOffset>
+      <ScaleRatio>1</ScaleRatio>
+    </ComplexSource>
+  </VRTRasterBand>
+`, ib+1, filepath.Base(path), ib)
+	}
+	sb.WriteString("</VRTDataset>")
+
+	vrtPath := filepath.Join(filepath.Dir(path), "bands.vrt")
+	if err := ioutil.WriteFile(vrtPath, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return vrtPath
+}
+
+func TestDrillPolygonWithHole(t *testing.T) {
+	// The pixels inside the hole are set to an outlier value which
+	// would show up in both the mean and the top decile if leaked.
+	rows := newTestGrid(10, 10, 1)
+	for iy := 3; iy < 7; iy++ {
+		for ix := 3; ix < 7; ix++ {
+			rows[iy][ix] = 100
+		}
+	}
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The hole boundary runs through the middle of the pixels around
+	// the outliers, so these are touched by the geometry and included.
+	donut := `[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[2.5,2.5],[2.5,7.5],[7.5,7.5],[7.5,2.5],[2.5,2.5]]]`
+	for _, geometry := range []string{
+		`{"type":"Polygon","coordinates":` + donut + `}`,
+		`{"type":"MultiPolygon","coordinates":[` + donut + `]}`,
+	} {
+		res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{DrillDecileCount: 9})
+
+		if res.Shape[0] != 1 || res.Shape[1] != 10 {
+			t.Fatalf("unexpected result shape: %v", res.Shape)
+		}
+		mean := res.TimeSeries[0]
+		if mean.Value != 1 || mean.Count != 84 {
+			t.Errorf("%s: expected mean 1 over 84 pixels, got %v over %v", geometry, mean.Value, mean.Count)
+		}
+		for ic, decile := range res.TimeSeries[1:] {
+			if decile.Value != 1 {
+				t.Errorf("%s: decile %d includes hole pixels: %v", geometry, ic+1, decile.Value)
+			}
+		}
+	}
+}
+
+func TestDrillWindowAtCorners(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// Each square overlaps a 2x2 pixel corner of the grid
+	tests := []struct {
+		corner   string
+		geometry string
+		mean     float64
+	}{
+		{"top left", `[[[-5,8],[2,8],[2,15],[-5,15],[-5,8]]]`, 5.5},
+		{"top right", `[[[8,8],[15,8],[15,15],[8,15],[8,8]]]`, 13.5},
+		{"bottom left", `[[[-5,-5],[2,-5],[2,2],[-5,2],[-5,-5]]]`, 85.5},
+		{"bottom right", `[[[8,-5],[15,-5],[15,2],[8,2],[8,-5]]]`, 93.5},
+	}
+
+	for _, tc := range tests {
+		res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":`+tc.geometry+`}`, &pb.GeoRPCGranule{})
+		mean := res.TimeSeries[0]
+		if mean.Value != tc.mean || mean.Count != 4 {
+			t.Errorf("%s: expected mean %v over 4 pixels, got %v over %v", tc.corner, tc.mean, mean.Value, mean.Count)
+		}
+	}
+}
+
+func TestDrillFeatureCollection(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	in := &pb.GeoRPCGranule{
+		Operation: "drill",
+		Path:      path,
+		Geometry: `{"type":"FeatureCollection","features":[
+			{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[-5,8],[2,8],[2,15],[-5,15],[-5,8]]]},"properties":{}},
+			{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[8,-5],[15,-5],[15,2],[8,2],[8,-5]]]},"properties":{}}]}`,
+		Bands:     []int32{1},
+		ClipLower: -math.MaxFloat32,
+		ClipUpper: math.MaxFloat32,
+	}
+	res := DrillDataset(context.Background(), in)
+	if res.Error != "OK" {
+		t.Fatalf("drill failed: %s", res.Error)
+	}
+
+	if len(res.Shape) != 3 || res.Shape[0] != 2 || res.Shape[1] != 1 || res.Shape[2] != 1 {
+		t.Fatalf("unexpected result shape: %v", res.Shape)
+	}
+	for i, expected := range []float64{5.5, 93.5} {
+		if mean := res.TimeSeries[i]; mean.Value != expected || mean.Count != 4 {
+			t.Errorf("feature %d: expected mean %v over 4 pixels, got %v over %v", i, expected, mean.Value, mean.Count)
+		}
+	}
+}
+
+func TestDrillBufferedPoint(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The grid has no SRS, hence the buffer distance is in pixels and
+	// the circle touches the 3x3 pixels around the center of pixel 45.
+	res := drillTestGrid(t, path, `{"type":"Point","coordinates":[5.5,5.5]}`, &pb.GeoRPCGranule{BufferMeters: 1})
+	mean := res.TimeSeries[0]
+	if mean.Value != 45 || mean.Count != 9 {
+		t.Errorf("expected mean 45 over 9 pixels, got %v over %v", mean.Value, mean.Count)
+	}
+}
+
+func TestDrillSampledPoint(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The point falls within pixel 33 off its center
+	res := drillTestGrid(t, path, `{"type":"Point","coordinates":[3.75,6.25]}`, &pb.GeoRPCGranule{})
+	mean := res.TimeSeries[0]
+	if mean.Value != 33 || mean.Count != 1 {
+		t.Errorf("expected value 33 over 1 pixel, got %v over %v", mean.Value, mean.Count)
+	}
+}
+
+func TestDrillSampledLine(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The line runs from the center of pixel 0 to the center of pixel
+	// 44. Without segmenting it, only the pixels of its end points would
+	// be sampled rather than the 5 pixels of the diagonal it crosses.
+	res := drillTestGrid(t, path, `{"type":"LineString","coordinates":[[0.5,9.5],[4.5,5.5]]}`, &pb.GeoRPCGranule{})
+	mean := res.TimeSeries[0]
+	if mean.Value != 22 || mean.Count != 5 {
+		t.Errorf("expected mean 22 over 5 pixels, got %v over %v", mean.Value, mean.Count)
+	}
+}
+
+func TestDrillBilinearPoint(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The ramp is linear, hence interpolating it between the centers of
+	// pixels 33, 34, 43 and 44 gives the ramp at the point, 10*3.25+3.25.
+	res := drillTestGrid(t, path, `{"type":"Point","coordinates":[3.75,6.25]}`, &pb.GeoRPCGranule{Resampling: pb.Resampling_BILINEAR})
+	mean := res.TimeSeries[0]
+	if mean.Value != 35.75 || mean.Count != 1 {
+		t.Errorf("expected value 35.75 over 1 point, got %v over %v", mean.Value, mean.Count)
+	}
+}
+
+func TestDrillClippedCount(t *testing.T) {
+	rows := newTestGrid(10, 10, 1)
+	for ix := 0; ix < 4; ix++ {
+		rows[0][ix] = 100
+	}
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}`, &pb.GeoRPCGranule{ClipLower: -1, ClipUpper: 50})
+	mean := res.TimeSeries[0]
+	if mean.Value != 1 || mean.Count != 96 || mean.ClippedCount != 4 {
+		t.Errorf("expected mean 1 over 96 pixels with 4 clipped, got %v over %v with %v clipped", mean.Value, mean.Count, mean.ClippedCount)
+	}
+}
+
+func TestDrillInt32Precision(t *testing.T) {
+	// 2^24+1 isn't representable as float32, which rounds it to 2^24
+	dir, err := ioutil.TempDir("", "gsky_drill_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "grid.asc")
+	grid := "ncols 2\nnrows 2\nxllcorner 0\nyllcorner 0\ncellsize 1\nNODATA_value -9999\n16777217 16777217\n16777217 -9999\n"
+	if err := ioutil.WriteFile(path, []byte(grid), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, &pb.GeoRPCGranule{})
+	mean := res.TimeSeries[0]
+	if mean.Value != 16777217 || mean.Count != 3 {
+		t.Errorf("expected mean 16777217 over 3 pixels, got %v over %v", mean.Value, mean.Count)
+	}
+}
+
+func TestDrillInt32NoDataTolerance(t *testing.T) {
+	// As float32, 16777218 rounds to 16777216 and the NoData 16777219 to
+	// 16777220, which are further apart than the tolerance
+	dir, err := ioutil.TempDir("", "gsky_drill_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "grid.asc")
+	grid := "ncols 2\nnrows 2\nxllcorner 0\nyllcorner 0\ncellsize 1\nNODATA_value 16777219\n16777217 16777217\n16777217 16777218\n"
+	if err := ioutil.WriteFile(path, []byte(grid), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	in := &pb.GeoRPCGranule{NoDataTolerance: 1, ComputeStdDev: true, ComputeMinMax: true}
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, in)
+	if res.Shape[1] != 5 {
+		t.Fatalf("unexpected result shape: %v", res.Shape)
+	}
+	if mean := res.TimeSeries[0]; mean.Value != 16777217 || mean.Count != 3 {
+		t.Errorf("expected mean 16777217 over 3 pixels, got %v over %v", mean.Value, mean.Count)
+	}
+	if stdDev := res.TimeSeries[1]; stdDev.Value != 0 {
+		t.Errorf("expected zero standard deviation, got %v", stdDev.Value)
+	}
+	if min, max := res.TimeSeries[3], res.TimeSeries[4]; min.Value != 16777217 || max.Value != 16777217 {
+		t.Errorf("expected min and max 16777217, got %v and %v", min.Value, max.Value)
+	}
+}
+
+func TestDrillSum(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 2), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`, &pb.GeoRPCGranule{ComputeSum: true, SumByArea: true})
+	if res.Shape[1] != 2 {
+		t.Fatalf("unexpected result shape: %v", res.Shape)
+	}
+	if sum := res.TimeSeries[1]; sum.Value != 8 || sum.Count != 4 {
+		t.Errorf("expected sum 8 over 4 pixels, got %v over %v", sum.Value, sum.Count)
+	}
+	if res.PixelArea != 1 {
+		t.Errorf("expected unit pixel area, actual %v", res.PixelArea)
+	}
+}
+
+func TestDrillBandOutOfRange(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	in := &pb.GeoRPCGranule{
+		Operation: "drill",
+		Path:      path,
+		Geometry:  `{"type":"Feature","geometry":{"type":"Point","coordinates":[5.5,5.5]},"properties":{}}`,
+		Bands:     []int32{1, 2},
+	}
+	res := DrillDataset(context.Background(), in)
+	if !strings.Contains(res.Error, "band 2 out of range [1, 1]") {
+		t.Errorf("unexpected error: %s", res.Error)
+	}
+}
+
+func TestDrillReadFailure(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The grid is truncated after its first rows, hence reading the
+	// rows of the geometry fails rather than returning zeros
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if err := ioutil.WriteFile(path, []byte(strings.Join(lines[:8], "")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The GDAL error is reported by the concurrent readers too
+	for _, concurrency := range []int32{1, 2} {
+		in := &pb.GeoRPCGranule{
+			Operation:       "drill",
+			Path:            path,
+			Geometry:        `{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]},"properties":{}}`,
+			Bands:           []int32{1, 1},
+			BandStrides:     1,
+			ConcurrentReads: concurrency,
+		}
+		res := DrillDataset(context.Background(), in)
+		if !strings.Contains(res.Error, "RasterIO of bands [1] failed") || !strings.Contains(res.Error, "GDAL error") {
+			t.Errorf("%d readers: unexpected error: %s", concurrency, res.Error)
+		}
+	}
+}
+
+func TestDrillNoOverlap(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	for _, geometry := range []string{
+		`{"type":"Polygon","coordinates":[[[20,20],[30,20],[30,30],[20,30],[20,20]]]}`,
+		`{"type":"Point","coordinates":[25,25]}`,
+	} {
+		in := &pb.GeoRPCGranule{
+			Operation: "drill",
+			Path:      path,
+			Geometry:  `{"type":"Feature","geometry":` + geometry + `,"properties":{}}`,
+			Bands:     []int32{1},
+		}
+		res := DrillDataset(context.Background(), in)
+		if res.Error != "NO_OVERLAP" {
+			t.Fatalf("%s: expected NO_OVERLAP, got %s", geometry, res.Error)
+		}
+		if len(res.TimeSeries) != 1 || res.TimeSeries[0].Count != 0 {
+			t.Errorf("%s: expected a zero count row, got %v", geometry, res.TimeSeries)
+		}
+	}
+}
+
+func TestDrillMetadataOnly(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[2,2],[5,2],[5,4],[2,4],[2,2]]]}`, &pb.GeoRPCGranule{MetadataOnly: true})
+	w := res.Window
+	if w == nil || w.OffX != 2 || w.OffY != 6 || w.CountX != 3 || w.CountY != 2 || w.MaskedPixels != 6 {
+		t.Errorf("unexpected window: %v", w)
+	}
+	if len(res.TimeSeries) != 0 || res.Metrics.BytesRead != 0 {
+		t.Errorf("expected no data read, got %d rows and %d bytes", len(res.TimeSeries), res.Metrics.BytesRead)
+	}
+}
+
+func TestDrillHistogramFullRaster(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	rows[0][0] = -9999
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The whole raster is drilled, hence GDAL computes the histogram
+	in := &pb.GeoRPCGranule{HistogramBins: 10, HistogramMin: 0, HistogramMax: 99}
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}`, in)
+
+	expected := make([]int64, 10)
+	for val := 1; val < 99; val++ {
+		expected[int(float64(val)*(10.0/99))]++
+	}
+	// the maximum falls into the last bin
+	expected[9]++
+	if len(res.Histograms) != 1 {
+		t.Fatalf("expected a histogram, got %v", res.Histograms)
+	}
+	for i, count := range res.Histograms[0].Counts {
+		if count != expected[i] {
+			t.Errorf("expected counts %v, actual %v", expected, res.Histograms[0].Counts)
+			break
+		}
+	}
+}
+
+func TestDrillConcurrentReads(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The rows are merged in order regardless of the reader of a stride
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	bands := []int32{1, 1, 1, 1, 1, 1, 1}
+	expected := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{Bands: bands, BandStrides: 3, ComputeStdDev: true})
+	actual := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{Bands: bands, BandStrides: 3, ComputeStdDev: true, ConcurrentReads: 3})
+	if len(actual.TimeSeries) != len(expected.TimeSeries) {
+		t.Fatalf("expected %d rows, actual %d", len(expected.TimeSeries), len(actual.TimeSeries))
+	}
+	for i, ts := range actual.TimeSeries {
+		if ts.Value != expected.TimeSeries[i].Value || ts.Count != expected.TimeSeries[i].Count {
+			t.Errorf("row %d: expected %v, actual %v", i, expected.TimeSeries[i], ts)
+		}
+	}
+	if actual.Metrics.BytesRead != expected.Metrics.BytesRead {
+		t.Errorf("expected %d bytes read, actual %d", expected.Metrics.BytesRead, actual.Metrics.BytesRead)
+	}
+}
+
+func TestDrillDeterministicOrdering(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+	vrtPath := writeTestBands(t, path, 12)
+
+	// The serial drill is the reference the concurrent reads and
+	// reductions must reproduce bit for bit, hence run under -race
+	geometry := `{"type":"Polygon","coordinates":[[[1,1],[8,1],[8,8],[1,8],[1,1]]]}`
+	bands := []int32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	newRequest := func(concurrency int32) *pb.GeoRPCGranule {
+		return &pb.GeoRPCGranule{Bands: bands, DrillDecileCount: 9, ComputeStdDev: true, ConcurrentReads: concurrency, StatsWorkers: concurrency}
+	}
+	expected, err := proto.Marshal(&pb.Result{TimeSeries: drillTestGrid(t, vrtPath, geometry, newRequest(1)).TimeSeries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The runs vary the scheduling under -race, fewer of them in short mode
+	runs := 100
+	if testing.Short() {
+		runs = 5
+	}
+	for i := 0; i < runs; i++ {
+		actual, err := proto.Marshal(&pb.Result{TimeSeries: drillTestGrid(t, vrtPath, geometry, newRequest(4)).TimeSeries})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Fatalf("run %d: the time series differ from the ones of the serial drill", i)
+		}
+	}
+}
+
+func TestDrillStream(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	bands := []int32{1, 1, 1, 1, 1, 1, 1}
+	expected := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{Bands: bands, BandStrides: 3})
+
+	// The rows of every stride are streamed before the final result,
+	// which holds none of them
+	in := &pb.GeoRPCGranule{Operation: "drill", Path: path, Bands: bands, BandStrides: 3, ClipLower: -math.MaxFloat32, ClipUpper: math.MaxFloat32}
+	in.Geometry = fmt.Sprintf(`{"type":"Feature","geometry":%s,"properties":{}}`, geometry)
+	var streamed []*pb.TimeSeries
+	nParts := 0
+	res := DrillDatasetStream(context.Background(), in, func(part *pb.Result) error {
+		if int(part.Shape[0]*part.Shape[1]) != len(part.TimeSeries) {
+			t.Errorf("shape %v of %d rows", part.Shape, len(part.TimeSeries))
+		}
+		streamed = append(streamed, part.TimeSeries...)
+		nParts++
+		return nil
+	})
+	if res.Error != "OK" {
+		t.Fatalf("drill failed: %s", res.Error)
+	}
+	if nParts != 3 || len(res.TimeSeries) != 0 || res.Metrics == nil {
+		t.Errorf("expected 3 partial results and a final one without rows, actual %d partial, %d final rows", nParts, len(res.TimeSeries))
+	}
+	if res.Shape[0] != expected.Shape[0] || res.Shape[1] != expected.Shape[1] {
+		t.Errorf("expected shape %v, actual %v", expected.Shape, res.Shape)
+	}
+	if len(streamed) != len(expected.TimeSeries) {
+		t.Fatalf("expected %d rows, actual %d", len(expected.TimeSeries), len(streamed))
+	}
+	for i, ts := range streamed {
+		if ts.Value != expected.TimeSeries[i].Value || ts.Count != expected.TimeSeries[i].Count {
+			t.Errorf("row %d: expected %v, actual %v", i, expected.TimeSeries[i], ts)
+		}
+	}
+}
+
+func TestDrillWindowGeoTransform(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`, &pb.GeoRPCGranule{})
+	// The grid has its top left corner at (0, 10) and unit pixels
+	expected := []float64{float64(res.Window.OffX), 1, 0, 10 - float64(res.Window.OffY), 0, -1}
+	geot := res.Raster.GeoTransform
+	if len(geot) != 6 {
+		t.Fatalf("expected a geotransform, got %v", geot)
+	}
+	for i := range expected {
+		if geot[i] != expected[i] {
+			t.Errorf("expected geotransform %v, actual %v", expected, geot)
+			break
+		}
+	}
+}
+
+func TestDrillMaskType(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The pixels are drilled alike whatever the type and burn value of
+	// the mask
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	expected := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{})
+	for _, in := range []*pb.GeoRPCGranule{
+		{MaskBurnValue: 1},
+		{MaskType: pb.MaskType_MASK_INT16, MaskBurnValue: 1000},
+		{MaskType: pb.MaskType_MASK_FLOAT32, MaskBurnValue: 0.25},
+	} {
+		actual := drillTestGrid(t, path, geometry, in)
+		if mean := actual.TimeSeries[0]; mean.Value != expected.TimeSeries[0].Value || mean.Count != 16 {
+			t.Errorf("%v %v: expected a mean of %v over 16 pixels, got %v over %v", in.MaskType, in.MaskBurnValue, expected.TimeSeries[0].Value, mean.Value, mean.Count)
+		}
+	}
+
+	in := &pb.GeoRPCGranule{
+		Operation:     "drill",
+		Path:          path,
+		Geometry:      fmt.Sprintf(`{"type":"Feature","geometry":%s,"properties":{}}`, geometry),
+		Bands:         []int32{1},
+		MaskBurnValue: 1000,
+	}
+	res := DrillDataset(context.Background(), in)
+	if !strings.Contains(res.Error, "mask burn value 1000 out of the Byte range") {
+		t.Errorf("unexpected error: %s", res.Error)
+	}
+}
+
+func TestDrillPixelCenterMask(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The polygon touches 3x3 pixels but only contains the center of one
+	geometry := `{"type":"Polygon","coordinates":[[[2.6,2.6],[4.4,2.6],[4.4,4.4],[2.6,4.4],[2.6,2.6]]]}`
+	tests := []struct {
+		pixelCenter bool
+		expected    int32
+	}{
+		{false, 9},
+		{true, 1},
+	}
+	for _, tc := range tests {
+		res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{PixelCenterMask: tc.pixelCenter})
+		if count := res.TimeSeries[0].Count; count != tc.expected {
+			t.Errorf("pixel center %v: expected %d pixels, actual %d", tc.pixelCenter, tc.expected, count)
+		}
+	}
+}
+
+func TestDrillValidityMask(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The pixels of the third column are flagged as invalid
+	maskRows := newTestGrid(10, 10, 1)
+	for iy := range maskRows {
+		maskRows[iy][2] = 0
+	}
+	maskPath := writeTestGrid(t, maskRows, -9999)
+	defer os.RemoveAll(filepath.Dir(maskPath))
+
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`, &pb.GeoRPCGranule{MaskPath: maskPath})
+	if count := res.TimeSeries[0].Count; count != 12 {
+		t.Errorf("expected 12 valid pixels, actual %d", count)
+	}
+}
+
+func TestDrillMaskRange(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The mask band holds the column index, e.g. an elevation
+	maskRows := newTestGrid(10, 10, 0)
+	for iy := range maskRows {
+		for ix := range maskRows[iy] {
+			maskRows[iy][ix] = float32(ix)
+		}
+	}
+	maskPath := writeTestGrid(t, maskRows, -9999)
+	defer os.RemoveAll(filepath.Dir(maskPath))
+
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{MaskPath: maskPath, MaskMin: 3, MaskMax: 4})
+	if count := res.TimeSeries[0].Count; count != 8 {
+		t.Errorf("expected 8 pixels within the mask range, actual %d", count)
+	}
+
+	in := &pb.GeoRPCGranule{
+		Operation: "drill",
+		Path:      path,
+		Geometry:  fmt.Sprintf(`{"type":"Feature","geometry":%s,"properties":{}}`, geometry),
+		Bands:     []int32{1},
+		MaskPath:  maskPath,
+		MaskMin:   4,
+		MaskMax:   3,
+	}
+	res = DrillDataset(context.Background(), in)
+	if !strings.Contains(res.Error, "invalid mask range") {
+		t.Errorf("unexpected error: %s", res.Error)
+	}
+}
+
+func TestDrillMemoryCap(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	in := &pb.GeoRPCGranule{
+		Operation:      "drill",
+		Path:           path,
+		Geometry:       `{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]},"properties":{}}`,
+		Bands:          []int32{1},
+		MaxDrillMemory: 10,
+	}
+	res := DrillDataset(context.Background(), in)
+	if !strings.Contains(res.Error, "exceeding the cap of 10 bytes") {
+		t.Errorf("unexpected error: %s", res.Error)
+	}
+}
+
+func TestDrillMaxBands(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	bands := []int32{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+	in := &pb.GeoRPCGranule{
+		Operation: "drill",
+		Path:      path,
+		Geometry:  fmt.Sprintf(`{"type":"Feature","geometry":%s,"properties":{}}`, geometry),
+		Bands:     bands,
+		MaxBands:  4,
+	}
+	res := DrillDataset(context.Background(), in)
+	if !strings.Contains(res.Error, "10 bands to read, exceeding the cap of 4 bands: set bandStrides to at least 5") {
+		t.Errorf("unexpected error: %s", res.Error)
+	}
+
+	res = drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{Bands: bands, BandStrides: 5, MaxBands: 4})
+	if len(res.TimeSeries) != len(bands)*int(res.Shape[1]) {
+		t.Errorf("expected %d bands, actual shape %v", len(bands), res.Shape)
+	}
+
+	for _, tc := range []struct{ nBands, strides, expected int }{
+		{10, 1, 10}, {10, 2, 10}, {10, 3, 7}, {10, 5, 4}, {11, 5, 5}, {3, 5, 2},
+	} {
+		if actual := stridedBandCount(tc.nBands, tc.strides); actual != tc.expected {
+			t.Errorf("%d bands with strides %d: expected %d read, actual %d", tc.nBands, tc.strides, tc.expected, actual)
+		}
+	}
+}
+
+func TestDrillNoDataFraction(t *testing.T) {
+	rows := newTestGrid(10, 10, 1)
+	// one of the 4 pixels of the polygon is NoData
+	rows[7][2] = -9999
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`, &pb.GeoRPCGranule{ComputeNoDataFraction: true})
+	if fraction := res.TimeSeries[1]; fraction.Value != 0.25 || fraction.Count != 4 {
+		t.Errorf("expected a NoData fraction of 0.25 over 4 pixels, got %v over %v", fraction.Value, fraction.Count)
+	}
+}
+
+func TestDrillSubdatasetNotFound(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	in := &pb.GeoRPCGranule{
+		Operation:  "drill",
+		Path:       path,
+		Geometry:   `{"type":"Feature","geometry":{"type":"Point","coordinates":[5.5,5.5]},"properties":{}}`,
+		Bands:      []int32{1},
+		Subdataset: "phot_veg",
+	}
+	res := DrillDataset(context.Background(), in)
+	if !strings.Contains(res.Error, "has no subdatasets") {
+		t.Errorf("unexpected error: %s", res.Error)
+	}
+}
+
+func TestDrillRATLabels(t *testing.T) {
+	rows := newTestGrid(10, 10, 1)
+	rows[4][2] = 2
+	rows[5][3] = 3
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// Class 3 is missing from the table, hence keeps its code
+	rat := `<PAMDataset>
+  <PAMRasterBand band="1">
+    <GDALRasterAttributeTable>
+      <FieldDefn index="0"><Name>Value</Name><Type>0</Type><Usage>5</Usage></FieldDefn>
+      <FieldDefn index="1"><Name>Class</Name><Type>2</Type><Usage>2</Usage></FieldDefn>
+      <Row index="0"><F>1</F><F>Forest</F></Row>
+      <Row index="1"><F>2</F><F>Water</F></Row>
+    </GDALRasterAttributeTable>
+  </PAMRasterBand>
+</PAMDataset>`
+	if err := ioutil.WriteFile(path+".aux.xml", []byte(rat), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{Categorical: true, UseRAT: true})
+	cf := res.ClassFractions[0]
+	if len(cf.Labels) != len(cf.Classes) {
+		t.Fatalf("expected %d labels, actual %v", len(cf.Classes), cf.Labels)
+	}
+	expected := map[int32]string{1: "Forest", 2: "Water", 3: "3"}
+	for i, class := range cf.Classes {
+		if cf.Labels[i] != expected[class] {
+			t.Errorf("class %d: expected label %q, actual %q", class, expected[class], cf.Labels[i])
+		}
+	}
+
+	res = drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{Categorical: true})
+	if labels := res.ClassFractions[0].Labels; len(labels) != 0 {
+		t.Errorf("expected no labels without UseRAT, actual %v", labels)
+	}
+}
+
+func TestDrillCacheMetrics(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`, &pb.GeoRPCGranule{})
+	if res.Metrics.CacheMax <= 0 || res.Metrics.CacheUsed < 0 || res.Metrics.CacheUsed > res.Metrics.CacheMax {
+		t.Errorf("unexpected cache usage %d of %d bytes", res.Metrics.CacheUsed, res.Metrics.CacheMax)
+	}
+}
+
+func TestDrillTimingMetrics(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	in := &pb.GeoRPCGranule{Bands: []int32{1, 1, 1, 1, 1, 1, 1}, BandStrides: 3, ConcurrentReads: 1}
+	res := drillTestGrid(t, path, `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`, in)
+	m := res.Metrics
+	if m.RasterIOTime <= 0 || m.ReduceTime <= 0 {
+		t.Errorf("expected the RasterIO and the reduction times, actual %d and %d ns", m.RasterIOTime, m.ReduceTime)
+	}
+	if m.RasterIOTime+m.ReduceTime > m.WallTime {
+		t.Errorf("%d ns of RasterIO and %d ns of reduction exceed the wall time of %d ns", m.RasterIOTime, m.ReduceTime, m.WallTime)
+	}
+}
+
+func TestDrillMinValidPixels(t *testing.T) {
+	rows := newTestGrid(10, 10, 1)
+	// 3 of the 4 pixels of the polygon are NoData
+	rows[6][2] = -9999
+	rows[6][3] = -9999
+	rows[7][2] = -9999
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{MinValidPixels: 2})
+	if mean := res.TimeSeries[0]; mean.Value != -9999 || mean.Count != 0 {
+		t.Errorf("expected the NoData sentinel, got %v over %v", mean.Value, mean.Count)
+	}
+	res = drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{MinValidFraction: 0.25})
+	if mean := res.TimeSeries[0]; mean.Value != 1 || mean.Count != 1 {
+		t.Errorf("expected a mean of 1 over 1 pixel, got %v over %v", mean.Value, mean.Count)
+	}
+}
+
+func TestDrillZones(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The first and last features share a zone
+	in := &pb.GeoRPCGranule{
+		Operation: "drill",
+		Path:      path,
+		Geometry: `{"type":"FeatureCollection","features":[
+			{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,8],[2,8],[2,10],[0,10],[0,8]]]},"properties":{}},
+			{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[8,0],[10,0],[10,2],[8,2],[8,0]]]},"properties":{}},
+			{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[4,4],[6,4],[6,6],[4,6],[4,4]]]},"properties":{}}]}`,
+		Bands:     []int32{1},
+		ClipLower: -math.MaxFloat32,
+		ClipUpper: math.MaxFloat32,
+		ZoneIDs:   []int32{7, 3, 7},
+	}
+	res := DrillDataset(context.Background(), in)
+	if res.Error != "OK" {
+		t.Fatalf("drill failed: %s", res.Error)
+	}
+
+	if len(res.Shape) != 3 || res.Shape[0] != 2 || res.Shape[1] != 1 || res.Shape[2] != 1 {
+		t.Fatalf("unexpected result shape: %v", res.Shape)
+	}
+	if len(res.ZoneIDs) != 2 || res.ZoneIDs[0] != 7 || res.ZoneIDs[1] != 3 {
+		t.Errorf("expected zone IDs [7 3], got %v", res.ZoneIDs)
+	}
+	expected := []struct {
+		mean  float64
+		count int32
+	}{{27.5, 8}, {93.5, 4}}
+	for i, exp := range expected {
+		if mean := res.TimeSeries[i]; mean.Value != exp.mean || mean.Count != exp.count {
+			t.Errorf("zone %d: expected mean %v over %v pixels, got %v over %v", i, exp.mean, exp.count, mean.Value, mean.Count)
+		}
+	}
+
+	in.ZoneIDs = in.ZoneIDs[:2]
+	if res := DrillDataset(context.Background(), in); res.Error == "OK" {
+		t.Errorf("expected an error for zone IDs not aligned to the features")
+	}
+}
+
+func TestDrillBandStrides(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// Band ib is the grid offset by ib, hence the linear interpolation
+	// of the skipped bands is exact
+	const nBands = 7
+	vrtPath := writeTestBands(t, path, nBands)
+
+	bands := make([]int32, nBands)
+	bandTimes := make([]int64, nBands)
+	for ib := range bands {
+		bands[ib] = int32(ib + 1)
+		bandTimes[ib] = int64(ib * 86400)
+	}
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	for _, strides := range []int32{1, 2, 3, 5} {
+		res := drillTestGrid(t, vrtPath, geometry, &pb.GeoRPCGranule{Bands: bands, BandTimes: bandTimes, BandStrides: strides, DrillDecileCount: 9})
+		nCols := int(res.Shape[1])
+		if len(res.TimeSeries) != nBands*nCols || res.Shape[0] != nBands {
+			t.Errorf("strides %d: expected %d rows of %d columns, actual %d values of shape %v", strides, nBands, nCols, len(res.TimeSeries), res.Shape)
+			continue
+		}
+		for ib := 0; ib < nBands; ib++ {
+			mean := res.TimeSeries[ib*nCols]
+			if math.Abs(mean.Value-float64(ib+1)) > 1e-6 || mean.Time != bandTimes[ib] {
+				t.Errorf("strides %d, band %d: expected %d at %d, actual %v at %d", strides, ib, ib+1, bandTimes[ib], mean.Value, mean.Time)
+			}
+		}
+	}
+}
+
+func TestDrillUnit(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	vrt := `<VRTDataset rasterXSize="10" rasterYSize="10">
+  <GeoTransform>0, 1, 0, 10, 0, -1</GeoTransform>
+  <VRTRasterBand dataType="Float32" band="1">
+    <NoDataValue>-9999</NoDataValue>
+    <UnitType>K</UnitType>
+    <Offset>%s</Offset>
+    <Scale>%s</Scale>
+    <SimpleSource>
+      <SourceFilename relativeToVRT="1">grid.asc</SourceFilename>
+      <SourceBand>1</SourceBand>
+    </SimpleSource>
+  </VRTRasterBand>
+</VRTDataset>`
+	writeVRT := func(name, offset, scale string) string {
+		vrtPath := filepath.Join(filepath.Dir(path), name)
+		if err := ioutil.WriteFile(vrtPath, []byte(fmt.Sprintf(vrt, offset, scale)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return vrtPath
+	}
+
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`
+	res := drillTestGrid(t, writeVRT("unscaled.vrt", "0", "1"), geometry, &pb.GeoRPCGranule{})
+	if res.Unit != "K" {
+		t.Errorf("expected unit K, actual %q", res.Unit)
+	}
+
+	// The raw values of scaled bands aren't in the unit of the band
+	scaledPath := writeVRT("scaled.vrt", "273", "2")
+	res = drillTestGrid(t, scaledPath, geometry, &pb.GeoRPCGranule{})
+	if res.Unit != "" {
+		t.Errorf("expected no unit for the raw values, actual %q", res.Unit)
+	}
+	res = drillTestGrid(t, scaledPath, geometry, &pb.GeoRPCGranule{ApplyScaleOffset: true})
+	if res.Unit != "K" || res.TimeSeries[0].Value != 275 {
+		t.Errorf("expected 275 K, actual %v %q", res.TimeSeries[0].Value, res.Unit)
+	}
+}
+
+func TestDrillWarpOnRead(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+	prj := `GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563]],PRIMEM["Greenwich",0],UNIT["degree",0.0174532925199433]]`
+	if err := ioutil.WriteFile(strings.TrimSuffix(path, ".asc")+".prj", []byte(prj), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Halving the resolution quadruples the pixels within the polygon
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{WarpSRS: "EPSG:4326", WarpResolution: []float64{0.5}})
+	if mean := res.TimeSeries[0]; mean.Value != 1 || mean.Count != 16 {
+		t.Errorf("expected a mean of 1 over 16 pixels, got %v over %v", mean.Value, mean.Count)
+	}
+	if res.Resolution[0] != 0.5 {
+		t.Errorf("expected the target resolution 0.5, got %v", res.Resolution[0])
+	}
+}
+
+func TestDrillRotatedGeoTransform(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The grid rotated by 90 degrees, i.e. x = row and y = column
+	vrt := `<VRTDataset rasterXSize="10" rasterYSize="10">
+  <GeoTransform>0, 0, 1, 0, 1, 0</GeoTransform>
+  <VRTRasterBand dataType="Float32" band="1">
+    <NoDataValue>-9999</NoDataValue>
+    <SimpleSource>
+      <SourceFilename relativeToVRT="1">grid.asc</SourceFilename>
+      <SourceBand>1</SourceBand>
+    </SimpleSource>
+  </VRTRasterBand>
+</VRTDataset>`
+	vrtPath := filepath.Join(filepath.Dir(path), "rotated.vrt")
+	if err := ioutil.WriteFile(vrtPath, []byte(vrt), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows 2 and 3 of columns 6 and 7
+	geometry := `{"type":"Polygon","coordinates":[[[2,6],[4,6],[4,8],[2,8],[2,6]]]}`
+	res := drillTestGrid(t, vrtPath, geometry, &pb.GeoRPCGranule{})
+	if mean := res.TimeSeries[0]; mean.Value != 31.5 || mean.Count != 4 {
+		t.Errorf("expected a mean of 31.5 over 4 pixels, got %v over %v", mean.Value, mean.Count)
+	}
+}
+
+func TestDrillDataTypes(t *testing.T) {
+	rows := newRampGrid(10, 10, -50)
+	path := writeTestGrid(t, rows, -99)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// Rows 2 and 3 of columns 6 and 7, signed values included
+	geometry := `{"type":"Polygon","coordinates":[[[6,6],[8,6],[8,8],[6,8],[6,6]]]}`
+	for _, dataType := range []string{"Int8", "Int64", "UInt64"} {
+		vrt := fmt.Sprintf(`<VRTDataset rasterXSize="10" rasterYSize="10">
+  <GeoTransform>0, 1, 0, 10, 0, -1</GeoTransform>
+  <VRTRasterBand dataType="%s" band="1">
+    <NoDataValue>-99</NoDataValue>
+    <SimpleSource>
+      <SourceFilename relativeToVRT="1">grid.asc</SourceFilename>
+      <SourceBand>1</SourceBand>
+    </SimpleSource>
+  </VRTRasterBand>
+</VRTDataset>`, dataType)
+		vrtPath := filepath.Join(filepath.Dir(path), dataType+".vrt")
+		if err := ioutil.WriteFile(vrtPath, []byte(vrt), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := -18.5
+		if dataType == "UInt64" {
+			// The negative values are clamped to zero
+			expected = 0
+		}
+		res := drillTestGrid(t, vrtPath, geometry, &pb.GeoRPCGranule{})
+		if mean := res.TimeSeries[0]; mean.Value != expected || mean.Count != 4 {
+			t.Errorf("%s: expected a mean of %v over 4 pixels, got %v over %v", dataType, expected, mean.Value, mean.Count)
+		}
+	}
+}
+
+func TestDrillInt64Precision(t *testing.T) {
+	// 2^24+1 and 2^24+3 aren't representable as float32
+	dir, err := ioutil.TempDir("", "gsky_drill_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	grid := "ncols 2\nnrows 2\nxllcorner 0\nyllcorner 0\ncellsize 1\nNODATA_value -9999\n16777217 16777219\n16777217 16777219\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "grid.asc"), []byte(grid), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vrt := `<VRTDataset rasterXSize="2" rasterYSize="2">
+  <GeoTransform>0, 1, 0, 2, 0, -1</GeoTransform>
+  <VRTRasterBand dataType="Int64" band="1">
+    <NoDataValue>-9999</NoDataValue>
+    <SimpleSource>
+      <SourceFilename relativeToVRT="1">grid.asc</SourceFilename>
+      <SourceBand>1</SourceBand>
+    </SimpleSource>
+  </VRTRasterBand>
+</VRTDataset>`
+	vrtPath := filepath.Join(dir, "int64.vrt")
+	if err := ioutil.WriteFile(vrtPath, []byte(vrt), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	in := &pb.GeoRPCGranule{ComputeMinMax: true}
+	res := drillTestGrid(t, vrtPath, `{"type":"Polygon","coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}`, in)
+	if mean := res.TimeSeries[0]; mean.Value != 16777218 || mean.Count != 4 {
+		t.Errorf("expected mean 16777218 over 4 pixels, got %v over %v", mean.Value, mean.Count)
+	}
+	if min, max := res.TimeSeries[1], res.TimeSeries[2]; min.Value != 16777217 || max.Value != 16777219 {
+		t.Errorf("expected min 16777217 and max 16777219, got %v and %v", min.Value, max.Value)
+	}
+}
+
+func TestDrillSigmaClip(t *testing.T) {
+	// A cloud pixel among the pixels of the geometry
+	rows := newTestGrid(10, 10, 10)
+	rows[5][3] = 1000
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{SigmaClip: 3})
+	mean := res.TimeSeries[0]
+	if mean.Value != 10 || mean.Count != 15 || mean.SigmaRejectedCount != 1 || mean.ValidCount != 16 {
+		t.Errorf("expected a mean of 10 over 15 of 16 pixels with 1 rejected, got %v", mean)
+	}
+}
+
+func TestDrillMaskCache(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The second drill of the geometry reuses the mask of the first
+	geometry := `{"type":"Polygon","coordinates":[[[1.2,1.3],[6.7,1.1],[5.9,7.4],[1.2,1.3]]]}`
+	first := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{})
+	second := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{})
+	if first.Metrics.MaskCacheHits != 0 || second.Metrics.MaskCacheHits != 1 {
+		t.Errorf("expected a miss then a hit, got %d and %d", first.Metrics.MaskCacheHits, second.Metrics.MaskCacheHits)
+	}
+	if first.TimeSeries[0].Value != second.TimeSeries[0].Value || first.TimeSeries[0].Count != second.TimeSeries[0].Count {
+		t.Errorf("expected the same mean, got %v and %v", first.TimeSeries[0], second.TimeSeries[0])
+	}
+
+	// Masks of other options aren't shared
+	centers := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{PixelCenterMask: true})
+	if centers.Metrics.MaskCacheHits != 0 || centers.TimeSeries[0].Count >= first.TimeSeries[0].Count {
+		t.Errorf("expected a miss with fewer pixels, got %d hits over %d pixels", centers.Metrics.MaskCacheHits, centers.TimeSeries[0].Count)
+	}
+}
+
+func TestDrillGeometryArea(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// Without SRS, the area is only known in the units of the grid
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{})
+	if res.GeometryArea != 4 || res.GeometryAreaM2 != 0 || res.PixelAreaM2 != 0 {
+		t.Errorf("expected an area of 4 without square meters, got %v, %v m2 and %v m2 pixels", res.GeometryArea, res.GeometryAreaM2, res.PixelAreaM2)
+	}
+
+	// 2x2 degrees near the equator are about 4.9e10 m2
+	prj := `GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563]],PRIMEM["Greenwich",0],UNIT["degree",0.0174532925199433]]`
+	if err := ioutil.WriteFile(strings.TrimSuffix(path, ".asc")+".prj", []byte(prj), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res = drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{})
+	if res.GeometryArea != 4 || res.GeometryAreaM2 < 4.85e10 || res.GeometryAreaM2 > 5e10 {
+		t.Errorf("expected an area of 4 square degrees and about 4.9e10 m2, got %v and %v m2", res.GeometryArea, res.GeometryAreaM2)
+	}
+	if math.Abs(res.PixelAreaM2-res.GeometryAreaM2/4) > 1 {
+		t.Errorf("expected a pixel area of %v m2, got %v m2", res.GeometryAreaM2/4, res.PixelAreaM2)
+	}
+}
+
+func TestDrillOverviewLevel(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	dir := filepath.Dir(path)
+	defer os.RemoveAll(dir)
+
+	// The overview holds other values to tell which level is read
+	ovrPath := writeTestGrid(t, newTestGrid(5, 5, 2), -9999)
+	defer os.RemoveAll(filepath.Dir(ovrPath))
+
+	vrt := fmt.Sprintf(`<VRTDataset rasterXSize="10" rasterYSize="10">
+  <GeoTransform>0, 1, 0, 10, 0, -1</GeoTransform>
+  <VRTRasterBand dataType="Float32" band="1">
+    <NoDataValue>-9999</NoDataValue>
+    <SimpleSource>
+      <SourceFilename relativeToVRT="1">grid.asc</SourceFilename>
+      <SourceBand>1</SourceBand>
+    </SimpleSource>
+    <Overview>
+      <SourceFilename>%s</SourceFilename>
+      <SourceBand>1</SourceBand>
+    </Overview>
+  </VRTRasterBand>
+</VRTDataset>`, ovrPath)
+	vrtPath := filepath.Join(dir, "overviews.vrt")
+	if err := ioutil.WriteFile(vrtPath, []byte(vrt), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	res := drillTestGrid(t, vrtPath, geometry, &pb.GeoRPCGranule{OverviewLevel: 1})
+	if mean := res.TimeSeries[0]; mean.Value != 2 || mean.Count != 4 {
+		t.Errorf("expected a mean of 2 over 4 overview pixels, got %v over %v", mean.Value, mean.Count)
+	}
+	if res.OverviewLevel != 1 || res.Resolution[0] != 2 {
+		t.Errorf("expected overview level 1 at resolution 2, got %v at %v", res.OverviewLevel, res.Resolution)
+	}
+
+	in := &pb.GeoRPCGranule{Operation: "drill", Path: vrtPath, Bands: []int32{1}, OverviewLevel: 2}
+	in.Geometry = fmt.Sprintf(`{"type":"Feature","geometry":%s,"properties":{}}`, geometry)
+	if res := DrillDataset(context.Background(), in); res.Error == "OK" {
+		t.Errorf("expected an error for a missing overview level")
+	}
+}
+
+func TestDrillInteriorDeciles(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The polygon touches 4x4 pixels but only contains the centers of
+	// the 2x2 pixels of rows 5 and 6 and columns 3 and 4
+	geometry := `{"type":"Polygon","coordinates":[[[2.5,2.5],[5.5,2.5],[5.5,5.5],[2.5,5.5],[2.5,2.5]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{Percentiles: []float64{0}, InteriorDeciles: true})
+	if mean := res.TimeSeries[0]; mean.Value != 58.5 || mean.Count != 16 {
+		t.Errorf("expected a mean of 58.5 over 16 pixels, got %v over %v", mean.Value, mean.Count)
+	}
+	if min := res.TimeSeries[1]; min.Value != 53 {
+		t.Errorf("expected the minimum of the interior pixels 53, got %v", min.Value)
+	}
+}
+
+func TestDrillApproxQuantiles(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	geometry := `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]]]}`
+	exact := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{DrillDecileCount: 9, ComputeMedian: true})
+	approx := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{DrillDecileCount: 9, ComputeMedian: true, ApproxQuantiles: true})
+	if len(approx.TimeSeries) != len(exact.TimeSeries) {
+		t.Fatalf("expected %d columns, actual %d", len(exact.TimeSeries), len(approx.TimeSeries))
+	}
+	// The deciles and the median of the 100 pixels of values 0 to 99
+	for ic := 1; ic < len(exact.TimeSeries); ic++ {
+		if math.Abs(approx.TimeSeries[ic].Value-exact.TimeSeries[ic].Value) > 5 {
+			t.Errorf("column %d: expected about %v, actual %v", ic, exact.TimeSeries[ic].Value, approx.TimeSeries[ic].Value)
+		}
+	}
+}
+
+func TestDrillBufferedSelfIntersectingPolygon(t *testing.T) {
+	path := writeTestGrid(t, newTestGrid(10, 10, 1), -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The bow tie is fixed by the zero-distance buffer before masking
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,6],[6,2],[2,6],[2,2]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{})
+	if mean := res.TimeSeries[0]; mean.Value != 1 || mean.Count == 0 {
+		t.Errorf("expected a mean of 1 over the pixels of the bow tie, got %v over %v", mean.Value, mean.Count)
+	}
+}
+
+func TestFixTopology(t *testing.T) {
+	// The ring collapsed onto a line buffers to an empty polygon, hence
+	// the polygon is cloned as it is
+	g, err := createGeometryFromWkt("POLYGON ((2 2,6 6,4 4,2 2))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed := fixTopology(g, defaultBufferSegments)
+	if fixed == nil {
+		t.Fatal("expected a clone of the geometry, got nil")
+	}
+
+	var vertices [][2]float64
+	forEachVertex(fixed, func(x, y float64) {
+		vertices = append(vertices, [2]float64{x, y})
+	})
+	expected := [][2]float64{{2, 2}, {6, 6}, {4, 4}, {2, 2}}
+	if fmt.Sprint(vertices) != fmt.Sprint(expected) {
+		t.Errorf("expected the vertices %v, got %v", expected, vertices)
+	}
+}
+
+func TestDrillLongitudeConvention(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The grid spans the longitudes 175 to 185 in the 0..360 convention
+	vrt := `<VRTDataset rasterXSize="10" rasterYSize="10">
+  <SRS>EPSG:4326</SRS>
+  <GeoTransform>175, 1, 0, 10, 0, -1</GeoTransform>
+  <VRTRasterBand dataType="Float32" band="1">
+    <NoDataValue>-9999</NoDataValue>
+    <SimpleSource>
+      <SourceFilename relativeToVRT="1">grid.asc</SourceFilename>
+      <SourceBand>1</SourceBand>
+    </SimpleSource>
+  </VRTRasterBand>
+</VRTDataset>`
+	vrtPath := filepath.Join(filepath.Dir(path), "pacific.vrt")
+	if err := ioutil.WriteFile(vrtPath, []byte(vrt), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The longitudes -178 to -176 are 182 to 184, i.e. columns 7 and 8
+	geometry := `{"type":"Polygon","coordinates":[[[-178,2],[-176,2],[-176,4],[-178,4],[-178,2]]]}`
+	res := drillTestGrid(t, vrtPath, geometry, &pb.GeoRPCGranule{})
+	if mean := res.TimeSeries[0]; mean.Value != 72.5 || mean.Count != 4 {
+		t.Errorf("expected a mean of 72.5 over 4 pixels, got %v over %v", mean.Value, mean.Count)
+	}
+}
+
+func TestDrillAntimeridian(t *testing.T) {
+	// Unless split at the antimeridian, the polygon from 178 to -178
+	// would cover the longitudes -178 to 178 instead.
+	geometry := `{"type":"Polygon","coordinates":[[[178,2],[-178,2],[-178,6],[178,6],[178,2]]]}`
+	for _, tc := range []struct {
+		name     string
+		width    int
+		geot     string
+		window   pb.Window
+		count    int32
+		expected float64
+	}{
+		// The grid spans the longitudes 175 to 185, the polygon
+		// covers columns 3 to 6 of rows 4 to 7.
+		{"pacific", 10, "175, 1, 0, 10, 0, -1", pb.Window{OffX: 3, OffY: 4, CountX: 4, CountY: 4}, 16, 59.5},
+		// The grid spans the longitudes -180 to 180 with 2 degree
+		// pixels, the polygon covers the first and last columns of
+		// rows 2 and 3, hence the window spans the whole rows.
+		{"global", 180, "-180, 2, 0, 10, 0, -2", pb.Window{OffX: 0, OffY: 2, CountX: 180, CountY: 2}, 4, 539.5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeTestGrid(t, newRampGrid(tc.width, 10, 0), -9999)
+			defer os.RemoveAll(filepath.Dir(path))
+
+			vrt := fmt.Sprintf(`<VRTDataset rasterXSize="%d" rasterYSize="10">
+  <SRS>EPSG:4326</SRS>
+  <GeoTransform>%s</GeoTransform>
+  <VRTRasterBand dataType="Float32" band="1">
+    <NoDataValue>-9999</NoDataValue>
+    <SimpleSource>
+      <SourceFilename relativeToVRT="1">grid.asc</SourceFilename>
+      <SourceBand>1</SourceBand>
+    </SimpleSource>
+  </VRTRasterBand>
+</VRTDataset>`, tc.width, tc.geot)
+			vrtPath := filepath.Join(filepath.Dir(path), "antimeridian.vrt")
+			if err := ioutil.WriteFile(vrtPath, []byte(vrt), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			res := drillTestGrid(t, vrtPath, geometry, &pb.GeoRPCGranule{})
+			w := res.Window
+			if w.OffX != tc.window.OffX || w.OffY != tc.window.OffY || w.CountX != tc.window.CountX || w.CountY != tc.window.CountY {
+				t.Errorf("expected the window %d,%d %dx%d, got %d,%d %dx%d", tc.window.OffX, tc.window.OffY, tc.window.CountX, tc.window.CountY, w.OffX, w.OffY, w.CountX, w.CountY)
+			}
+			if mean := res.TimeSeries[0]; mean.Value != tc.expected || mean.Count != tc.count {
+				t.Errorf("expected a mean of %v over %v pixels, got %v over %v", tc.expected, tc.count, mean.Value, mean.Count)
+			}
+		})
+	}
+}
+
+func TestDrillPerBandClipBounds(t *testing.T) {
+	rows := newRampGrid(10, 10, 0)
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The last band falls back to the scalar bounds
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[6,2],[6,6],[2,6],[2,2]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{Bands: []int32{1, 1, 1}, ClipUpperPerBand: []float32{50.5, 60.5}})
+	expected := []struct {
+		mean  float64
+		count int32
+	}{{43.5, 4}, {48.5, 8}, {58.5, 16}}
+	for i, exp := range expected {
+		if mean := res.TimeSeries[i]; mean.Value != exp.mean || mean.Count != exp.count {
+			t.Errorf("band %d: expected mean %v over %v pixels, got %v over %v", i, exp.mean, exp.count, mean.Value, mean.Count)
+		}
+	}
+
+	in := &pb.GeoRPCGranule{Operation: "drill", Path: path, Geometry: fmt.Sprintf(`{"type":"Feature","geometry":%s,"properties":{}}`, geometry), Bands: []int32{1}, ClipUpperPerBand: []float32{1, 2}}
+	if res := DrillDataset(context.Background(), in); res.Error == "OK" {
+		t.Errorf("expected an error for more clip bounds than bands")
+	}
+}
+
+func TestDrillValidCounts(t *testing.T) {
+	rows := newTestGrid(10, 10, 1)
+	rows[6][2] = -9999
+	path := writeTestGrid(t, rows, -9999)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// 1 of the 4 pixels of the polygon is NoData
+	geometry := `{"type":"Polygon","coordinates":[[[2,2],[4,2],[4,4],[2,4],[2,2]]]}`
+	res := drillTestGrid(t, path, geometry, &pb.GeoRPCGranule{})
+	if mean := res.TimeSeries[0]; mean.ValidCount != 3 || mean.TotalMaskedCount != 4 {
+		t.Errorf("expected 3 valid pixels out of 4, got %v out of %v", mean.ValidCount, mean.TotalMaskedCount)
+	}
+}
@@ -0,0 +1,63 @@
+package gdalprocess
+
+// #include <stdlib.h>
+// #include "gdal.h"
+// #include "gdal_utils.h"
+// #cgo pkg-config: gdal
+import "C"
+
+import (
+	"fmt"
+	"strconv"
+	"unsafe"
+
+	pb "github.com/nci/gsky/worker/gdalservice"
+)
+
+// warpResamplings maps the resamplings to their gdalwarp names.
+var warpResamplings = map[pb.Resampling]string{
+	pb.Resampling_NEAREST:  "near",
+	pb.Resampling_BILINEAR: "bilinear",
+	pb.Resampling_CUBIC:    "cubic",
+}
+
+// warpOnRead returns a VRT warping the dataset to the target SRS of the
+// request, and to its target resolution if given, such that datasets in
+// different native SRSs are drilled on a common grid. The pixels are
+// warped as they're read and the geometry is masked on the target grid.
+// The VRT must be closed before the dataset.
+func warpOnRead(ds C.GDALDatasetH, in *pb.GeoRPCGranule) (C.GDALDatasetH, error) {
+	args := []string{"-of", "VRT", "-t_srs", in.WarpSRS, "-r", warpResamplings[in.WarpResampling]}
+	switch len(in.WarpResolution) {
+	case 0:
+	case 1:
+		res := strconv.FormatFloat(in.WarpResolution[0], 'g', -1, 64)
+		args = append(args, "-tr", res, res)
+	case 2:
+		args = append(args, "-tr", strconv.FormatFloat(in.WarpResolution[0], 'g', -1, 64), strconv.FormatFloat(in.WarpResolution[1], 'g', -1, 64))
+	default:
+		return nil, fmt.Errorf("expected 1 or 2 warp resolution values, got %d", len(in.WarpResolution))
+	}
+	for _, res := range in.WarpResolution {
+		if res <= 0 {
+			return nil, fmt.Errorf("warp resolution must be positive: %v", res)
+		}
+	}
+
+	argv, freeArgv := cStringList(args)
+	defer freeArgv()
+	opts := C.GDALWarpAppOptionsNew(argv, nil)
+	if opts == nil {
+		return nil, fmt.Errorf("invalid warp options: %v", args)
+	}
+	defer C.GDALWarpAppOptionsFree(opts)
+
+	cEmpty := C.CString("")
+	defer C.free(unsafe.Pointer(cEmpty))
+	var usageErr C.int
+	warped := C.GDALWarp(cEmpty, nil, 1, &ds, opts, &usageErr)
+	if warped == nil {
+		return nil, fmt.Errorf("failed to warp the dataset to %s", in.WarpSRS)
+	}
+	return warped, nil
+}
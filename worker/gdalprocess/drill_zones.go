@@ -0,0 +1,32 @@
+package gdalprocess
+
+// distinctZoneIDs returns the distinct zone IDs in the order they first
+// appear along with the index of the distinct ID of every feature.
+// Features sharing a zone ID are reduced as a single zone.
+func distinctZoneIDs(ids []int32) ([]int32, []int) {
+	var distinct []int32
+	index := make([]int, len(ids))
+	seen := make(map[int32]int)
+	for i, id := range ids {
+		iZone, ok := seen[id]
+		if !ok {
+			iZone = len(distinct)
+			seen[id] = iZone
+			distinct = append(distinct, id)
+		}
+		index[i] = iZone
+	}
+	return distinct, index
+}
+
+// zoneMask returns the Byte mask of the pixels of mask within the zone
+// of index iZone, whose pixels are burnt with iZone+1 in zones.
+func zoneMask(mask *MaskBuffer, zones []int32, iZone int) *MaskBuffer {
+	zMask := &MaskBuffer{Byte: make([]uint8, mask.len())}
+	for i := range zMask.Byte {
+		if mask.isSet(i) && zones[i] == int32(iZone+1) {
+			zMask.Byte[i] = defaultMaskBurnValue
+		}
+	}
+	return zMask
+}
@@ -0,0 +1,78 @@
+package gdalprocess
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	pb "github.com/nci/gsky/worker/gdalservice"
+)
+
+func TestDistinctZoneIDs(t *testing.T) {
+	distinct, index := distinctZoneIDs([]int32{7, 3, 7, 5, 3})
+	if !reflect.DeepEqual(distinct, []int32{7, 3, 5}) {
+		t.Errorf("expected distinct IDs [7 3 5], actual %v", distinct)
+	}
+	if !reflect.DeepEqual(index, []int{0, 1, 0, 2, 1}) {
+		t.Errorf("expected indices [0 1 0 2 1], actual %v", index)
+	}
+}
+
+func TestZoneMask(t *testing.T) {
+	mask := &MaskBuffer{Byte: []uint8{255, 255, 0, 255, 255}}
+	zones := []int32{1, 2, 2, 0, 2}
+	if zMask := zoneMask(mask, zones, 1); !reflect.DeepEqual(zMask.Byte, []uint8{0, 255, 0, 0, 255}) {
+		t.Errorf("expected [0 255 0 0 255], actual %v", zMask)
+	}
+}
+
+func TestZoneMaskBurnValues(t *testing.T) {
+	// Any non-zero value flags a pixel within the geometry, whatever
+	// the type of the mask
+	zones := []int32{2, 2, 2, 1, 2}
+	for _, mask := range []*MaskBuffer{
+		{Byte: []uint8{1, 7, 0, 255, 3}},
+		{Int16: []int16{1, 1000, 0, 255, -3}},
+		{Float32: []float32{0.25, 1000, 0, 255, -3}},
+	} {
+		if zMask := zoneMask(mask, zones, 1); !reflect.DeepEqual(zMask.Byte, []uint8{255, 255, 0, 0, 255}) {
+			t.Errorf("expected [255 255 0 0 255], actual %v", zMask.Byte)
+		}
+	}
+}
+
+func TestMaskBurnValue(t *testing.T) {
+	for _, tc := range []struct {
+		maskType  pb.MaskType
+		burnValue float64
+		expected  float64
+		fails     bool
+	}{
+		{pb.MaskType_MASK_BYTE, 0, 255, false},
+		{pb.MaskType_MASK_BYTE, 7, 7, false},
+		{pb.MaskType_MASK_BYTE, 256, 0, true},
+		{pb.MaskType_MASK_INT16, 1000, 1000, false},
+		{pb.MaskType_MASK_INT16, 1.5, 0, true},
+		{pb.MaskType_MASK_INT16, 40000, 0, true},
+		{pb.MaskType_MASK_FLOAT32, 0.25, 0.25, false},
+		{pb.MaskType_MASK_FLOAT32, math.NaN(), 0, true},
+	} {
+		val, err := maskBurnValue(&pb.GeoRPCGranule{MaskType: tc.maskType, MaskBurnValue: tc.burnValue})
+		if (err != nil) != tc.fails || val != tc.expected {
+			t.Errorf("%v %v: unexpected burn value %v, error %v", tc.maskType, tc.burnValue, val, err)
+		}
+	}
+}
+
+func TestMaskBufferClear(t *testing.T) {
+	mask := newMaskBuffer(pb.MaskType_MASK_INT16, 3)
+	mask.Int16[0], mask.Int16[2] = 1000, -1
+	c := mask.clone()
+	c.clear(0)
+	if c.isSet(0) || !c.isSet(2) || c.isSet(1) {
+		t.Errorf("unexpected cleared mask %v", c.Int16)
+	}
+	if !mask.isSet(0) || mask.size() != 6 {
+		t.Errorf("unexpected original mask %v", mask.Int16)
+	}
+}
@@ -0,0 +1,86 @@
+package gdalprocess
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache is a fixed capacity cache of exclusively owned values.
+// A value retrieved with take is removed from the cache until it's
+// handed back with put, so that a value is never used by two goroutines
+// at the same time nor evicted while in use. Values evicted or rejected
+// by the cache are released with the onEvict callback. The capacity is
+// the number of entries unless the cache is sized, in which case it's
+// the total size of the values.
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	used     int
+	entries  *list.List
+	index    map[string]*list.Element
+	sizeOf   func(value interface{}) int
+	onEvict  func(value interface{})
+}
+
+type lruEntry struct {
+	key   string
+	value interface{}
+	size  int
+}
+
+func newLRUCache(capacity int, onEvict func(value interface{})) *lruCache {
+	return newSizedLRUCache(capacity, func(interface{}) int { return 1 }, onEvict)
+}
+
+// newSizedLRUCache returns a cache holding values up to the total size
+// given by capacity, the size of a value being returned by sizeOf.
+func newSizedLRUCache(capacity int, sizeOf func(value interface{}) int, onEvict func(value interface{})) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		entries:  list.New(),
+		index:    make(map[string]*list.Element),
+		sizeOf:   sizeOf,
+		onEvict:  onEvict,
+	}
+}
+
+// take removes the value of key from the cache and returns it.
+func (c *lruCache) take(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.index[key]
+	if !found {
+		return nil, false
+	}
+	c.entries.Remove(elem)
+	delete(c.index, key)
+	entry := elem.Value.(*lruEntry)
+	c.used -= entry.size
+	return entry.value, true
+}
+
+// put inserts the value of key as the most recently used entry,
+// evicting the least recently used entries until it fits. A value
+// larger than the capacity is rejected.
+func (c *lruCache) put(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	size := c.sizeOf(value)
+	if _, found := c.index[key]; found || size > c.capacity {
+		c.onEvict(value)
+		return
+	}
+
+	c.index[key] = c.entries.PushFront(&lruEntry{key, value, size})
+	c.used += size
+	for c.used > c.capacity {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		entry := oldest.Value.(*lruEntry)
+		delete(c.index, entry.key)
+		c.used -= entry.size
+		c.onEvict(entry.value)
+	}
+}
@@ -0,0 +1,61 @@
+package gdalprocess
+
+import (
+	"testing"
+)
+
+func TestLRUCache(t *testing.T) {
+	var evicted []interface{}
+	cache := newLRUCache(2, func(value interface{}) { evicted = append(evicted, value) })
+
+	cache.put("a", 1)
+	cache.put("b", 2)
+	cache.put("c", 3)
+	if len(evicted) != 1 || evicted[0] != 1 {
+		t.Errorf("expected the least recently used value to be evicted, evicted: %v", evicted)
+	}
+
+	if _, found := cache.take("a"); found {
+		t.Errorf("evicted value is still cached")
+	}
+
+	val, found := cache.take("b")
+	if !found || val != 2 {
+		t.Errorf("unexpected cached value: %v, %v", val, found)
+	}
+	if _, found := cache.take("b"); found {
+		t.Errorf("taken value must not be shared")
+	}
+
+	cache.put("b", 2)
+	cache.put("b", 4)
+	if len(evicted) != 2 || evicted[1] != 4 {
+		t.Errorf("expected the duplicated value to be released, evicted: %v", evicted)
+	}
+}
+
+func TestSizedLRUCache(t *testing.T) {
+	var evicted []interface{}
+	cache := newSizedLRUCache(10, func(value interface{}) int { return len(value.(string)) }, func(value interface{}) { evicted = append(evicted, value) })
+
+	cache.put("a", "aaaa")
+	cache.put("b", "bbbb")
+	cache.put("c", "cccc")
+	if len(evicted) != 1 || evicted[0] != "aaaa" {
+		t.Errorf("expected the least recently used value to be evicted, evicted: %v", evicted)
+	}
+
+	// Taken values don't count towards the size
+	if val, found := cache.take("b"); !found || val != "bbbb" {
+		t.Errorf("unexpected cached value: %v, %v", val, found)
+	}
+	cache.put("d", "dddddd")
+	if len(evicted) != 1 {
+		t.Errorf("unexpected eviction: %v", evicted)
+	}
+
+	cache.put("e", "eeeeeeeeeee")
+	if len(evicted) != 2 || evicted[1] != "eeeeeeeeeee" {
+		t.Errorf("expected the value larger than the cache to be rejected, evicted: %v", evicted)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"io"
 	"log"
 
@@ -29,6 +30,12 @@ type Task struct {
 	Resp      chan *pb.Result
 	Error     chan error
 	NumTrials int
+
+	// Partial receives the partial results of a streamed task, i.e.
+	// one whose payload has Stream set, before the final one is sent
+	// to Resp.
+	Partial  chan *pb.Result
+	streamed bool
 }
 
 type Process struct {
@@ -128,20 +135,32 @@ func (p *Process) Start() error {
 			}
 			conn.CloseWrite()
 
-			var buf bytes.Buffer
-			nr, err := io.Copy(&buf, conn)
-			if err != nil {
+			out := new(pb.Result)
+			if task.Payload.Stream {
+				final, err := readResultStream(conn, task)
+				conn.Close()
+				if err != nil {
+					p.retryTask(task, fmt.Errorf("stream read failed: %v", err))
+					break
+				}
+				if final != nil {
+					out = final
+				}
+			} else {
+				var buf bytes.Buffer
+				nr, err := io.Copy(&buf, conn)
+				if err != nil {
+					conn.Close()
+					p.retryTask(task, fmt.Errorf("io.copy failed: %v, bytes read: %v", err, nr))
+					break
+				}
 				conn.Close()
-				p.retryTask(task, fmt.Errorf("io.copy failed: %v, bytes read: %v", err, nr))
-				break
-			}
-			conn.Close()
 
-			out := new(pb.Result)
-			err = proto.Unmarshal(buf.Bytes(), out)
-			if err != nil {
-				task.Error <- fmt.Errorf("error decoding data: %v", err)
-				continue
+				err = proto.Unmarshal(buf.Bytes(), out)
+				if err != nil {
+					task.Error <- fmt.Errorf("error decoding data: %v", err)
+					continue
+				}
 			}
 
 			if len(out.Error) == 0 {
@@ -189,7 +208,8 @@ func (p *Process) Start() error {
 func (p *Process) retryTask(task *Task, taskErr error) {
 	syscall.Kill(p.Cmd.Process.Pid, syscall.SIGKILL)
 	task.NumTrials++
-	if task.NumTrials >= 5 {
+	// The partial results already sent can't be taken back
+	if task.NumTrials >= 5 || task.streamed {
 		task.Error <- taskErr
 	} else {
 		p.TaskQueue <- task
@@ -201,3 +221,60 @@ func (p *Process) RemoveTempFiles() {
 	os.Remove(p.TempFile)
 	os.Remove(p.Address)
 }
+
+// WriteResultFrame writes the result prefixed by its varint encoded
+// length, which delimits the results streamed over the socket.
+func WriteResultFrame(w io.Writer, out *pb.Result) error {
+	outb, err := proto.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	frame := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(outb))
+	n := binary.PutUvarint(frame, uint64(len(outb)))
+	_, err = w.Write(append(frame[:n], outb...))
+	return err
+}
+
+// readResultFrame reads a result written by WriteResultFrame. It returns
+// io.EOF if the stream ends before the frame.
+func readResultFrame(r *bufio.Reader) (*pb.Result, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	out := new(pb.Result)
+	if err := proto.Unmarshal(buf, out); err != nil {
+		return nil, fmt.Errorf("error decoding data: %v", err)
+	}
+	return out, nil
+}
+
+// readResultStream reads the results streamed for the task until the
+// end of the stream. All but the last result are sent to the partial
+// channel of the task, the last one is returned.
+func readResultStream(r io.Reader, task *Task) (*pb.Result, error) {
+	rd := bufio.NewReader(r)
+	var out *pb.Result
+	for {
+		res, err := readResultFrame(rd)
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if out != nil {
+			task.streamed = true
+			task.Partial <- out
+		}
+		out = res
+	}
+}
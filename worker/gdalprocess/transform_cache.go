@@ -0,0 +1,101 @@
+package gdalprocess
+
+// #include <stdlib.h>
+// #include "ogr_srs_api.h"
+// #include "cpl_conv.h"
+// #cgo pkg-config: gdal
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// transformCacheSize is the maximum number of coordinate
+// transformations kept alive across granules.
+const transformCacheSize = 32
+
+// transformCache holds the coordinate transformations between the
+// geometry and dataset SRSes keyed by the WKT of both and the options of
+// the transformation. Creating a transformation goes through the PROJ
+// database, which is costly compared to transforming a geometry.
+var transformCache = newLRUCache(transformCacheSize, func(value interface{}) {
+	C.OCTDestroyCoordinateTransformation(value.(*coordTransform).trans)
+})
+
+// coordTransform is a coordinate transformation along with whether it's
+// a ballpark transformation, i.e. one ignoring the datum shifts, which
+// may be off by tens of meters.
+type coordTransform struct {
+	trans    C.OGRCoordinateTransformationH
+	ballpark bool
+}
+
+func exportToWkt(hSRS C.OGRSpatialReferenceH) string {
+	var cWkt *C.char
+	if C.OSRExportToWkt(hSRS, &cWkt) != C.OGRERR_NONE {
+		return ""
+	}
+	defer C.VSIFree(unsafe.Pointer(cWkt))
+	return C.GoString(cWkt)
+}
+
+// acquireTransform returns a coordinate transformation from srcSRS to
+// the dataset projection dstWKT along with its cache key. The caller
+// owns the transformation until it's handed back with releaseTransform.
+//
+// The transformation follows the PROJ coordinate operation, e.g. a
+// pipeline, if given. Otherwise PROJ picks the most accurate one for the
+// area of interest (west, south, east, north in degrees) if given, or
+// for the whole area of use of both SRSes. A ballpark transformation is
+// only used if no accurate one is available, e.g. for a missing grid.
+func acquireTransform(srcSRS C.OGRSpatialReferenceH, dstWKT *C.char, operation string, areaOfInterest []float64) (*coordTransform, string, error) {
+	if len(areaOfInterest) != 0 && len(areaOfInterest) != 4 {
+		return nil, "", fmt.Errorf("area of interest must be given as west, south, east, north: %v", areaOfInterest)
+	}
+
+	key := fmt.Sprintf("%s\n%s\n%s\n%v", exportToWkt(srcSRS), C.GoString(dstWKT), operation, areaOfInterest)
+	if value, found := transformCache.take(key); found {
+		return value.(*coordTransform), key, nil
+	}
+
+	desSRS := C.OSRNewSpatialReference(dstWKT)
+	defer C.OSRDestroySpatialReference(desSRS)
+
+	opts := C.OCTNewCoordinateTransformationOptions()
+	defer C.OCTDestroyCoordinateTransformationOptions(opts)
+	if len(operation) > 0 {
+		cOperation := C.CString(operation)
+		defer C.free(unsafe.Pointer(cOperation))
+		if C.OCTCoordinateTransformationOptionsSetOperation(opts, cOperation, 0) == 0 {
+			return nil, "", fmt.Errorf("invalid coordinate operation: %s", operation)
+		}
+	}
+	if len(areaOfInterest) == 4 {
+		aoi := areaOfInterest
+		if C.OCTCoordinateTransformationOptionsSetAreaOfInterest(opts, C.double(aoi[0]), C.double(aoi[1]), C.double(aoi[2]), C.double(aoi[3])) == 0 {
+			return nil, "", fmt.Errorf("invalid area of interest: %v", aoi)
+		}
+	}
+
+	C.OCTCoordinateTransformationOptionsSetBallparkAllowed(opts, 0)
+	if trans := C.OCTNewCoordinateTransformationEx(srcSRS, desSRS, opts); trans != nil {
+		return &coordTransform{trans: trans}, key, nil
+	}
+	if len(operation) > 0 {
+		return nil, "", fmt.Errorf("coordinate operation could not be instantiated: %s", operation)
+	}
+
+	C.OCTCoordinateTransformationOptionsSetBallparkAllowed(opts, 1)
+	if trans := C.OCTNewCoordinateTransformationEx(srcSRS, desSRS, opts); trans != nil {
+		return &coordTransform{trans: trans, ballpark: true}, key, nil
+	}
+	return nil, "", fmt.Errorf("no coordinate transformation from the geometry SRS to the dataset SRS")
+}
+
+func releaseTransform(ct *coordTransform, key string) {
+	if ct == nil {
+		return
+	}
+	transformCache.put(key, ct)
+}
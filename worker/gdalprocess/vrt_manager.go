@@ -2,6 +2,7 @@ package gdalprocess
 
 /*
 #include "gdal.h"
+#include "gdal_utils.h"
 #include "cpl_vsi.h"
 #cgo pkg-config: gdal
 
@@ -175,6 +176,37 @@ func NewVRTManager(vrt []byte) (*VRTManager, error) {
 	return vrtMgr, nil
 }
 
+// NewVRTManagerFromHrefs builds an in-memory VRT stacking the assets,
+// e.g. the hrefs of the items returned by a STAC search, with
+// GDALBuildVRT. The first band of every asset becomes a band of the VRT
+// in the order given.
+func NewVRTManagerFromHrefs(hrefs []string) (*VRTManager, error) {
+	vsiFile := fmt.Sprintf("/vsimem/file%04d.vrt", rand.Intn(1000))
+	vsiFileC := C.CString(vsiFile)
+	defer C.free(unsafe.Pointer(vsiFileC))
+
+	srcNames, freeSrcNames := cStringList(hrefs)
+	defer freeSrcNames()
+
+	argv, freeArgv := cStringList([]string{"-separate"})
+	defer freeArgv()
+	opts := C.GDALBuildVRTOptionsNew(argv, nil)
+	if opts == nil {
+		return nil, fmt.Errorf("failed to create the GDALBuildVRT options")
+	}
+	defer C.GDALBuildVRTOptionsFree(opts)
+
+	var usageErr C.int
+	ds := C.GDALBuildVRT(vsiFileC, C.int(len(hrefs)), nil, srcNames, opts, &usageErr)
+	if ds == nil {
+		return nil, fmt.Errorf("GDALBuildVRT failed on %d assets", len(hrefs))
+	}
+	// Closing the dataset writes the VRT to the in-memory file
+	C.GDALClose(ds)
+
+	return &VRTManager{DSFileName: vsiFile}, nil
+}
+
 func (mgr *VRTManager) Close() {
 	if len(mgr.DSFileName) > 0 {
 		fileC := C.CString(mgr.DSFileName)
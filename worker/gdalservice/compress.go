@@ -0,0 +1,66 @@
+package gdalservice
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+
+	proto "github.com/golang/protobuf/proto"
+)
+
+// CompressTimeSeries replaces the time series of the result by their
+// gzipped protobuf encoding, which shrinks the long time series of many
+// bands and statistics several fold for the cost of a fast compression.
+func CompressTimeSeries(res *Result) error {
+	if len(res.TimeSeries) == 0 {
+		return nil
+	}
+
+	raw, err := proto.Marshal(&Result{TimeSeries: res.TimeSeries})
+	if err != nil {
+		return fmt.Errorf("failed to encode the time series: %v", err)
+	}
+
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(raw); err != nil {
+		return fmt.Errorf("failed to compress the time series: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress the time series: %v", err)
+	}
+
+	res.CompressedTimeSeries = buf.Bytes()
+	res.TimeSeries = nil
+	return nil
+}
+
+// DecompressTimeSeries restores the time series of a result compressed
+// by CompressTimeSeries. Results without compressed time series are
+// left as they are.
+func DecompressTimeSeries(res *Result) error {
+	if len(res.CompressedTimeSeries) == 0 {
+		return nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(res.CompressedTimeSeries))
+	if err != nil {
+		return fmt.Errorf("failed to decompress the time series: %v", err)
+	}
+	raw, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return fmt.Errorf("failed to decompress the time series: %v", err)
+	}
+
+	decoded := &Result{}
+	if err := proto.Unmarshal(raw, decoded); err != nil {
+		return fmt.Errorf("failed to decode the time series: %v", err)
+	}
+	res.TimeSeries = decoded.TimeSeries
+	res.CompressedTimeSeries = nil
+	return nil
+}
@@ -0,0 +1,67 @@
+package gdalservice
+
+import (
+	"math"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+)
+
+// drillTimeSeries returns the rows of a drill of nBands bands with the
+// mean and the nine deciles of a smooth seasonal signal.
+func drillTimeSeries(nBands int) []*TimeSeries {
+	const nCols = 10
+	rows := make([]*TimeSeries, 0, nBands*nCols)
+	for ib := 0; ib < nBands; ib++ {
+		t := int64(946684800 + ib*86400*8)
+		for ic := 0; ic < nCols; ic++ {
+			val := 0.3 + 0.2*math.Sin(float64(ib)*2*math.Pi/46) + 0.01*float64(ic)
+			rows = append(rows, &TimeSeries{Value: val, Count: 1200, Time: t, ValidCount: 1200, TotalMaskedCount: 1250})
+		}
+	}
+	return rows
+}
+
+func TestCompressTimeSeries(t *testing.T) {
+	rows := drillTimeSeries(100)
+	res := &Result{TimeSeries: rows, Error: "OK"}
+	if err := CompressTimeSeries(res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.TimeSeries) != 0 || len(res.CompressedTimeSeries) == 0 {
+		t.Fatalf("expected the time series to be compressed")
+	}
+
+	if err := DecompressTimeSeries(res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.TimeSeries) != len(rows) || len(res.CompressedTimeSeries) != 0 {
+		t.Fatalf("expected %d rows, actual %d", len(rows), len(res.TimeSeries))
+	}
+	for i, ts := range res.TimeSeries {
+		if !proto.Equal(ts, rows[i]) {
+			t.Errorf("row %d: expected %v, actual %v", i, rows[i], ts)
+		}
+	}
+}
+
+// BenchmarkCompressTimeSeries measures the compression of the rows of
+// a 5000-band drill, logging the size of the result with and without it.
+func BenchmarkCompressTimeSeries(b *testing.B) {
+	rows := drillTimeSeries(5000)
+	raw, err := proto.Marshal(&Result{TimeSeries: rows})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(raw)))
+
+	var compressed int
+	for i := 0; i < b.N; i++ {
+		res := &Result{TimeSeries: rows}
+		if err := CompressTimeSeries(res); err != nil {
+			b.Fatal(err)
+		}
+		compressed = len(res.CompressedTimeSeries)
+	}
+	b.Logf("%d bytes compressed to %d bytes", len(raw), compressed)
+}
@@ -11,11 +11,16 @@ It has these top-level messages:
 	GeoRPCGranule
 	Raster
 	TimeSeries
+	BandPixels
+	Histogram
+	ClassFractions
+	BandChecksum
 	Overview
 	GeoMetaData
 	GeoFile
 	WorkerInfo
 	WorkerMetrics
+	Window
 	Result
 */
 package gdalservice
@@ -41,25 +46,278 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type Aggregation int32
+
+const (
+	Aggregation_ARITHMETIC Aggregation = 0
+	Aggregation_GEOMETRIC  Aggregation = 1
+	Aggregation_HARMONIC   Aggregation = 2
+)
+
+var Aggregation_name = map[int32]string{
+	0: "ARITHMETIC",
+	1: "GEOMETRIC",
+	2: "HARMONIC",
+}
+var Aggregation_value = map[string]int32{
+	"ARITHMETIC": 0,
+	"GEOMETRIC":  1,
+	"HARMONIC":   2,
+}
+
+func (x Aggregation) String() string {
+	return proto.EnumName(Aggregation_name, int32(x))
+}
+func (Aggregation) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type Interpolation int32
+
+const (
+	Interpolation_LINEAR Interpolation = 0
+	Interpolation_PCHIP  Interpolation = 1
+)
+
+var Interpolation_name = map[int32]string{
+	0: "LINEAR",
+	1: "PCHIP",
+}
+var Interpolation_value = map[string]int32{
+	"LINEAR": 0,
+	"PCHIP":  1,
+}
+
+func (x Interpolation) String() string {
+	return proto.EnumName(Interpolation_name, int32(x))
+}
+func (Interpolation) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type Resampling int32
+
+const (
+	Resampling_NEAREST  Resampling = 0
+	Resampling_BILINEAR Resampling = 1
+	Resampling_CUBIC    Resampling = 2
+)
+
+var Resampling_name = map[int32]string{
+	0: "NEAREST",
+	1: "BILINEAR",
+	2: "CUBIC",
+}
+var Resampling_value = map[string]int32{
+	"NEAREST":  0,
+	"BILINEAR": 1,
+	"CUBIC":    2,
+}
+
+func (x Resampling) String() string {
+	return proto.EnumName(Resampling_name, int32(x))
+}
+func (Resampling) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+type ComplexPart int32
+
+const (
+	ComplexPart_AMPLITUDE ComplexPart = 0
+	ComplexPart_INTENSITY ComplexPart = 1
+	ComplexPart_REAL      ComplexPart = 2
+	ComplexPart_IMAG      ComplexPart = 3
+	ComplexPart_PHASE     ComplexPart = 4
+)
+
+var ComplexPart_name = map[int32]string{
+	0: "AMPLITUDE",
+	1: "INTENSITY",
+	2: "REAL",
+	3: "IMAG",
+	4: "PHASE",
+}
+var ComplexPart_value = map[string]int32{
+	"AMPLITUDE": 0,
+	"INTENSITY": 1,
+	"REAL":      2,
+	"IMAG":      3,
+	"PHASE":     4,
+}
+
+func (x ComplexPart) String() string {
+	return proto.EnumName(ComplexPart_name, int32(x))
+}
+func (ComplexPart) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type Statistic int32
+
+const (
+	Statistic_STAT_MEAN            Statistic = 0
+	Statistic_STAT_STDDEV          Statistic = 1
+	Statistic_STAT_MEDIAN          Statistic = 2
+	Statistic_STAT_MINMAX          Statistic = 4
+	Statistic_STAT_MODE            Statistic = 8
+	Statistic_STAT_IQR             Statistic = 16
+	Statistic_STAT_MAD             Statistic = 32
+	Statistic_STAT_SUM             Statistic = 64
+	Statistic_STAT_STDERROR        Statistic = 128
+	Statistic_STAT_CV              Statistic = 256
+	Statistic_STAT_NODATA_FRACTION Statistic = 512
+	Statistic_STAT_MOMENTS         Statistic = 1024
+)
+
+var Statistic_name = map[int32]string{
+	0:    "STAT_MEAN",
+	1:    "STAT_STDDEV",
+	2:    "STAT_MEDIAN",
+	4:    "STAT_MINMAX",
+	8:    "STAT_MODE",
+	16:   "STAT_IQR",
+	32:   "STAT_MAD",
+	64:   "STAT_SUM",
+	128:  "STAT_STDERROR",
+	256:  "STAT_CV",
+	512:  "STAT_NODATA_FRACTION",
+	1024: "STAT_MOMENTS",
+}
+var Statistic_value = map[string]int32{
+	"STAT_MEAN":            0,
+	"STAT_STDDEV":          1,
+	"STAT_MEDIAN":          2,
+	"STAT_MINMAX":          4,
+	"STAT_MODE":            8,
+	"STAT_IQR":             16,
+	"STAT_MAD":             32,
+	"STAT_SUM":             64,
+	"STAT_STDERROR":        128,
+	"STAT_CV":              256,
+	"STAT_NODATA_FRACTION": 512,
+	"STAT_MOMENTS":         1024,
+}
+
+func (x Statistic) String() string {
+	return proto.EnumName(Statistic_name, int32(x))
+}
+func (Statistic) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+type MaskType int32
+
+const (
+	MaskType_MASK_BYTE    MaskType = 0
+	MaskType_MASK_INT16   MaskType = 1
+	MaskType_MASK_FLOAT32 MaskType = 2
+)
+
+var MaskType_name = map[int32]string{
+	0: "MASK_BYTE",
+	1: "MASK_INT16",
+	2: "MASK_FLOAT32",
+}
+var MaskType_value = map[string]int32{
+	"MASK_BYTE":    0,
+	"MASK_INT16":   1,
+	"MASK_FLOAT32": 2,
+}
+
+func (x MaskType) String() string {
+	return proto.EnumName(MaskType_name, int32(x))
+}
+func (MaskType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
 type GeoRPCGranule struct {
-	Operation        string    `protobuf:"bytes,1,opt,name=operation" json:"operation,omitempty"`
-	Path             string    `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
-	Geometry         string    `protobuf:"bytes,3,opt,name=geometry" json:"geometry,omitempty"`
-	Bands            []int32   `protobuf:"varint,4,rep,packed,name=bands" json:"bands,omitempty"`
-	Height           int32     `protobuf:"varint,5,opt,name=height" json:"height,omitempty"`
-	Width            int32     `protobuf:"varint,6,opt,name=width" json:"width,omitempty"`
-	SrcSRS           string    `protobuf:"bytes,7,opt,name=srcSRS" json:"srcSRS,omitempty"`
-	SrcGeot          []float64 `protobuf:"fixed64,8,rep,packed,name=srcGeot" json:"srcGeot,omitempty"`
-	DstSRS           string    `protobuf:"bytes,9,opt,name=dstSRS" json:"dstSRS,omitempty"`
-	DstGeot          []float64 `protobuf:"fixed64,10,rep,packed,name=dstGeot" json:"dstGeot,omitempty"`
-	BandStrides      int32     `protobuf:"varint,11,opt,name=bandStrides" json:"bandStrides,omitempty"`
-	GeoLocOpts       []string  `protobuf:"bytes,12,rep,name=geoLocOpts" json:"geoLocOpts,omitempty"`
-	DrillDecileCount int32     `protobuf:"varint,13,opt,name=drillDecileCount" json:"drillDecileCount,omitempty"`
-	ClipUpper        float32   `protobuf:"fixed32,14,opt,name=clipUpper" json:"clipUpper,omitempty"`
-	ClipLower        float32   `protobuf:"fixed32,15,opt,name=clipLower" json:"clipLower,omitempty"`
-	SRSCf            int32     `protobuf:"varint,16,opt,name=sRSCf" json:"sRSCf,omitempty"`
-	PixelCount       int32     `protobuf:"varint,17,opt,name=pixelCount" json:"pixelCount,omitempty"`
-	VRT              string    `protobuf:"bytes,18,opt,name=vRT" json:"vRT,omitempty"`
+	Operation                string        `protobuf:"bytes,1,opt,name=operation" json:"operation,omitempty"`
+	Path                     string        `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
+	Geometry                 string        `protobuf:"bytes,3,opt,name=geometry" json:"geometry,omitempty"`
+	Bands                    []int32       `protobuf:"varint,4,rep,packed,name=bands" json:"bands,omitempty"`
+	Height                   int32         `protobuf:"varint,5,opt,name=height" json:"height,omitempty"`
+	Width                    int32         `protobuf:"varint,6,opt,name=width" json:"width,omitempty"`
+	SrcSRS                   string        `protobuf:"bytes,7,opt,name=srcSRS" json:"srcSRS,omitempty"`
+	SrcGeot                  []float64     `protobuf:"fixed64,8,rep,packed,name=srcGeot" json:"srcGeot,omitempty"`
+	DstSRS                   string        `protobuf:"bytes,9,opt,name=dstSRS" json:"dstSRS,omitempty"`
+	DstGeot                  []float64     `protobuf:"fixed64,10,rep,packed,name=dstGeot" json:"dstGeot,omitempty"`
+	BandStrides              int32         `protobuf:"varint,11,opt,name=bandStrides" json:"bandStrides,omitempty"`
+	GeoLocOpts               []string      `protobuf:"bytes,12,rep,name=geoLocOpts" json:"geoLocOpts,omitempty"`
+	DrillDecileCount         int32         `protobuf:"varint,13,opt,name=drillDecileCount" json:"drillDecileCount,omitempty"`
+	ClipUpper                float32       `protobuf:"fixed32,14,opt,name=clipUpper" json:"clipUpper,omitempty"`
+	ClipLower                float32       `protobuf:"fixed32,15,opt,name=clipLower" json:"clipLower,omitempty"`
+	SRSCf                    int32         `protobuf:"varint,16,opt,name=sRSCf" json:"sRSCf,omitempty"`
+	PixelCount               int32         `protobuf:"varint,17,opt,name=pixelCount" json:"pixelCount,omitempty"`
+	VRT                      string        `protobuf:"bytes,18,opt,name=vRT" json:"vRT,omitempty"`
+	NoDataTolerance          float32       `protobuf:"fixed32,19,opt,name=noDataTolerance" json:"noDataTolerance,omitempty"`
+	ComputeStdDev            bool          `protobuf:"varint,20,opt,name=computeStdDev" json:"computeStdDev,omitempty"`
+	Percentiles              []float64     `protobuf:"fixed64,21,rep,packed,name=percentiles" json:"percentiles,omitempty"`
+	FractionalCoverage       bool          `protobuf:"varint,22,opt,name=fractionalCoverage" json:"fractionalCoverage,omitempty"`
+	StatsWorkers             int32         `protobuf:"varint,23,opt,name=statsWorkers" json:"statsWorkers,omitempty"`
+	ComputeMedian            bool          `protobuf:"varint,24,opt,name=computeMedian" json:"computeMedian,omitempty"`
+	ComputeMinMax            bool          `protobuf:"varint,25,opt,name=computeMinMax" json:"computeMinMax,omitempty"`
+	GeometrySRS              string        `protobuf:"bytes,26,opt,name=geometrySRS" json:"geometrySRS,omitempty"`
+	ApproxScale              float32       `protobuf:"fixed32,27,opt,name=approxScale" json:"approxScale,omitempty"`
+	PixelBudget              int64         `protobuf:"varint,28,opt,name=pixelBudget" json:"pixelBudget,omitempty"`
+	ApplyScaleOffset         bool          `protobuf:"varint,29,opt,name=applyScaleOffset" json:"applyScaleOffset,omitempty"`
+	ReturnPixels             bool          `protobuf:"varint,30,opt,name=returnPixels" json:"returnPixels,omitempty"`
+	MaxReturnPixels          int32         `protobuf:"varint,31,opt,name=maxReturnPixels" json:"maxReturnPixels,omitempty"`
+	HistogramBins            int32         `protobuf:"varint,32,opt,name=histogramBins" json:"histogramBins,omitempty"`
+	HistogramMin             float64       `protobuf:"fixed64,33,opt,name=histogramMin" json:"histogramMin,omitempty"`
+	HistogramMax             float64       `protobuf:"fixed64,34,opt,name=histogramMax" json:"histogramMax,omitempty"`
+	Aggregation              Aggregation   `protobuf:"varint,35,opt,name=aggregation,enum=gdalservice.Aggregation" json:"aggregation,omitempty"`
+	Interpolation            Interpolation `protobuf:"varint,36,opt,name=interpolation,enum=gdalservice.Interpolation" json:"interpolation,omitempty"`
+	InterpolationCheckStride int32         `protobuf:"varint,37,opt,name=interpolationCheckStride" json:"interpolationCheckStride,omitempty"`
+	Categorical              bool          `protobuf:"varint,38,opt,name=categorical" json:"categorical,omitempty"`
+	Classes                  []int32       `protobuf:"varint,39,rep,packed,name=classes" json:"classes,omitempty"`
+	ComputeMode              bool          `protobuf:"varint,40,opt,name=computeMode" json:"computeMode,omitempty"`
+	BlockAligned             bool          `protobuf:"varint,41,opt,name=blockAligned" json:"blockAligned,omitempty"`
+	BandTimes                []int64       `protobuf:"varint,42,rep,packed,name=bandTimes" json:"bandTimes,omitempty"`
+	ClipInclusive            bool          `protobuf:"varint,43,opt,name=clipInclusive" json:"clipInclusive,omitempty"`
+	ClipByPercentile         bool          `protobuf:"varint,44,opt,name=clipByPercentile" json:"clipByPercentile,omitempty"`
+	TrimFraction             float64       `protobuf:"fixed64,45,opt,name=trimFraction" json:"trimFraction,omitempty"`
+	Resampling               Resampling    `protobuf:"varint,46,opt,name=resampling,enum=gdalservice.Resampling" json:"resampling,omitempty"`
+	BufferMeters             float64       `protobuf:"fixed64,47,opt,name=bufferMeters" json:"bufferMeters,omitempty"`
+	ComputeIQR               bool          `protobuf:"varint,48,opt,name=computeIQR" json:"computeIQR,omitempty"`
+	ComputeMAD               bool          `protobuf:"varint,49,opt,name=computeMAD" json:"computeMAD,omitempty"`
+	ComputeSum               bool          `protobuf:"varint,50,opt,name=computeSum" json:"computeSum,omitempty"`
+	SumByArea                bool          `protobuf:"varint,51,opt,name=sumByArea" json:"sumByArea,omitempty"`
+	OpenOptions              []string      `protobuf:"bytes,52,rep,name=openOptions" json:"openOptions,omitempty"`
+	ConfigOptions            []string      `protobuf:"bytes,53,rep,name=configOptions" json:"configOptions,omitempty"`
+	AllowedDrivers           []string      `protobuf:"bytes,54,rep,name=allowedDrivers" json:"allowedDrivers,omitempty"`
+	WeightBand               int32         `protobuf:"varint,55,opt,name=weightBand" json:"weightBand,omitempty"`
+	ComputeStdError          bool          `protobuf:"varint,56,opt,name=computeStdError" json:"computeStdError,omitempty"`
+	AssetHrefs               []string      `protobuf:"bytes,57,rep,name=assetHrefs" json:"assetHrefs,omitempty"`
+	MetadataOnly             bool          `protobuf:"varint,58,opt,name=metadataOnly" json:"metadataOnly,omitempty"`
+	ConcurrentReads          int32         `protobuf:"varint,59,opt,name=concurrentReads" json:"concurrentReads,omitempty"`
+	ComputeCV                bool          `protobuf:"varint,60,opt,name=computeCV" json:"computeCV,omitempty"`
+	PixelCenterMask          bool          `protobuf:"varint,61,opt,name=pixelCenterMask" json:"pixelCenterMask,omitempty"`
+	MaskBand                 int32         `protobuf:"varint,62,opt,name=maskBand" json:"maskBand,omitempty"`
+	MaskPath                 string        `protobuf:"bytes,63,opt,name=maskPath" json:"maskPath,omitempty"`
+	MaxDrillMemory           int64         `protobuf:"varint,64,opt,name=maxDrillMemory" json:"maxDrillMemory,omitempty"`
+	ComputeNoDataFraction    bool          `protobuf:"varint,65,opt,name=computeNoDataFraction" json:"computeNoDataFraction,omitempty"`
+	BufferSegments           int32         `protobuf:"varint,66,opt,name=bufferSegments" json:"bufferSegments,omitempty"`
+	ComputeMoments           bool          `protobuf:"varint,67,opt,name=computeMoments" json:"computeMoments,omitempty"`
+	Subdataset               string        `protobuf:"bytes,68,opt,name=subdataset" json:"subdataset,omitempty"`
+	MinValidPixels           int32         `protobuf:"varint,69,opt,name=minValidPixels" json:"minValidPixels,omitempty"`
+	MinValidFraction         float64       `protobuf:"fixed64,70,opt,name=minValidFraction" json:"minValidFraction,omitempty"`
+	CoordinateOperation      string        `protobuf:"bytes,71,opt,name=coordinateOperation" json:"coordinateOperation,omitempty"`
+	AreaOfInterest           []float64     `protobuf:"fixed64,72,rep,packed,name=areaOfInterest" json:"areaOfInterest,omitempty"`
+	ComplexPart              ComplexPart   `protobuf:"varint,73,opt,name=complexPart,enum=gdalservice.ComplexPart" json:"complexPart,omitempty"`
+	ZoneIDs                  []int32       `protobuf:"varint,74,rep,packed,name=zoneIDs" json:"zoneIDs,omitempty"`
+	CompensatedSum           bool          `protobuf:"varint,75,opt,name=compensatedSum" json:"compensatedSum,omitempty"`
+	DebugChecksum            bool          `protobuf:"varint,76,opt,name=debugChecksum" json:"debugChecksum,omitempty"`
+	WarpSRS                  string        `protobuf:"bytes,77,opt,name=warpSRS" json:"warpSRS,omitempty"`
+	WarpResolution           []float64     `protobuf:"fixed64,78,rep,packed,name=warpResolution" json:"warpResolution,omitempty"`
+	WarpResampling           Resampling    `protobuf:"varint,79,opt,name=warpResampling,enum=gdalservice.Resampling" json:"warpResampling,omitempty"`
+	InteriorDeciles          bool          `protobuf:"varint,80,opt,name=interiorDeciles" json:"interiorDeciles,omitempty"`
+	Stats                    uint32        `protobuf:"varint,81,opt,name=stats" json:"stats,omitempty"`
+	ClipUpperPerBand         []float32     `protobuf:"fixed32,82,rep,packed,name=clipUpperPerBand" json:"clipUpperPerBand,omitempty"`
+	ClipLowerPerBand         []float32     `protobuf:"fixed32,83,rep,packed,name=clipLowerPerBand" json:"clipLowerPerBand,omitempty"`
+	Stream                   bool          `protobuf:"varint,84,opt,name=stream" json:"stream,omitempty"`
+	SigmaClip                float64       `protobuf:"fixed64,85,opt,name=sigmaClip" json:"sigmaClip,omitempty"`
+	SigmaIterations          int32         `protobuf:"varint,86,opt,name=sigmaIterations" json:"sigmaIterations,omitempty"`
+	CompressTimeSeries       bool          `protobuf:"varint,87,opt,name=compressTimeSeries" json:"compressTimeSeries,omitempty"`
+	OverviewLevel            int32         `protobuf:"varint,88,opt,name=overviewLevel" json:"overviewLevel,omitempty"`
+	MaxBands                 int32         `protobuf:"varint,89,opt,name=maxBands" json:"maxBands,omitempty"`
+	UseRAT                   bool          `protobuf:"varint,90,opt,name=useRAT" json:"useRAT,omitempty"`
+	SmoothWindow             int32         `protobuf:"varint,91,opt,name=smoothWindow" json:"smoothWindow,omitempty"`
+	MaskMin                  float64       `protobuf:"fixed64,92,opt,name=maskMin" json:"maskMin,omitempty"`
+	MaskMax                  float64       `protobuf:"fixed64,93,opt,name=maskMax" json:"maskMax,omitempty"`
+	ApproxQuantiles          bool          `protobuf:"varint,94,opt,name=approxQuantiles" json:"approxQuantiles,omitempty"`
+	MaskType                 MaskType      `protobuf:"varint,95,opt,name=maskType,enum=gdalservice.MaskType" json:"maskType,omitempty"`
+	MaskBurnValue            float64       `protobuf:"fixed64,96,opt,name=maskBurnValue" json:"maskBurnValue,omitempty"`
+	NoDataRelTolerance       float32       `protobuf:"fixed32,97,opt,name=noDataRelTolerance" json:"noDataRelTolerance,omitempty"`
 }
 
 func (m *GeoRPCGranule) Reset()                    { *m = GeoRPCGranule{} }
@@ -137,120 +395,897 @@ func (m *GeoRPCGranule) GetDstGeot() []float64 {
 	return nil
 }
 
-func (m *GeoRPCGranule) GetBandStrides() int32 {
+func (m *GeoRPCGranule) GetBandStrides() int32 {
+	if m != nil {
+		return m.BandStrides
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetGeoLocOpts() []string {
+	if m != nil {
+		return m.GeoLocOpts
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetDrillDecileCount() int32 {
+	if m != nil {
+		return m.DrillDecileCount
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetClipUpper() float32 {
+	if m != nil {
+		return m.ClipUpper
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetClipLower() float32 {
+	if m != nil {
+		return m.ClipLower
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetSRSCf() int32 {
+	if m != nil {
+		return m.SRSCf
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetPixelCount() int32 {
+	if m != nil {
+		return m.PixelCount
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetVRT() string {
+	if m != nil {
+		return m.VRT
+	}
+	return ""
+}
+
+func (m *GeoRPCGranule) GetNoDataTolerance() float32 {
+	if m != nil {
+		return m.NoDataTolerance
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetComputeStdDev() bool {
+	if m != nil {
+		return m.ComputeStdDev
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetPercentiles() []float64 {
+	if m != nil {
+		return m.Percentiles
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetFractionalCoverage() bool {
+	if m != nil {
+		return m.FractionalCoverage
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetStatsWorkers() int32 {
+	if m != nil {
+		return m.StatsWorkers
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetComputeMedian() bool {
+	if m != nil {
+		return m.ComputeMedian
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetComputeMinMax() bool {
+	if m != nil {
+		return m.ComputeMinMax
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetGeometrySRS() string {
+	if m != nil {
+		return m.GeometrySRS
+	}
+	return ""
+}
+
+func (m *GeoRPCGranule) GetApproxScale() float32 {
+	if m != nil {
+		return m.ApproxScale
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetPixelBudget() int64 {
+	if m != nil {
+		return m.PixelBudget
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetApplyScaleOffset() bool {
+	if m != nil {
+		return m.ApplyScaleOffset
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetReturnPixels() bool {
+	if m != nil {
+		return m.ReturnPixels
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetMaxReturnPixels() int32 {
+	if m != nil {
+		return m.MaxReturnPixels
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetHistogramBins() int32 {
+	if m != nil {
+		return m.HistogramBins
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetHistogramMin() float64 {
+	if m != nil {
+		return m.HistogramMin
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetHistogramMax() float64 {
+	if m != nil {
+		return m.HistogramMax
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetAggregation() Aggregation {
+	if m != nil {
+		return m.Aggregation
+	}
+	return Aggregation_ARITHMETIC
+}
+
+func (m *GeoRPCGranule) GetInterpolation() Interpolation {
+	if m != nil {
+		return m.Interpolation
+	}
+	return Interpolation_LINEAR
+}
+
+func (m *GeoRPCGranule) GetInterpolationCheckStride() int32 {
+	if m != nil {
+		return m.InterpolationCheckStride
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetCategorical() bool {
+	if m != nil {
+		return m.Categorical
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetClasses() []int32 {
+	if m != nil {
+		return m.Classes
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetComputeMode() bool {
+	if m != nil {
+		return m.ComputeMode
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetBlockAligned() bool {
+	if m != nil {
+		return m.BlockAligned
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetBandTimes() []int64 {
+	if m != nil {
+		return m.BandTimes
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetClipInclusive() bool {
+	if m != nil {
+		return m.ClipInclusive
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetClipByPercentile() bool {
+	if m != nil {
+		return m.ClipByPercentile
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetTrimFraction() float64 {
+	if m != nil {
+		return m.TrimFraction
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetResampling() Resampling {
+	if m != nil {
+		return m.Resampling
+	}
+	return Resampling_NEAREST
+}
+
+func (m *GeoRPCGranule) GetBufferMeters() float64 {
+	if m != nil {
+		return m.BufferMeters
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetComputeIQR() bool {
+	if m != nil {
+		return m.ComputeIQR
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetComputeMAD() bool {
+	if m != nil {
+		return m.ComputeMAD
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetComputeSum() bool {
+	if m != nil {
+		return m.ComputeSum
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetSumByArea() bool {
+	if m != nil {
+		return m.SumByArea
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetOpenOptions() []string {
+	if m != nil {
+		return m.OpenOptions
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetConfigOptions() []string {
+	if m != nil {
+		return m.ConfigOptions
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetAllowedDrivers() []string {
+	if m != nil {
+		return m.AllowedDrivers
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetWeightBand() int32 {
+	if m != nil {
+		return m.WeightBand
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetComputeStdError() bool {
+	if m != nil {
+		return m.ComputeStdError
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetAssetHrefs() []string {
+	if m != nil {
+		return m.AssetHrefs
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetMetadataOnly() bool {
+	if m != nil {
+		return m.MetadataOnly
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetConcurrentReads() int32 {
+	if m != nil {
+		return m.ConcurrentReads
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetComputeCV() bool {
+	if m != nil {
+		return m.ComputeCV
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetPixelCenterMask() bool {
+	if m != nil {
+		return m.PixelCenterMask
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetMaskBand() int32 {
+	if m != nil {
+		return m.MaskBand
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetMaskPath() string {
+	if m != nil {
+		return m.MaskPath
+	}
+	return ""
+}
+
+func (m *GeoRPCGranule) GetMaxDrillMemory() int64 {
+	if m != nil {
+		return m.MaxDrillMemory
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetComputeNoDataFraction() bool {
+	if m != nil {
+		return m.ComputeNoDataFraction
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetBufferSegments() int32 {
+	if m != nil {
+		return m.BufferSegments
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetComputeMoments() bool {
+	if m != nil {
+		return m.ComputeMoments
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetSubdataset() string {
+	if m != nil {
+		return m.Subdataset
+	}
+	return ""
+}
+
+func (m *GeoRPCGranule) GetMinValidPixels() int32 {
+	if m != nil {
+		return m.MinValidPixels
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetMinValidFraction() float64 {
+	if m != nil {
+		return m.MinValidFraction
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetCoordinateOperation() string {
+	if m != nil {
+		return m.CoordinateOperation
+	}
+	return ""
+}
+
+func (m *GeoRPCGranule) GetAreaOfInterest() []float64 {
+	if m != nil {
+		return m.AreaOfInterest
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetComplexPart() ComplexPart {
+	if m != nil {
+		return m.ComplexPart
+	}
+	return ComplexPart_AMPLITUDE
+}
+
+func (m *GeoRPCGranule) GetZoneIDs() []int32 {
+	if m != nil {
+		return m.ZoneIDs
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetCompensatedSum() bool {
+	if m != nil {
+		return m.CompensatedSum
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetDebugChecksum() bool {
+	if m != nil {
+		return m.DebugChecksum
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetWarpSRS() string {
+	if m != nil {
+		return m.WarpSRS
+	}
+	return ""
+}
+
+func (m *GeoRPCGranule) GetWarpResolution() []float64 {
+	if m != nil {
+		return m.WarpResolution
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetWarpResampling() Resampling {
+	if m != nil {
+		return m.WarpResampling
+	}
+	return Resampling_NEAREST
+}
+
+func (m *GeoRPCGranule) GetInteriorDeciles() bool {
+	if m != nil {
+		return m.InteriorDeciles
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetStats() uint32 {
+	if m != nil {
+		return m.Stats
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetClipUpperPerBand() []float32 {
+	if m != nil {
+		return m.ClipUpperPerBand
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetClipLowerPerBand() []float32 {
+	if m != nil {
+		return m.ClipLowerPerBand
+	}
+	return nil
+}
+
+func (m *GeoRPCGranule) GetStream() bool {
+	if m != nil {
+		return m.Stream
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetSigmaClip() float64 {
+	if m != nil {
+		return m.SigmaClip
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetSigmaIterations() int32 {
+	if m != nil {
+		return m.SigmaIterations
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetCompressTimeSeries() bool {
+	if m != nil {
+		return m.CompressTimeSeries
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetOverviewLevel() int32 {
+	if m != nil {
+		return m.OverviewLevel
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetMaxBands() int32 {
+	if m != nil {
+		return m.MaxBands
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetUseRAT() bool {
+	if m != nil {
+		return m.UseRAT
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetSmoothWindow() int32 {
+	if m != nil {
+		return m.SmoothWindow
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetMaskMin() float64 {
+	if m != nil {
+		return m.MaskMin
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetMaskMax() float64 {
+	if m != nil {
+		return m.MaskMax
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetApproxQuantiles() bool {
+	if m != nil {
+		return m.ApproxQuantiles
+	}
+	return false
+}
+
+func (m *GeoRPCGranule) GetMaskType() MaskType {
+	if m != nil {
+		return m.MaskType
+	}
+	return MaskType_MASK_BYTE
+}
+
+func (m *GeoRPCGranule) GetMaskBurnValue() float64 {
+	if m != nil {
+		return m.MaskBurnValue
+	}
+	return 0
+}
+
+func (m *GeoRPCGranule) GetNoDataRelTolerance() float32 {
+	if m != nil {
+		return m.NoDataRelTolerance
+	}
+	return 0
+}
+
+type Raster struct {
+	Data         []byte    `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	NoData       float64   `protobuf:"fixed64,2,opt,name=noData" json:"noData,omitempty"`
+	RasterType   string    `protobuf:"bytes,3,opt,name=rasterType" json:"rasterType,omitempty"`
+	Bbox         []int32   `protobuf:"varint,4,rep,packed,name=bbox" json:"bbox,omitempty"`
+	GeoTransform []float64 `protobuf:"fixed64,5,rep,packed,name=geoTransform" json:"geoTransform,omitempty"`
+	Projection   string    `protobuf:"bytes,6,opt,name=projection" json:"projection,omitempty"`
+}
+
+func (m *Raster) Reset()                    { *m = Raster{} }
+func (m *Raster) String() string            { return proto.CompactTextString(m) }
+func (*Raster) ProtoMessage()               {}
+func (*Raster) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Raster) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Raster) GetNoData() float64 {
+	if m != nil {
+		return m.NoData
+	}
+	return 0
+}
+
+func (m *Raster) GetRasterType() string {
+	if m != nil {
+		return m.RasterType
+	}
+	return ""
+}
+
+func (m *Raster) GetBbox() []int32 {
+	if m != nil {
+		return m.Bbox
+	}
+	return nil
+}
+
+func (m *Raster) GetGeoTransform() []float64 {
+	if m != nil {
+		return m.GeoTransform
+	}
+	return nil
+}
+
+func (m *Raster) GetProjection() string {
+	if m != nil {
+		return m.Projection
+	}
+	return ""
+}
+
+type TimeSeries struct {
+	Value              float64 `protobuf:"fixed64,1,opt,name=value" json:"value,omitempty"`
+	Count              int32   `protobuf:"varint,2,opt,name=count" json:"count,omitempty"`
+	Time               int64   `protobuf:"varint,3,opt,name=time" json:"time,omitempty"`
+	ClippedCount       int32   `protobuf:"varint,4,opt,name=clippedCount" json:"clippedCount,omitempty"`
+	ValidCount         int64   `protobuf:"varint,5,opt,name=validCount" json:"validCount,omitempty"`
+	TotalMaskedCount   int64   `protobuf:"varint,6,opt,name=totalMaskedCount" json:"totalMaskedCount,omitempty"`
+	SigmaRejectedCount int64   `protobuf:"varint,7,opt,name=sigmaRejectedCount" json:"sigmaRejectedCount,omitempty"`
+}
+
+func (m *TimeSeries) Reset()                    { *m = TimeSeries{} }
+func (m *TimeSeries) String() string            { return proto.CompactTextString(m) }
+func (*TimeSeries) ProtoMessage()               {}
+func (*TimeSeries) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *TimeSeries) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *TimeSeries) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *TimeSeries) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *TimeSeries) GetClippedCount() int32 {
+	if m != nil {
+		return m.ClippedCount
+	}
+	return 0
+}
+
+func (m *TimeSeries) GetValidCount() int64 {
+	if m != nil {
+		return m.ValidCount
+	}
+	return 0
+}
+
+func (m *TimeSeries) GetTotalMaskedCount() int64 {
+	if m != nil {
+		return m.TotalMaskedCount
+	}
+	return 0
+}
+
+func (m *TimeSeries) GetSigmaRejectedCount() int64 {
+	if m != nil {
+		return m.SigmaRejectedCount
+	}
+	return 0
+}
+
+type BandPixels struct {
+	Band  int32     `protobuf:"varint,1,opt,name=band" json:"band,omitempty"`
+	Index []int32   `protobuf:"varint,2,rep,packed,name=index" json:"index,omitempty"`
+	Value []float32 `protobuf:"fixed32,3,rep,packed,name=value" json:"value,omitempty"`
+}
+
+func (m *BandPixels) Reset()                    { *m = BandPixels{} }
+func (m *BandPixels) String() string            { return proto.CompactTextString(m) }
+func (*BandPixels) ProtoMessage()               {}
+func (*BandPixels) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *BandPixels) GetBand() int32 {
+	if m != nil {
+		return m.Band
+	}
+	return 0
+}
+
+func (m *BandPixels) GetIndex() []int32 {
+	if m != nil {
+		return m.Index
+	}
+	return nil
+}
+
+func (m *BandPixels) GetValue() []float32 {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type Histogram struct {
+	Band   int32     `protobuf:"varint,1,opt,name=band" json:"band,omitempty"`
+	Edges  []float64 `protobuf:"fixed64,2,rep,packed,name=edges" json:"edges,omitempty"`
+	Counts []int64   `protobuf:"varint,3,rep,packed,name=counts" json:"counts,omitempty"`
+}
+
+func (m *Histogram) Reset()                    { *m = Histogram{} }
+func (m *Histogram) String() string            { return proto.CompactTextString(m) }
+func (*Histogram) ProtoMessage()               {}
+func (*Histogram) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *Histogram) GetBand() int32 {
 	if m != nil {
-		return m.BandStrides
+		return m.Band
 	}
 	return 0
 }
 
-func (m *GeoRPCGranule) GetGeoLocOpts() []string {
+func (m *Histogram) GetEdges() []float64 {
 	if m != nil {
-		return m.GeoLocOpts
+		return m.Edges
 	}
 	return nil
 }
 
-func (m *GeoRPCGranule) GetDrillDecileCount() int32 {
+func (m *Histogram) GetCounts() []int64 {
 	if m != nil {
-		return m.DrillDecileCount
+		return m.Counts
 	}
-	return 0
+	return nil
 }
 
-func (m *GeoRPCGranule) GetClipUpper() float32 {
-	if m != nil {
-		return m.ClipUpper
-	}
-	return 0
+type ClassFractions struct {
+	Band            int32     `protobuf:"varint,1,opt,name=band" json:"band,omitempty"`
+	Classes         []int32   `protobuf:"varint,2,rep,packed,name=classes" json:"classes,omitempty"`
+	Fractions       []float64 `protobuf:"fixed64,3,rep,packed,name=fractions" json:"fractions,omitempty"`
+	Counts          []int64   `protobuf:"varint,4,rep,packed,name=counts" json:"counts,omitempty"`
+	Majority        int32     `protobuf:"varint,5,opt,name=majority" json:"majority,omitempty"`
+	Minority        int32     `protobuf:"varint,6,opt,name=minority" json:"minority,omitempty"`
+	DistinctClasses int32     `protobuf:"varint,7,opt,name=distinctClasses" json:"distinctClasses,omitempty"`
+	Labels          []string  `protobuf:"bytes,8,rep,name=labels" json:"labels,omitempty"`
 }
 
-func (m *GeoRPCGranule) GetClipLower() float32 {
+func (m *ClassFractions) Reset()                    { *m = ClassFractions{} }
+func (m *ClassFractions) String() string            { return proto.CompactTextString(m) }
+func (*ClassFractions) ProtoMessage()               {}
+func (*ClassFractions) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *ClassFractions) GetBand() int32 {
 	if m != nil {
-		return m.ClipLower
+		return m.Band
 	}
 	return 0
 }
 
-func (m *GeoRPCGranule) GetSRSCf() int32 {
+func (m *ClassFractions) GetClasses() []int32 {
 	if m != nil {
-		return m.SRSCf
+		return m.Classes
 	}
-	return 0
+	return nil
 }
 
-func (m *GeoRPCGranule) GetPixelCount() int32 {
+func (m *ClassFractions) GetFractions() []float64 {
 	if m != nil {
-		return m.PixelCount
+		return m.Fractions
 	}
-	return 0
+	return nil
 }
 
-func (m *GeoRPCGranule) GetVRT() string {
+func (m *ClassFractions) GetCounts() []int64 {
 	if m != nil {
-		return m.VRT
+		return m.Counts
 	}
-	return ""
-}
-
-type Raster struct {
-	Data       []byte  `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
-	NoData     float64 `protobuf:"fixed64,2,opt,name=noData" json:"noData,omitempty"`
-	RasterType string  `protobuf:"bytes,3,opt,name=rasterType" json:"rasterType,omitempty"`
-	Bbox       []int32 `protobuf:"varint,4,rep,packed,name=bbox" json:"bbox,omitempty"`
+	return nil
 }
 
-func (m *Raster) Reset()                    { *m = Raster{} }
-func (m *Raster) String() string            { return proto.CompactTextString(m) }
-func (*Raster) ProtoMessage()               {}
-func (*Raster) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
-
-func (m *Raster) GetData() []byte {
+func (m *ClassFractions) GetMajority() int32 {
 	if m != nil {
-		return m.Data
+		return m.Majority
 	}
-	return nil
+	return 0
 }
 
-func (m *Raster) GetNoData() float64 {
+func (m *ClassFractions) GetMinority() int32 {
 	if m != nil {
-		return m.NoData
+		return m.Minority
 	}
 	return 0
 }
 
-func (m *Raster) GetRasterType() string {
+func (m *ClassFractions) GetDistinctClasses() int32 {
 	if m != nil {
-		return m.RasterType
+		return m.DistinctClasses
 	}
-	return ""
+	return 0
 }
 
-func (m *Raster) GetBbox() []int32 {
+func (m *ClassFractions) GetLabels() []string {
 	if m != nil {
-		return m.Bbox
+		return m.Labels
 	}
 	return nil
 }
 
-type TimeSeries struct {
-	Value float64 `protobuf:"fixed64,1,opt,name=value" json:"value,omitempty"`
-	Count int32   `protobuf:"varint,2,opt,name=count" json:"count,omitempty"`
+type BandChecksum struct {
+	Band     int32  `protobuf:"varint,1,opt,name=band" json:"band,omitempty"`
+	Checksum uint64 `protobuf:"varint,2,opt,name=checksum" json:"checksum,omitempty"`
+	Count    int64  `protobuf:"varint,3,opt,name=count" json:"count,omitempty"`
 }
 
-func (m *TimeSeries) Reset()                    { *m = TimeSeries{} }
-func (m *TimeSeries) String() string            { return proto.CompactTextString(m) }
-func (*TimeSeries) ProtoMessage()               {}
-func (*TimeSeries) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (m *BandChecksum) Reset()                    { *m = BandChecksum{} }
+func (m *BandChecksum) String() string            { return proto.CompactTextString(m) }
+func (*BandChecksum) ProtoMessage()               {}
+func (*BandChecksum) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
 
-func (m *TimeSeries) GetValue() float64 {
+func (m *BandChecksum) GetBand() int32 {
 	if m != nil {
-		return m.Value
+		return m.Band
 	}
 	return 0
 }
 
-func (m *TimeSeries) GetCount() int32 {
+func (m *BandChecksum) GetChecksum() uint64 {
+	if m != nil {
+		return m.Checksum
+	}
+	return 0
+}
+
+func (m *BandChecksum) GetCount() int64 {
 	if m != nil {
 		return m.Count
 	}
@@ -265,7 +1300,7 @@ type Overview struct {
 func (m *Overview) Reset()                    { *m = Overview{} }
 func (m *Overview) String() string            { return proto.CompactTextString(m) }
 func (*Overview) ProtoMessage()               {}
-func (*Overview) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*Overview) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *Overview) GetXSize() int32 {
 	if m != nil {
@@ -300,7 +1335,7 @@ type GeoMetaData struct {
 func (m *GeoMetaData) Reset()                    { *m = GeoMetaData{} }
 func (m *GeoMetaData) String() string            { return proto.CompactTextString(m) }
 func (*GeoMetaData) ProtoMessage()               {}
-func (*GeoMetaData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*GeoMetaData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *GeoMetaData) GetDatasetName() string {
 	if m != nil {
@@ -402,7 +1437,7 @@ type GeoFile struct {
 func (m *GeoFile) Reset()                    { *m = GeoFile{} }
 func (m *GeoFile) String() string            { return proto.CompactTextString(m) }
 func (*GeoFile) ProtoMessage()               {}
-func (*GeoFile) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*GeoFile) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 func (m *GeoFile) GetFileName() string {
 	if m != nil {
@@ -432,7 +1467,7 @@ type WorkerInfo struct {
 func (m *WorkerInfo) Reset()                    { *m = WorkerInfo{} }
 func (m *WorkerInfo) String() string            { return proto.CompactTextString(m) }
 func (*WorkerInfo) ProtoMessage()               {}
-func (*WorkerInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*WorkerInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *WorkerInfo) GetPoolSize() int32 {
 	if m != nil {
@@ -442,15 +1477,26 @@ func (m *WorkerInfo) GetPoolSize() int32 {
 }
 
 type WorkerMetrics struct {
-	BytesRead int64 `protobuf:"varint,1,opt,name=bytesRead" json:"bytesRead,omitempty"`
-	UserTime  int64 `protobuf:"varint,2,opt,name=userTime" json:"userTime,omitempty"`
-	SysTime   int64 `protobuf:"varint,3,opt,name=sysTime" json:"sysTime,omitempty"`
+	BytesRead              int64   `protobuf:"varint,1,opt,name=bytesRead" json:"bytesRead,omitempty"`
+	UserTime               int64   `protobuf:"varint,2,opt,name=userTime" json:"userTime,omitempty"`
+	SysTime                int64   `protobuf:"varint,3,opt,name=sysTime" json:"sysTime,omitempty"`
+	ValidPixels            int64   `protobuf:"varint,4,opt,name=validPixels" json:"validPixels,omitempty"`
+	MaskedPixels           int64   `protobuf:"varint,5,opt,name=maskedPixels" json:"maskedPixels,omitempty"`
+	InterpolationChecks    int32   `protobuf:"varint,6,opt,name=interpolationChecks" json:"interpolationChecks,omitempty"`
+	InterpolationMaxError  float64 `protobuf:"fixed64,7,opt,name=interpolationMaxError" json:"interpolationMaxError,omitempty"`
+	InterpolationMeanError float64 `protobuf:"fixed64,8,opt,name=interpolationMeanError" json:"interpolationMeanError,omitempty"`
+	WallTime               int64   `protobuf:"varint,9,opt,name=wallTime" json:"wallTime,omitempty"`
+	CacheUsed              int64   `protobuf:"varint,10,opt,name=cacheUsed" json:"cacheUsed,omitempty"`
+	CacheMax               int64   `protobuf:"varint,11,opt,name=cacheMax" json:"cacheMax,omitempty"`
+	MaskCacheHits          int64   `protobuf:"varint,12,opt,name=maskCacheHits" json:"maskCacheHits,omitempty"`
+	RasterIOTime           int64   `protobuf:"varint,13,opt,name=rasterIOTime" json:"rasterIOTime,omitempty"`
+	ReduceTime             int64   `protobuf:"varint,14,opt,name=reduceTime" json:"reduceTime,omitempty"`
 }
 
 func (m *WorkerMetrics) Reset()                    { *m = WorkerMetrics{} }
 func (m *WorkerMetrics) String() string            { return proto.CompactTextString(m) }
 func (*WorkerMetrics) ProtoMessage()               {}
-func (*WorkerMetrics) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*WorkerMetrics) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 func (m *WorkerMetrics) GetBytesRead() int64 {
 	if m != nil {
@@ -473,20 +1519,161 @@ func (m *WorkerMetrics) GetSysTime() int64 {
 	return 0
 }
 
+func (m *WorkerMetrics) GetValidPixels() int64 {
+	if m != nil {
+		return m.ValidPixels
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetMaskedPixels() int64 {
+	if m != nil {
+		return m.MaskedPixels
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetInterpolationChecks() int32 {
+	if m != nil {
+		return m.InterpolationChecks
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetInterpolationMaxError() float64 {
+	if m != nil {
+		return m.InterpolationMaxError
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetInterpolationMeanError() float64 {
+	if m != nil {
+		return m.InterpolationMeanError
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetWallTime() int64 {
+	if m != nil {
+		return m.WallTime
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetCacheUsed() int64 {
+	if m != nil {
+		return m.CacheUsed
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetCacheMax() int64 {
+	if m != nil {
+		return m.CacheMax
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetMaskCacheHits() int64 {
+	if m != nil {
+		return m.MaskCacheHits
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetRasterIOTime() int64 {
+	if m != nil {
+		return m.RasterIOTime
+	}
+	return 0
+}
+
+func (m *WorkerMetrics) GetReduceTime() int64 {
+	if m != nil {
+		return m.ReduceTime
+	}
+	return 0
+}
+
+type Window struct {
+	OffX         int32 `protobuf:"varint,1,opt,name=offX" json:"offX,omitempty"`
+	OffY         int32 `protobuf:"varint,2,opt,name=offY" json:"offY,omitempty"`
+	CountX       int32 `protobuf:"varint,3,opt,name=countX" json:"countX,omitempty"`
+	CountY       int32 `protobuf:"varint,4,opt,name=countY" json:"countY,omitempty"`
+	MaskedPixels int64 `protobuf:"varint,5,opt,name=maskedPixels" json:"maskedPixels,omitempty"`
+}
+
+func (m *Window) Reset()                    { *m = Window{} }
+func (m *Window) String() string            { return proto.CompactTextString(m) }
+func (*Window) ProtoMessage()               {}
+func (*Window) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *Window) GetOffX() int32 {
+	if m != nil {
+		return m.OffX
+	}
+	return 0
+}
+
+func (m *Window) GetOffY() int32 {
+	if m != nil {
+		return m.OffY
+	}
+	return 0
+}
+
+func (m *Window) GetCountX() int32 {
+	if m != nil {
+		return m.CountX
+	}
+	return 0
+}
+
+func (m *Window) GetCountY() int32 {
+	if m != nil {
+		return m.CountY
+	}
+	return 0
+}
+
+func (m *Window) GetMaskedPixels() int64 {
+	if m != nil {
+		return m.MaskedPixels
+	}
+	return 0
+}
+
 type Result struct {
-	TimeSeries []*TimeSeries  `protobuf:"bytes,1,rep,name=timeSeries" json:"timeSeries,omitempty"`
-	Raster     *Raster        `protobuf:"bytes,2,opt,name=raster" json:"raster,omitempty"`
-	Info       *GeoFile       `protobuf:"bytes,3,opt,name=info" json:"info,omitempty"`
-	Error      string         `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
-	Shape      []int32        `protobuf:"varint,5,rep,packed,name=shape" json:"shape,omitempty"`
-	WorkerInfo *WorkerInfo    `protobuf:"bytes,6,opt,name=workerInfo" json:"workerInfo,omitempty"`
-	Metrics    *WorkerMetrics `protobuf:"bytes,7,opt,name=metrics" json:"metrics,omitempty"`
+	TimeSeries           []*TimeSeries     `protobuf:"bytes,1,rep,name=timeSeries" json:"timeSeries,omitempty"`
+	Raster               *Raster           `protobuf:"bytes,2,opt,name=raster" json:"raster,omitempty"`
+	Info                 *GeoFile          `protobuf:"bytes,3,opt,name=info" json:"info,omitempty"`
+	Error                string            `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+	Shape                []int32           `protobuf:"varint,5,rep,packed,name=shape" json:"shape,omitempty"`
+	WorkerInfo           *WorkerInfo       `protobuf:"bytes,6,opt,name=workerInfo" json:"workerInfo,omitempty"`
+	Metrics              *WorkerMetrics    `protobuf:"bytes,7,opt,name=metrics" json:"metrics,omitempty"`
+	OverviewLevel        int32             `protobuf:"varint,8,opt,name=overviewLevel" json:"overviewLevel,omitempty"`
+	Resolution           []float64         `protobuf:"fixed64,9,rep,packed,name=resolution" json:"resolution,omitempty"`
+	Pixels               []*BandPixels     `protobuf:"bytes,10,rep,name=pixels" json:"pixels,omitempty"`
+	Histograms           []*Histogram      `protobuf:"bytes,11,rep,name=histograms" json:"histograms,omitempty"`
+	ClassFractions       []*ClassFractions `protobuf:"bytes,12,rep,name=classFractions" json:"classFractions,omitempty"`
+	PixelArea            float64           `protobuf:"fixed64,13,opt,name=pixelArea" json:"pixelArea,omitempty"`
+	Window               *Window           `protobuf:"bytes,14,opt,name=window" json:"window,omitempty"`
+	Warnings             []string          `protobuf:"bytes,15,rep,name=warnings" json:"warnings,omitempty"`
+	ZoneIDs              []int32           `protobuf:"varint,16,rep,packed,name=zoneIDs" json:"zoneIDs,omitempty"`
+	Checksums            []*BandChecksum   `protobuf:"bytes,17,rep,name=checksums" json:"checksums,omitempty"`
+	GeometryArea         float64           `protobuf:"fixed64,18,opt,name=geometryArea" json:"geometryArea,omitempty"`
+	GeometryAreaM2       float64           `protobuf:"fixed64,19,opt,name=geometryAreaM2" json:"geometryAreaM2,omitempty"`
+	PixelAreaM2          float64           `protobuf:"fixed64,20,opt,name=pixelAreaM2" json:"pixelAreaM2,omitempty"`
+	BandTimes            []int64           `protobuf:"varint,21,rep,packed,name=bandTimes" json:"bandTimes,omitempty"`
+	CompressedTimeSeries []byte            `protobuf:"bytes,22,opt,name=compressedTimeSeries,proto3" json:"compressedTimeSeries,omitempty"`
+	Unit                 string            `protobuf:"bytes,23,opt,name=unit" json:"unit,omitempty"`
 }
 
 func (m *Result) Reset()                    { *m = Result{} }
 func (m *Result) String() string            { return proto.CompactTextString(m) }
 func (*Result) ProtoMessage()               {}
-func (*Result) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*Result) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 func (m *Result) GetTimeSeries() []*TimeSeries {
 	if m != nil {
@@ -537,16 +1724,139 @@ func (m *Result) GetMetrics() *WorkerMetrics {
 	return nil
 }
 
+func (m *Result) GetOverviewLevel() int32 {
+	if m != nil {
+		return m.OverviewLevel
+	}
+	return 0
+}
+
+func (m *Result) GetResolution() []float64 {
+	if m != nil {
+		return m.Resolution
+	}
+	return nil
+}
+
+func (m *Result) GetPixels() []*BandPixels {
+	if m != nil {
+		return m.Pixels
+	}
+	return nil
+}
+
+func (m *Result) GetHistograms() []*Histogram {
+	if m != nil {
+		return m.Histograms
+	}
+	return nil
+}
+
+func (m *Result) GetClassFractions() []*ClassFractions {
+	if m != nil {
+		return m.ClassFractions
+	}
+	return nil
+}
+
+func (m *Result) GetPixelArea() float64 {
+	if m != nil {
+		return m.PixelArea
+	}
+	return 0
+}
+
+func (m *Result) GetWindow() *Window {
+	if m != nil {
+		return m.Window
+	}
+	return nil
+}
+
+func (m *Result) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+func (m *Result) GetZoneIDs() []int32 {
+	if m != nil {
+		return m.ZoneIDs
+	}
+	return nil
+}
+
+func (m *Result) GetChecksums() []*BandChecksum {
+	if m != nil {
+		return m.Checksums
+	}
+	return nil
+}
+
+func (m *Result) GetGeometryArea() float64 {
+	if m != nil {
+		return m.GeometryArea
+	}
+	return 0
+}
+
+func (m *Result) GetGeometryAreaM2() float64 {
+	if m != nil {
+		return m.GeometryAreaM2
+	}
+	return 0
+}
+
+func (m *Result) GetPixelAreaM2() float64 {
+	if m != nil {
+		return m.PixelAreaM2
+	}
+	return 0
+}
+
+func (m *Result) GetBandTimes() []int64 {
+	if m != nil {
+		return m.BandTimes
+	}
+	return nil
+}
+
+func (m *Result) GetCompressedTimeSeries() []byte {
+	if m != nil {
+		return m.CompressedTimeSeries
+	}
+	return nil
+}
+
+func (m *Result) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*GeoRPCGranule)(nil), "gdalservice.GeoRPCGranule")
 	proto.RegisterType((*Raster)(nil), "gdalservice.Raster")
 	proto.RegisterType((*TimeSeries)(nil), "gdalservice.TimeSeries")
+	proto.RegisterType((*BandPixels)(nil), "gdalservice.BandPixels")
+	proto.RegisterType((*Histogram)(nil), "gdalservice.Histogram")
+	proto.RegisterType((*ClassFractions)(nil), "gdalservice.ClassFractions")
+	proto.RegisterType((*BandChecksum)(nil), "gdalservice.BandChecksum")
 	proto.RegisterType((*Overview)(nil), "gdalservice.Overview")
 	proto.RegisterType((*GeoMetaData)(nil), "gdalservice.GeoMetaData")
 	proto.RegisterType((*GeoFile)(nil), "gdalservice.GeoFile")
 	proto.RegisterType((*WorkerInfo)(nil), "gdalservice.WorkerInfo")
 	proto.RegisterType((*WorkerMetrics)(nil), "gdalservice.WorkerMetrics")
+	proto.RegisterType((*Window)(nil), "gdalservice.Window")
 	proto.RegisterType((*Result)(nil), "gdalservice.Result")
+	proto.RegisterEnum("gdalservice.Aggregation", Aggregation_name, Aggregation_value)
+	proto.RegisterEnum("gdalservice.Interpolation", Interpolation_name, Interpolation_value)
+	proto.RegisterEnum("gdalservice.Resampling", Resampling_name, Resampling_value)
+	proto.RegisterEnum("gdalservice.ComplexPart", ComplexPart_name, ComplexPart_value)
+	proto.RegisterEnum("gdalservice.Statistic", Statistic_name, Statistic_value)
+	proto.RegisterEnum("gdalservice.MaskType", MaskType_name, MaskType_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -561,6 +1871,7 @@ const _ = grpc.SupportPackageIsVersion4
 
 type GDALClient interface {
 	Process(ctx context.Context, in *GeoRPCGranule, opts ...grpc.CallOption) (*Result, error)
+	DrillStream(ctx context.Context, in *GeoRPCGranule, opts ...grpc.CallOption) (GDAL_DrillStreamClient, error)
 }
 
 type gDALClient struct {
@@ -580,10 +1891,43 @@ func (c *gDALClient) Process(ctx context.Context, in *GeoRPCGranule, opts ...grp
 	return out, nil
 }
 
+func (c *gDALClient) DrillStream(ctx context.Context, in *GeoRPCGranule, opts ...grpc.CallOption) (GDAL_DrillStreamClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GDAL_serviceDesc.Streams[0], c.cc, "/gdalservice.GDAL/DrillStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gDALDrillStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GDAL_DrillStreamClient interface {
+	Recv() (*Result, error)
+	grpc.ClientStream
+}
+
+type gDALDrillStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *gDALDrillStreamClient) Recv() (*Result, error) {
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for GDAL service
 
 type GDALServer interface {
 	Process(context.Context, *GeoRPCGranule) (*Result, error)
+	DrillStream(*GeoRPCGranule, GDAL_DrillStreamServer) error
 }
 
 func RegisterGDALServer(s *grpc.Server, srv GDALServer) {
@@ -608,6 +1952,27 @@ func _GDAL_Process_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GDAL_DrillStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GeoRPCGranule)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GDALServer).DrillStream(m, &gDALDrillStreamServer{stream})
+}
+
+type GDAL_DrillStreamServer interface {
+	Send(*Result) error
+	grpc.ServerStream
+}
+
+type gDALDrillStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *gDALDrillStreamServer) Send(m *Result) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _GDAL_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "gdalservice.GDAL",
 	HandlerType: (*GDALServer)(nil),
@@ -617,68 +1982,205 @@ var _GDAL_serviceDesc = grpc.ServiceDesc{
 			Handler:    _GDAL_Process_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DrillStream",
+			Handler:       _GDAL_DrillStream_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "gdalservice.proto",
 }
 
 func init() { proto.RegisterFile("gdalservice.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 882 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x55, 0xdf, 0x8f, 0xe3, 0x34,
-	0x10, 0x56, 0x37, 0xfd, 0xe9, 0xee, 0xc2, 0x9e, 0x39, 0xc0, 0xaa, 0x10, 0x44, 0x79, 0x8a, 0x40,
-	0xea, 0x49, 0x7b, 0x2b, 0x40, 0xf7, 0x06, 0xbb, 0xa2, 0x42, 0xec, 0x71, 0x27, 0xa7, 0xe8, 0x9e,
-	0xd3, 0x64, 0xda, 0x1a, 0xd2, 0x38, 0xb2, 0xdd, 0xee, 0x96, 0x3f, 0x88, 0x7f, 0x80, 0x3f, 0x8e,
-	0x57, 0x34, 0xe3, 0xa4, 0xc9, 0xee, 0xde, 0x5b, 0xbe, 0xcf, 0x33, 0xf6, 0xf8, 0x9b, 0xf9, 0x1c,
-	0xf6, 0x62, 0x93, 0xa7, 0x85, 0x05, 0x73, 0x50, 0x19, 0xcc, 0x2b, 0xa3, 0x9d, 0xe6, 0xd3, 0x0e,
-	0x35, 0xfb, 0x66, 0xa3, 0xf5, 0xa6, 0x80, 0x57, 0xb4, 0xb4, 0xda, 0xaf, 0x5f, 0x39, 0xb5, 0x03,
-	0xeb, 0xd2, 0x5d, 0xe5, 0xa3, 0xa3, 0xff, 0x02, 0x76, 0xb1, 0x00, 0x2d, 0xdf, 0xdf, 0x2c, 0x4c,
-	0x5a, 0xee, 0x0b, 0xe0, 0x5f, 0xb1, 0x89, 0xae, 0xc0, 0xa4, 0x4e, 0xe9, 0x52, 0xf4, 0xc2, 0x5e,
-	0x3c, 0x91, 0x2d, 0xc1, 0x39, 0xeb, 0x57, 0xa9, 0xdb, 0x8a, 0x33, 0x5a, 0xa0, 0x6f, 0x3e, 0x63,
-	0xe3, 0x0d, 0xe8, 0x1d, 0x38, 0x73, 0x14, 0x01, 0xf1, 0x27, 0xcc, 0x5f, 0xb2, 0xc1, 0x2a, 0x2d,
-	0x73, 0x2b, 0xfa, 0x61, 0x10, 0x0f, 0xa4, 0x07, 0xfc, 0x0b, 0x36, 0xdc, 0x82, 0xda, 0x6c, 0x9d,
-	0x18, 0x84, 0xbd, 0x78, 0x20, 0x6b, 0x84, 0xd1, 0xf7, 0x2a, 0x77, 0x5b, 0x31, 0x24, 0xda, 0x03,
-	0x8c, 0xb6, 0x26, 0x4b, 0x64, 0x22, 0x46, 0xb4, 0x7b, 0x8d, 0xb8, 0x60, 0x23, 0x6b, 0xb2, 0x05,
-	0x68, 0x27, 0xc6, 0x61, 0x10, 0xf7, 0x64, 0x03, 0x31, 0x23, 0xb7, 0x0e, 0x33, 0x26, 0x3e, 0xc3,
-	0x23, 0xcc, 0xc8, 0xad, 0xa3, 0x0c, 0xe6, 0x33, 0x6a, 0xc8, 0x43, 0x36, 0xc5, 0xd2, 0x12, 0x67,
-	0x54, 0x0e, 0x56, 0x4c, 0xe9, 0xfc, 0x2e, 0xc5, 0xbf, 0x66, 0x6c, 0x03, 0xfa, 0x4e, 0x67, 0xef,
-	0x2a, 0x67, 0xc5, 0x79, 0x18, 0xc4, 0x13, 0xd9, 0x61, 0xf8, 0xb7, 0xec, 0x32, 0x37, 0xaa, 0x28,
-	0x6e, 0x21, 0x53, 0x05, 0xdc, 0xe8, 0x7d, 0xe9, 0xc4, 0x05, 0x6d, 0xf3, 0x8c, 0x47, 0x8d, 0xb3,
-	0x42, 0x55, 0x7f, 0x54, 0x15, 0x18, 0xf1, 0x49, 0xd8, 0x8b, 0xcf, 0x64, 0x4b, 0x34, 0xab, 0x77,
-	0xfa, 0x1e, 0x8c, 0xf8, 0xb4, 0x5d, 0x25, 0x02, 0x35, 0xb2, 0x32, 0xb9, 0x59, 0x8b, 0x4b, 0xaf,
-	0x11, 0x01, 0xac, 0xae, 0x52, 0x0f, 0x50, 0xf8, 0x73, 0x5f, 0xd0, 0x52, 0x87, 0xe1, 0x97, 0x2c,
-	0x38, 0xc8, 0xa5, 0xe0, 0x24, 0x07, 0x7e, 0x46, 0x5b, 0x36, 0x94, 0xa9, 0x75, 0x60, 0xb0, 0xa7,
-	0x79, 0xea, 0x52, 0x6a, 0xf6, 0xb9, 0xa4, 0x6f, 0x54, 0xb0, 0xd4, 0xb7, 0xc8, 0x62, 0xa7, 0x7b,
-	0xb2, 0x46, 0x78, 0x8e, 0xa1, 0xac, 0xe5, 0xb1, 0x82, 0xba, 0xdb, 0x1d, 0x06, 0xf7, 0x5a, 0xad,
-	0xf4, 0x43, 0xdd, 0x6e, 0xfa, 0x8e, 0x7e, 0x64, 0x6c, 0xa9, 0x76, 0x90, 0x80, 0x51, 0x60, 0xb1,
-	0xfe, 0x43, 0x5a, 0xec, 0x81, 0x8e, 0xeb, 0x49, 0x0f, 0x90, 0xcd, 0xa8, 0xf4, 0x33, 0x7f, 0x2b,
-	0x02, 0xd1, 0xf7, 0x6c, 0xfc, 0xee, 0x80, 0xa3, 0x0c, 0xf7, 0x18, 0xf1, 0x90, 0xa8, 0xbf, 0x7d,
-	0xde, 0x40, 0x7a, 0x80, 0xec, 0x91, 0xd8, 0x3a, 0x8f, 0x40, 0xf4, 0x4f, 0xc0, 0xa6, 0x0b, 0xd0,
-	0x6f, 0xc1, 0xa5, 0x54, 0x75, 0xc8, 0xa6, 0x78, 0x2b, 0x0b, 0xee, 0xf7, 0x74, 0x07, 0xf5, 0x54,
-	0x77, 0x29, 0xd4, 0xbc, 0x4c, 0x77, 0x90, 0x54, 0x69, 0x06, 0xf5, 0x70, 0xb7, 0x04, 0xde, 0xca,
-	0xb5, 0xf7, 0xa5, 0x6f, 0xdc, 0xd3, 0xdf, 0xdb, 0x4b, 0xde, 0xf7, 0x13, 0xd3, 0xa1, 0xf8, 0x1b,
-	0xc6, 0xd0, 0x6e, 0x09, 0xda, 0xcd, 0x8a, 0x41, 0x18, 0xc4, 0xd3, 0xab, 0xd9, 0xdc, 0x3b, 0x72,
-	0xde, 0x38, 0x72, 0xbe, 0x6c, 0x1c, 0x29, 0x3b, 0xd1, 0x1d, 0x87, 0x0c, 0x69, 0x50, 0x1b, 0x87,
-	0xbc, 0x66, 0x13, 0x5d, 0x2b, 0x62, 0xc5, 0x88, 0xb6, 0xfc, 0x7c, 0xde, 0x7d, 0x04, 0x1a, 0xbd,
-	0x64, 0x1b, 0xd7, 0x4a, 0x37, 0xfe, 0xa8, 0x74, 0x93, 0x8e, 0x74, 0x3c, 0x62, 0xe7, 0x1b, 0xd0,
-	0x4b, 0x93, 0x96, 0x76, 0xad, 0xcd, 0xae, 0xf6, 0xc9, 0x23, 0x0e, 0x6d, 0x54, 0xe9, 0xe2, 0xb8,
-	0xd1, 0x25, 0x19, 0x65, 0x22, 0x1b, 0x48, 0x2b, 0x46, 0xff, 0xf9, 0xe1, 0xb7, 0xa5, 0x38, 0xaf,
-	0x57, 0x3c, 0xc4, 0xd3, 0xf0, 0xf3, 0x9a, 0x3c, 0x31, 0x91, 0x1e, 0x44, 0x96, 0x8d, 0x16, 0xa0,
-	0x7f, 0x51, 0x05, 0xe0, 0x2b, 0xb2, 0x56, 0x05, 0x74, 0x1a, 0x74, 0xc2, 0xe4, 0x67, 0xa3, 0x0e,
-	0x60, 0xea, 0xd6, 0xd4, 0x88, 0x5f, 0xb3, 0x31, 0x36, 0x31, 0x01, 0x67, 0x45, 0x40, 0x62, 0x88,
-	0x47, 0x62, 0x74, 0x66, 0x40, 0x9e, 0x22, 0xa3, 0x98, 0xb1, 0x0f, 0xda, 0xfc, 0x05, 0xe6, 0xd7,
-	0x72, 0xad, 0xf1, 0xdc, 0x4a, 0xeb, 0xa2, 0x33, 0x5a, 0x27, 0x1c, 0x65, 0xec, 0xc2, 0x47, 0xbe,
-	0x05, 0x67, 0x54, 0x66, 0x71, 0x4c, 0x56, 0x47, 0x07, 0x56, 0x42, 0x9a, 0x53, 0x74, 0x20, 0x5b,
-	0x02, 0xb7, 0xda, 0x5b, 0x30, 0xd8, 0x51, 0x2a, 0x34, 0x90, 0x27, 0x4c, 0x8f, 0xd5, 0xd1, 0xd2,
-	0x52, 0x40, 0x4b, 0x0d, 0x8c, 0xfe, 0x3d, 0x63, 0x43, 0x09, 0x76, 0x5f, 0x38, 0xfe, 0x43, 0x3d,
-	0x31, 0xe4, 0x14, 0xd1, 0xa3, 0x1b, 0x7d, 0xf9, 0xe8, 0x46, 0xad, 0x91, 0x64, 0x27, 0x94, 0x7f,
-	0xc7, 0x86, 0x7e, 0xf2, 0xe8, 0xdc, 0xe9, 0xd5, 0x67, 0x8f, 0x92, 0xbc, 0xcf, 0x65, 0x1d, 0xc2,
-	0x63, 0xd6, 0x57, 0xe5, 0x5a, 0x53, 0x1d, 0xd3, 0xab, 0x97, 0x4f, 0x15, 0xc3, 0x6e, 0x48, 0x8a,
-	0xc0, 0xa6, 0x81, 0x31, 0xda, 0xd0, 0x74, 0x4f, 0xa4, 0x07, 0xf4, 0x02, 0x6d, 0xd3, 0x0a, 0x68,
-	0xa4, 0xf1, 0x05, 0x42, 0x80, 0xb5, 0xdf, 0x9f, 0x54, 0xa5, 0x07, 0xfc, 0x69, 0xed, 0xad, 0xe8,
-	0xb2, 0x13, 0xca, 0xaf, 0xd9, 0x68, 0xe7, 0xe5, 0xa5, 0xf7, 0x9d, 0x3c, 0xf2, 0x2c, 0xab, 0x6e,
-	0x80, 0x6c, 0x42, 0xaf, 0x7e, 0x66, 0xfd, 0xc5, 0xed, 0x4f, 0x77, 0xfc, 0x0d, 0x1b, 0xbd, 0x37,
-	0x3a, 0x03, 0x6b, 0xf9, 0xec, 0xe9, 0x4d, 0xda, 0xbf, 0xda, 0xec, 0x89, 0x20, 0x24, 0xf7, 0x6a,
-	0x48, 0x26, 0x7c, 0xfd, 0x7f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x3a, 0xfc, 0x7b, 0x42, 0x46, 0x07,
+	// 2978 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x95, 0x59, 0xdb, 0x7a, 0x1b, 0x49,
+	0x11, 0x46, 0xb6, 0x6c, 0x4b, 0x2d, 0xdb, 0x51, 0x26, 0x87, 0xed, 0x38, 0xcb, 0x6e, 0x10, 0x4b,
+	0x08, 0x5e, 0x70, 0x12, 0x27, 0x24, 0x90, 0x05, 0x36, 0xb2, 0xa4, 0xd8, 0xda, 0x58, 0x96, 0xd3,
+	0x52, 0x4e, 0x9c, 0xc2, 0x58, 0x6a, 0xc9, 0xb3, 0x19, 0xcd, 0xe8, 0x9b, 0x19, 0xf9, 0xb0, 0x57,
+	0xb9, 0xe0, 0x86, 0x17, 0xe1, 0x8a, 0x5b, 0x5e, 0x85, 0x0b, 0x9e, 0x81, 0x8f, 0x67, 0xa0, 0xaa,
+	0xba, 0x67, 0xa6, 0x67, 0xac, 0xf0, 0xc1, 0x95, 0xa6, 0xfe, 0xaa, 0xee, 0xae, 0xae, 0xae, 0xaa,
+	0xae, 0x6a, 0xb1, 0xcb, 0xe3, 0xa1, 0xed, 0x86, 0x32, 0x38, 0x71, 0x06, 0x72, 0x6b, 0x1a, 0xf8,
+	0x91, 0x6f, 0x55, 0x0c, 0x68, 0xe3, 0xf3, 0xb1, 0xef, 0x8f, 0x5d, 0x79, 0x97, 0x58, 0x47, 0xb3,
+	0xd1, 0xdd, 0xc8, 0x99, 0xc8, 0x30, 0xb2, 0x27, 0x53, 0x25, 0x5d, 0xfb, 0xe7, 0x4d, 0xb6, 0xb6,
+	0x2b, 0x7d, 0x71, 0xd8, 0xd8, 0x0d, 0x6c, 0x6f, 0xe6, 0x4a, 0xeb, 0x53, 0x56, 0xf6, 0xa7, 0x32,
+	0xb0, 0x23, 0xc7, 0xf7, 0x78, 0xe1, 0x56, 0xe1, 0x4e, 0x59, 0xa4, 0x80, 0x65, 0xb1, 0xe2, 0xd4,
+	0x8e, 0x8e, 0xf9, 0x02, 0x31, 0xe8, 0xdb, 0xda, 0x60, 0xa5, 0xb1, 0xf4, 0x27, 0x32, 0x0a, 0xce,
+	0xf9, 0x22, 0xe1, 0x09, 0x6d, 0x5d, 0x65, 0x4b, 0x47, 0xb6, 0x37, 0x0c, 0x79, 0xf1, 0xd6, 0xe2,
+	0x9d, 0x25, 0xa1, 0x08, 0xeb, 0x3a, 0x5b, 0x3e, 0x96, 0xce, 0xf8, 0x38, 0xe2, 0x4b, 0x20, 0xbf,
+	0x24, 0x34, 0x85, 0xd2, 0xa7, 0xce, 0x10, 0xa6, 0x5f, 0x26, 0x58, 0x11, 0x28, 0x1d, 0x06, 0x83,
+	0x9e, 0xe8, 0xf1, 0x15, 0x9a, 0x5d, 0x53, 0x16, 0x67, 0x2b, 0xf0, 0x05, 0xda, 0x47, 0xbc, 0x04,
+	0xb3, 0x17, 0x44, 0x4c, 0xe2, 0x88, 0x61, 0x18, 0xe1, 0x88, 0xb2, 0x1a, 0xa1, 0x28, 0x1c, 0x01,
+	0x5f, 0x34, 0x82, 0xa9, 0x11, 0x9a, 0xb4, 0x6e, 0xb1, 0x0a, 0xaa, 0xd6, 0x8b, 0x02, 0x67, 0x28,
+	0x43, 0x5e, 0xa1, 0xf5, 0x4d, 0xc8, 0xfa, 0x8c, 0x31, 0xd8, 0xd5, 0xbe, 0x3f, 0xe8, 0x4e, 0xa3,
+	0x90, 0xaf, 0xc2, 0xf0, 0xb2, 0x30, 0x10, 0x6b, 0x93, 0x55, 0x87, 0x81, 0xe3, 0xba, 0x4d, 0x39,
+	0x70, 0x5c, 0xd9, 0xf0, 0x67, 0x5e, 0xc4, 0xd7, 0x68, 0x9a, 0x0b, 0x38, 0xda, 0x78, 0xe0, 0x3a,
+	0xd3, 0x97, 0x53, 0xb0, 0x2b, 0x5f, 0x07, 0xa1, 0x05, 0x91, 0x02, 0x31, 0x77, 0xdf, 0x3f, 0x05,
+	0xee, 0xa5, 0x94, 0x4b, 0x00, 0xda, 0x28, 0x14, 0xbd, 0xc6, 0x88, 0x57, 0x95, 0x8d, 0x88, 0x40,
+	0xed, 0xa6, 0xce, 0x99, 0x74, 0xd5, 0xba, 0x97, 0x89, 0x65, 0x20, 0x56, 0x95, 0x2d, 0x9e, 0x88,
+	0x3e, 0xb7, 0xc8, 0x1c, 0xf8, 0x69, 0xdd, 0x61, 0x97, 0x3c, 0xbf, 0x69, 0x47, 0x76, 0xdf, 0x77,
+	0xe1, 0x74, 0xbd, 0x81, 0xe4, 0x57, 0x68, 0xad, 0x3c, 0x6c, 0x7d, 0xc1, 0xd6, 0x06, 0xfe, 0x64,
+	0x3a, 0x8b, 0x64, 0x2f, 0x1a, 0x36, 0xe5, 0x09, 0xbf, 0x0a, 0x72, 0x25, 0x91, 0x05, 0xd1, 0x82,
+	0xa0, 0xfc, 0x40, 0x7a, 0x11, 0x6c, 0x33, 0xe4, 0xd7, 0xc8, 0xbe, 0x26, 0x64, 0x6d, 0x31, 0x6b,
+	0x14, 0xd8, 0x03, 0xf4, 0x23, 0x1b, 0xd4, 0x3a, 0x81, 0xe9, 0xc7, 0x92, 0x5f, 0xa7, 0xc9, 0xe6,
+	0x70, 0xac, 0x1a, 0x5b, 0x05, 0x57, 0x8d, 0xc2, 0xd7, 0x7e, 0xf0, 0x5e, 0x06, 0x21, 0xff, 0x84,
+	0x76, 0x95, 0xc1, 0x0c, 0xdd, 0x3a, 0x72, 0xe8, 0xd8, 0x1e, 0xe7, 0x19, 0xdd, 0x14, 0x68, 0x4a,
+	0x39, 0x5e, 0xc7, 0x3e, 0xe3, 0x37, 0xb2, 0x52, 0x04, 0xe2, 0x0e, 0x62, 0xbf, 0x45, 0xd7, 0xd9,
+	0x20, 0x5b, 0x99, 0x10, 0x4a, 0xd8, 0x53, 0x08, 0x9c, 0xb3, 0xde, 0xc0, 0x76, 0x25, 0xbf, 0x49,
+	0xf6, 0x32, 0x21, 0xb2, 0x02, 0x5a, 0x7d, 0x67, 0x36, 0x1c, 0xcb, 0x88, 0x7f, 0x0a, 0x12, 0x8b,
+	0xc2, 0x84, 0xd0, 0x4f, 0x60, 0x80, 0x7b, 0x4e, 0xf2, 0xdd, 0xd1, 0x28, 0x04, 0xb1, 0xef, 0x93,
+	0x3a, 0x17, 0x70, 0xb4, 0x40, 0x20, 0xa3, 0x59, 0xe0, 0x1d, 0xe2, 0x04, 0x21, 0xff, 0x8c, 0xe4,
+	0x32, 0x18, 0x9e, 0xe3, 0xc4, 0x3e, 0x13, 0xa6, 0xd8, 0xe7, 0x64, 0xa8, 0x3c, 0x8c, 0x56, 0x38,
+	0x76, 0xc2, 0xc8, 0x1f, 0x07, 0xf6, 0x64, 0xc7, 0xf1, 0x42, 0x7e, 0x8b, 0xe4, 0xb2, 0x20, 0xae,
+	0x99, 0x00, 0x60, 0x18, 0xfe, 0x03, 0x10, 0x2a, 0x88, 0x0c, 0x96, 0x95, 0x01, 0x73, 0xd6, 0xf2,
+	0x32, 0x60, 0xcd, 0x27, 0x60, 0xab, 0xf1, 0x38, 0x90, 0x63, 0x95, 0x49, 0x7e, 0x08, 0x22, 0xeb,
+	0xdb, 0x7c, 0xcb, 0x4c, 0x58, 0xf5, 0x94, 0x2f, 0x4c, 0x61, 0xeb, 0x29, 0x5b, 0x73, 0xbc, 0x48,
+	0x06, 0x53, 0xdf, 0x55, 0xa3, 0xbf, 0xa0, 0xd1, 0x1b, 0x99, 0xd1, 0x6d, 0x53, 0x42, 0x64, 0x07,
+	0xc0, 0xea, 0x3c, 0x03, 0x34, 0x8e, 0xe5, 0xe0, 0xbd, 0x0a, 0x65, 0xfe, 0x23, 0xda, 0xf6, 0x47,
+	0xf9, 0x78, 0x86, 0x03, 0x3b, 0x92, 0x63, 0x3f, 0x70, 0xe0, 0x2c, 0xf8, 0x6d, 0x32, 0xba, 0x09,
+	0x61, 0x1e, 0x19, 0xb8, 0x76, 0x18, 0x82, 0x9f, 0xff, 0x98, 0xf2, 0x5a, 0x4c, 0xd2, 0x58, 0xed,
+	0x54, 0x3e, 0x2c, 0x75, 0x47, 0x8f, 0x4d, 0x21, 0xb4, 0xdd, 0x91, 0xeb, 0x0f, 0xde, 0xd7, 0x5d,
+	0x67, 0xec, 0xc9, 0x21, 0xff, 0x89, 0x3a, 0x53, 0x13, 0xc3, 0x0c, 0x80, 0xa9, 0xa7, 0x8f, 0xc9,
+	0x9a, 0x6f, 0xc2, 0x0a, 0x8b, 0x22, 0x05, 0xc8, 0x9b, 0x21, 0x1d, 0xb4, 0xbd, 0x81, 0x3b, 0x0b,
+	0x9d, 0x13, 0xc9, 0xbf, 0xd4, 0xde, 0x6c, 0x82, 0xe8, 0x67, 0x08, 0xec, 0x9c, 0x1f, 0x26, 0x21,
+	0xc8, 0x7f, 0xaa, 0xfc, 0x2c, 0x8f, 0xa3, 0x4e, 0xb0, 0xf5, 0xc9, 0x33, 0x1d, 0x83, 0xfc, 0x67,
+	0xea, 0x3c, 0x4d, 0xcc, 0x7a, 0xcc, 0x58, 0x20, 0x43, 0xb8, 0x39, 0x5c, 0xc7, 0x1b, 0xf3, 0x2d,
+	0x3a, 0x90, 0x4f, 0x32, 0x07, 0x22, 0x12, 0xb6, 0x30, 0x44, 0x69, 0xc3, 0xb3, 0xd1, 0x48, 0x06,
+	0x1d, 0x19, 0x61, 0x18, 0xdf, 0x55, 0x93, 0x9b, 0x18, 0xa6, 0x2f, 0x6d, 0xa3, 0xf6, 0x0b, 0xc1,
+	0xef, 0x91, 0x9a, 0x06, 0x62, 0xf0, 0x3b, 0xf5, 0x26, 0xbf, 0x9f, 0xe1, 0x03, 0x62, 0xf0, 0x7b,
+	0xb3, 0x09, 0xdf, 0xce, 0xf0, 0x01, 0x41, 0x83, 0x86, 0xb3, 0xc9, 0xce, 0x79, 0x3d, 0x90, 0x36,
+	0x7f, 0x40, 0xec, 0x14, 0xc0, 0x43, 0x83, 0x1b, 0xce, 0x83, 0x34, 0x0e, 0x1b, 0x0d, 0xf9, 0x43,
+	0xca, 0xed, 0x26, 0xa4, 0x12, 0x88, 0x37, 0x72, 0xc6, 0xb1, 0xcc, 0xcf, 0x49, 0x26, 0x0b, 0x5a,
+	0xb7, 0xd9, 0xba, 0xed, 0xba, 0x90, 0xa5, 0x87, 0xcd, 0x00, 0x8e, 0x00, 0xf6, 0xfa, 0x88, 0xc4,
+	0x72, 0x28, 0x6a, 0x7b, 0x4a, 0x17, 0xde, 0x0e, 0x9c, 0x29, 0x7f, 0xac, 0x92, 0x75, 0x8a, 0x60,
+	0x48, 0xa7, 0xb9, 0xb5, 0x15, 0x04, 0x7e, 0xc0, 0x7f, 0x41, 0x3a, 0xe7, 0x61, 0x9c, 0x09, 0xfd,
+	0x2e, 0xda, 0x0b, 0xe4, 0x28, 0xe4, 0xbf, 0x54, 0x97, 0x52, 0x8a, 0xa0, 0xed, 0x21, 0x79, 0xd9,
+	0x43, 0xc8, 0xe7, 0x5d, 0xcf, 0x3d, 0xe7, 0x4f, 0x94, 0xb3, 0x99, 0x98, 0x5a, 0xcd, 0x1b, 0xcc,
+	0x82, 0x00, 0xbc, 0x41, 0x48, 0x1b, 0x2e, 0xeb, 0xaf, 0x54, 0x02, 0xc9, 0xc1, 0x74, 0x31, 0x29,
+	0x05, 0x1a, 0xaf, 0xf8, 0xaf, 0x94, 0x15, 0x13, 0x00, 0xe7, 0x51, 0x17, 0x8e, 0xc4, 0xc0, 0xea,
+	0xd8, 0xe1, 0x7b, 0xfe, 0x6b, 0xa5, 0x75, 0x0e, 0xc6, 0x82, 0x61, 0x02, 0xbf, 0xb4, 0xfb, 0xdf,
+	0xd0, 0x52, 0x09, 0x1d, 0xf3, 0x0e, 0xb1, 0xc8, 0xf8, 0x5a, 0x15, 0x13, 0x31, 0x8d, 0xf6, 0x85,
+	0x9c, 0xd6, 0xc4, 0xdb, 0xb4, 0x23, 0x27, 0x3e, 0x94, 0x1b, 0x4f, 0x29, 0xbf, 0xe6, 0x50, 0xeb,
+	0x21, 0xbb, 0xa6, 0xd5, 0x3a, 0xa0, 0xab, 0x2c, 0xf1, 0xeb, 0x3a, 0xe9, 0x33, 0x9f, 0x89, 0xb3,
+	0x2b, 0x9f, 0xec, 0xc9, 0xf1, 0x04, 0x94, 0x0d, 0xf9, 0x0e, 0xe9, 0x96, 0x43, 0x51, 0x2e, 0x89,
+	0x67, 0x25, 0xd7, 0xa0, 0x69, 0x73, 0x28, 0x9e, 0x4d, 0x38, 0x3b, 0x42, 0x33, 0x63, 0x8a, 0x6f,
+	0xd2, 0x5e, 0x0c, 0x84, 0x76, 0xe3, 0x78, 0xaf, 0x6c, 0xd7, 0x19, 0xea, 0xbc, 0xdd, 0x52, 0xeb,
+	0x65, 0x51, 0x0c, 0xe4, 0x18, 0x49, 0x36, 0xf2, 0x8c, 0x62, 0xe8, 0x02, 0x6e, 0xdd, 0x63, 0x57,
+	0x06, 0xbe, 0x1f, 0x0c, 0x1d, 0x0f, 0xb2, 0x55, 0x37, 0x29, 0xe3, 0x76, 0x69, 0xf1, 0x79, 0x2c,
+	0xf2, 0x59, 0x88, 0x81, 0xee, 0x88, 0xd2, 0x29, 0xd4, 0x86, 0x7c, 0x8f, 0x6e, 0xee, 0x1c, 0x8a,
+	0xe9, 0x1c, 0xf7, 0xe7, 0xca, 0xb3, 0x43, 0x3b, 0x88, 0x78, 0x7b, 0x4e, 0x3a, 0x6f, 0xa4, 0x7c,
+	0x61, 0x0a, 0x63, 0xba, 0xfc, 0xce, 0xf7, 0x64, 0xbb, 0x19, 0xf2, 0x6f, 0x54, 0xba, 0xd4, 0x64,
+	0x6c, 0x4b, 0xe9, 0x85, 0xa0, 0xd4, 0x10, 0x63, 0xf7, 0x79, 0x6a, 0xcb, 0x14, 0xc5, 0xf8, 0x1b,
+	0xca, 0xa3, 0xd9, 0x98, 0xd2, 0x34, 0x04, 0x2e, 0xdf, 0x57, 0x29, 0x2f, 0x03, 0xe2, 0x3a, 0xa7,
+	0x76, 0x30, 0xc5, 0xcb, 0xbb, 0x43, 0x3b, 0x8e, 0x49, 0x5c, 0x07, 0x3f, 0x21, 0x43, 0xf9, 0xee,
+	0x8c, 0x4c, 0x72, 0xa0, 0x76, 0x99, 0x45, 0xad, 0xaf, 0x13, 0xb9, 0x38, 0xd1, 0x75, 0xff, 0x7b,
+	0xa2, 0xcb, 0x89, 0x63, 0x10, 0xd0, 0xbd, 0xe2, 0xf8, 0x81, 0x2a, 0xf8, 0x42, 0x7e, 0xa8, 0x82,
+	0x20, 0x07, 0x53, 0x1d, 0x87, 0x95, 0x0c, 0x7f, 0x01, 0xfc, 0x35, 0xa1, 0x88, 0x38, 0x6b, 0x53,
+	0x21, 0x08, 0x09, 0x9a, 0x42, 0x44, 0x80, 0xaa, 0x0b, 0xe2, 0x02, 0x1e, 0xcb, 0x52, 0x59, 0x18,
+	0xcb, 0xf6, 0x52, 0x59, 0x13, 0xa7, 0x1a, 0x3a, 0x82, 0x13, 0x9d, 0xf0, 0x3e, 0xa9, 0xa3, 0x29,
+	0x4a, 0x8c, 0xce, 0x78, 0x62, 0x37, 0x60, 0x00, 0x7f, 0x49, 0x5e, 0x95, 0x02, 0xb8, 0x1b, 0x22,
+	0xda, 0x91, 0x76, 0x97, 0x90, 0xbf, 0x52, 0xa9, 0x21, 0x07, 0x63, 0x6d, 0x87, 0x47, 0x06, 0xae,
+	0x12, 0xe2, 0x25, 0xd5, 0x83, 0xad, 0xc2, 0xd6, 0x5f, 0xab, 0xda, 0xee, 0x22, 0x07, 0x0f, 0x14,
+	0xcb, 0xbc, 0x13, 0x47, 0x9e, 0xee, 0xcb, 0x13, 0xe9, 0xf2, 0x37, 0xaa, 0x16, 0xc9, 0x80, 0x2a,
+	0x19, 0x9c, 0xed, 0x50, 0x03, 0xf1, 0x36, 0x4e, 0x14, 0x8a, 0xc6, 0x1d, 0xcd, 0x42, 0x29, 0xea,
+	0x7d, 0xfe, 0x5b, 0xb5, 0x23, 0x45, 0x51, 0xd5, 0x38, 0xf1, 0xfd, 0xe8, 0xf8, 0xb5, 0xe3, 0x0d,
+	0xfd, 0x53, 0xfe, 0x3b, 0x5d, 0x35, 0x1a, 0x18, 0x3a, 0x0a, 0x26, 0x15, 0x2c, 0x6f, 0x7e, 0x4f,
+	0x7b, 0x8e, 0xc9, 0x84, 0x03, 0x45, 0xcd, 0x1f, 0x0c, 0x0e, 0xd4, 0x33, 0x60, 0x0b, 0x55, 0xe8,
+	0xbd, 0x98, 0xd9, 0xba, 0xc6, 0xfd, 0xa3, 0x3a, 0xd9, 0x1c, 0x6c, 0xdd, 0x57, 0x29, 0xac, 0x7f,
+	0x3e, 0x95, 0xfc, 0x1d, 0xb9, 0xcf, 0xb5, 0x8c, 0xfb, 0x74, 0x34, 0x53, 0x24, 0x62, 0x68, 0x0e,
+	0xca, 0x80, 0x50, 0xac, 0x41, 0x40, 0xcf, 0x24, 0xff, 0x13, 0x2d, 0x9e, 0x05, 0xd1, 0xc8, 0xaa,
+	0x36, 0x17, 0xd2, 0x4d, 0xab, 0x76, 0x9b, 0xaa, 0xd0, 0x39, 0x9c, 0xda, 0xdf, 0x0a, 0x6c, 0x59,
+	0xd8, 0x21, 0x9c, 0x12, 0xf6, 0x6d, 0x98, 0x77, 0xa8, 0xa1, 0x5b, 0x15, 0xf4, 0x8d, 0x16, 0x54,
+	0x83, 0xa8, 0x9b, 0x2b, 0x08, 0x4d, 0x61, 0xe2, 0x0a, 0x68, 0x14, 0xed, 0x40, 0x75, 0x74, 0x06,
+	0x82, 0x73, 0x1d, 0x1d, 0xf9, 0x67, 0xba, 0xa5, 0xa3, 0x6f, 0xb4, 0x3a, 0x14, 0xca, 0x7d, 0x58,
+	0x37, 0x1c, 0xf9, 0xc1, 0x04, 0xfa, 0x3a, 0x0c, 0xaf, 0x0c, 0x46, 0x3d, 0x4a, 0xe0, 0x7f, 0x2b,
+	0x55, 0x0a, 0x5b, 0x56, 0xf3, 0xa6, 0x48, 0xed, 0x5f, 0x05, 0xc6, 0x0c, 0x17, 0x81, 0x00, 0x39,
+	0x21, 0x5b, 0x14, 0x48, 0x3b, 0x45, 0x20, 0x3a, 0xa0, 0x1e, 0x67, 0x41, 0xb5, 0x3f, 0x44, 0xa0,
+	0x4a, 0xd8, 0xd9, 0x92, 0xb2, 0x8b, 0x82, 0xbe, 0x51, 0x25, 0x0c, 0x83, 0xa9, 0x1c, 0xaa, 0xa6,
+	0xa8, 0xa8, 0x1c, 0xc1, 0xc4, 0x50, 0xa5, 0x13, 0x4c, 0xa0, 0x4a, 0x62, 0x89, 0x46, 0x1b, 0x08,
+	0x86, 0x58, 0xe4, 0x47, 0xb6, 0x8b, 0x47, 0x16, 0xcf, 0xb3, 0x4c, 0x52, 0x17, 0x70, 0x3c, 0x1d,
+	0x8a, 0x0a, 0x21, 0x71, 0x43, 0xb1, 0xf4, 0x0a, 0x49, 0xcf, 0xe1, 0xd4, 0xf6, 0x19, 0x43, 0x4f,
+	0xd6, 0x59, 0x1e, 0x8d, 0x8a, 0x01, 0x5c, 0x20, 0x2d, 0xe9, 0x1b, 0xf7, 0x0a, 0xfe, 0x2a, 0xcf,
+	0x60, 0xaf, 0xd4, 0x3c, 0x13, 0x91, 0xda, 0x65, 0x91, 0x62, 0x5d, 0x11, 0xb5, 0x0e, 0x2b, 0xef,
+	0xc5, 0xe5, 0xf7, 0xc7, 0x26, 0x93, 0xd0, 0x80, 0x84, 0x34, 0x19, 0x98, 0x93, 0x08, 0xf4, 0x01,
+	0xb2, 0x60, 0x48, 0xb3, 0x2d, 0x0a, 0x4d, 0xd5, 0xfe, 0x5d, 0x60, 0xeb, 0x0d, 0xac, 0x69, 0xe3,
+	0xab, 0x65, 0xbe, 0x86, 0x46, 0x21, 0xbc, 0x90, 0x2d, 0x84, 0x21, 0xb1, 0xc4, 0x2d, 0x9d, 0x9a,
+	0x1b, 0x12, 0x4b, 0x02, 0x18, 0xcb, 0x16, 0xcd, 0x65, 0x55, 0xc0, 0x7f, 0x0b, 0x55, 0x76, 0x74,
+	0xae, 0x9f, 0x06, 0x12, 0x9a, 0x78, 0x8e, 0xa7, 0x78, 0xcb, 0x9a, 0xa7, 0x69, 0x0c, 0xce, 0x21,
+	0xec, 0xde, 0xf1, 0x06, 0x51, 0x43, 0xeb, 0xb3, 0xa2, 0x12, 0x55, 0x0e, 0xc6, 0x95, 0x5d, 0xfb,
+	0x08, 0x6f, 0xdb, 0x12, 0x55, 0x4b, 0x9a, 0xaa, 0xf5, 0xd9, 0x2a, 0x9e, 0x46, 0x72, 0x97, 0xcc,
+	0xdb, 0x2d, 0x68, 0x30, 0x88, 0x2f, 0x20, 0x74, 0xbf, 0xa2, 0x48, 0xe8, 0xd4, 0x2f, 0x95, 0x0b,
+	0x2a, 0xa2, 0xf6, 0x88, 0x95, 0xba, 0x3a, 0xa3, 0xa1, 0xc4, 0x59, 0xcf, 0xf9, 0x4e, 0xea, 0x29,
+	0x15, 0x81, 0xe8, 0x39, 0xa1, 0xda, 0x9f, 0x89, 0xa8, 0xfd, 0x75, 0x91, 0x55, 0x76, 0xa5, 0x0f,
+	0xd5, 0xb1, 0x4d, 0x21, 0x09, 0x15, 0xaa, 0x2e, 0x1b, 0x0e, 0xec, 0x89, 0xd4, 0xcf, 0x32, 0x26,
+	0x84, 0xf6, 0xf6, 0xe0, 0xb7, 0x37, 0xb5, 0x07, 0x52, 0xbf, 0xce, 0xa4, 0x00, 0xc5, 0x47, 0x1a,
+	0xcc, 0xf4, 0x8d, 0x73, 0xaa, 0xa0, 0x36, 0xc3, 0xc3, 0x84, 0xe0, 0xce, 0x67, 0x18, 0x49, 0x3d,
+	0x7c, 0x2f, 0x0a, 0x29, 0xa4, 0x2b, 0xd8, 0x83, 0xd1, 0x93, 0xd2, 0x56, 0xfc, 0xa4, 0xb4, 0xd5,
+	0x8f, 0x9f, 0x94, 0x84, 0x21, 0x6d, 0x3c, 0xf1, 0x2c, 0xd3, 0xe1, 0xc7, 0x4f, 0x3c, 0x0f, 0x58,
+	0x39, 0xce, 0xf1, 0x78, 0x46, 0x38, 0x65, 0x36, 0x3b, 0xc6, 0xf6, 0x12, 0xa9, 0x5c, 0x6a, 0xba,
+	0xd2, 0x5c, 0xd3, 0x95, 0x0d, 0xd3, 0x5d, 0xc8, 0x44, 0x6c, 0x4e, 0x26, 0x02, 0xb7, 0x85, 0xc6,
+	0xef, 0x7c, 0x0c, 0x69, 0xa8, 0xa2, 0x0a, 0x05, 0x4d, 0x12, 0x07, 0x32, 0xd2, 0xeb, 0xe7, 0x7d,
+	0xbe, 0xaa, 0x39, 0x8a, 0xc4, 0xd5, 0xf0, 0xf3, 0x21, 0x3d, 0xea, 0x94, 0x85, 0x22, 0x6a, 0x21,
+	0x5b, 0x81, 0x73, 0x7a, 0x86, 0x4d, 0x14, 0x78, 0xc7, 0x08, 0x7e, 0x8d, 0x03, 0x4a, 0x68, 0x7a,
+	0x90, 0xa2, 0xe2, 0x5f, 0x1f, 0x8d, 0xa6, 0xa0, 0x52, 0x2d, 0xe1, 0x21, 0xf6, 0xa4, 0x0e, 0xc0,
+	0x4a, 0xae, 0xa4, 0x32, 0x7c, 0x40, 0x24, 0x92, 0xb5, 0x3b, 0x8c, 0xa9, 0xf7, 0x8f, 0xb6, 0x37,
+	0xf2, 0x71, 0xdd, 0xa9, 0xef, 0xbb, 0x86, 0x6b, 0x25, 0x74, 0xed, 0x2f, 0x45, 0xb6, 0xa6, 0x44,
+	0x61, 0x1a, 0xe8, 0x5d, 0x29, 0x2e, 0x8f, 0xce, 0x23, 0x19, 0x62, 0x45, 0x4f, 0xe2, 0xd8, 0x5a,
+	0xc6, 0x00, 0xce, 0x05, 0xd7, 0x68, 0x80, 0x47, 0x4a, 0x9a, 0x2e, 0x8a, 0x84, 0xa6, 0xe7, 0xb6,
+	0x73, 0xba, 0xc3, 0xb5, 0x8f, 0xc7, 0x24, 0x7a, 0xd2, 0x89, 0x51, 0xc6, 0x16, 0xd5, 0xa3, 0x87,
+	0x01, 0x51, 0x1f, 0x42, 0xa9, 0x52, 0x8b, 0xa8, 0x4c, 0x9b, 0xc1, 0xb0, 0x76, 0xbd, 0xd8, 0x92,
+	0x87, 0x3a, 0xd4, 0xe7, 0xb1, 0xb0, 0xce, 0xcf, 0xc0, 0x70, 0x4d, 0xab, 0x6e, 0x69, 0x85, 0x6e,
+	0x8c, 0xf9, 0x4c, 0xeb, 0x11, 0xbb, 0x9e, 0x65, 0x48, 0xdb, 0x53, 0xc3, 0x4a, 0x34, 0xec, 0x23,
+	0x5c, 0xb4, 0xcd, 0x29, 0x34, 0x72, 0x64, 0x80, 0xb2, 0xb2, 0x4d, 0x4c, 0x53, 0x67, 0x64, 0x43,
+	0x2e, 0x78, 0x19, 0x42, 0x47, 0xcf, 0x94, 0x55, 0x13, 0x80, 0xf2, 0x06, 0x12, 0x58, 0x55, 0x54,
+	0xd4, 0xc8, 0x98, 0x8e, 0x6f, 0xfe, 0x06, 0xd2, 0x7b, 0x0e, 0xbd, 0x2c, 0xa2, 0x40, 0x16, 0xa4,
+	0x87, 0x20, 0x0a, 0xcc, 0x76, 0x97, 0xd6, 0x5f, 0x53, 0xf6, 0x33, 0x31, 0xba, 0xb6, 0xe5, 0x70,
+	0x36, 0x90, 0x24, 0xb1, 0xae, 0xee, 0xb2, 0x14, 0xa9, 0xfd, 0x19, 0xaa, 0x01, 0x5d, 0xff, 0x40,
+	0x3a, 0xf0, 0x47, 0xa3, 0x37, 0x71, 0x72, 0xc3, 0x6f, 0x8d, 0xbd, 0xd5, 0x79, 0x88, 0xbe, 0x93,
+	0x34, 0xfd, 0x86, 0x4e, 0x7c, 0x49, 0xa7, 0xe9, 0x37, 0x09, 0xfe, 0x56, 0x67, 0x0d, 0x4d, 0xfd,
+	0x2f, 0xc7, 0x5c, 0xfb, 0xfb, 0x0a, 0x14, 0x25, 0x32, 0x9c, 0xb9, 0x11, 0x3e, 0x29, 0x44, 0x69,
+	0xb1, 0x58, 0x20, 0xff, 0xcf, 0x56, 0xda, 0x69, 0x39, 0x20, 0x0c, 0x51, 0xeb, 0x4b, 0xb6, 0xac,
+	0xb6, 0x4e, 0xda, 0x56, 0xb6, 0xaf, 0x64, 0xcb, 0x73, 0x62, 0x09, 0x2d, 0x02, 0x77, 0x43, 0xd1,
+	0x81, 0x38, 0xa1, 0x2d, 0x54, 0xb6, 0xaf, 0xe6, 0xe3, 0x0b, 0x63, 0x57, 0x90, 0x04, 0x5d, 0x91,
+	0xe4, 0x08, 0x45, 0x15, 0xe2, 0x44, 0x50, 0xa1, 0x7e, 0x6c, 0x43, 0xf2, 0x5c, 0x52, 0xb7, 0x30,
+	0x11, 0xa8, 0xfb, 0x69, 0x12, 0x83, 0xe4, 0xa4, 0x79, 0xdd, 0xd3, 0x10, 0x15, 0x86, 0x28, 0x38,
+	0xed, 0xca, 0x44, 0xc5, 0x22, 0xb9, 0x69, 0x25, 0xf7, 0xaa, 0x95, 0x89, 0x56, 0x11, 0x8b, 0x5e,
+	0xac, 0x97, 0x4b, 0xf3, 0xea, 0x65, 0x72, 0x81, 0xa4, 0xc5, 0x29, 0x53, 0xe6, 0x33, 0x10, 0xeb,
+	0x2e, 0x5b, 0x9e, 0xaa, 0x93, 0x61, 0x73, 0x8c, 0x9d, 0x56, 0x23, 0x42, 0x8b, 0x41, 0xac, 0xb0,
+	0xe4, 0x51, 0x0f, 0x5f, 0xc5, 0x71, 0xd0, 0xf5, 0xcc, 0xa0, 0xa4, 0xe8, 0x10, 0x86, 0xa4, 0xd5,
+	0x80, 0xbe, 0x2e, 0x53, 0x3d, 0xd0, 0x83, 0x79, 0x65, 0xfb, 0x66, 0xb6, 0x61, 0xcc, 0x88, 0x88,
+	0xdc, 0x10, 0x0c, 0x2a, 0x52, 0x83, 0x1e, 0x6d, 0xd6, 0x54, 0x6f, 0x92, 0x00, 0xe8, 0x03, 0xa7,
+	0xaa, 0xc2, 0x5f, 0x9f, 0xe3, 0x03, 0xca, 0xd1, 0x85, 0x16, 0x51, 0xb1, 0x1b, 0x78, 0xd0, 0xa1,
+	0x85, 0xfc, 0x12, 0xdd, 0xfb, 0x09, 0x6d, 0x76, 0xa7, 0xd5, 0x6c, 0x77, 0xfa, 0x18, 0xa2, 0x5a,
+	0xdf, 0xef, 0x21, 0xbf, 0x4c, 0x1b, 0xb8, 0x71, 0xc1, 0x62, 0x71, 0xc5, 0x20, 0x52, 0x59, 0x7d,
+	0x07, 0xd1, 0xb3, 0x31, 0x29, 0x6f, 0xa9, 0x27, 0x2f, 0x13, 0xc3, 0x96, 0xd4, 0xa4, 0x3b, 0xdb,
+	0xf4, 0xfc, 0x0e, 0x2d, 0x69, 0x16, 0x4d, 0x5e, 0x94, 0xb5, 0xd0, 0x55, 0x12, 0x32, 0xa1, 0xec,
+	0x6b, 0xe1, 0xb5, 0xfc, 0x6b, 0xe1, 0x36, 0xbb, 0x1a, 0xf7, 0x5f, 0x72, 0x68, 0xf4, 0x66, 0xd7,
+	0xa9, 0x13, 0x98, 0xcb, 0xc3, 0x5c, 0x30, 0xf3, 0x9c, 0x88, 0x5e, 0xdc, 0xa1, 0x5c, 0xc0, 0xef,
+	0xcd, 0x27, 0xac, 0x62, 0xbc, 0xd7, 0x5a, 0xeb, 0x8c, 0xd5, 0x45, 0xbb, 0xbf, 0xd7, 0x69, 0xf5,
+	0xdb, 0x8d, 0xea, 0xf7, 0xac, 0x35, 0x56, 0xde, 0x6d, 0x75, 0x81, 0x12, 0x40, 0x16, 0xac, 0x55,
+	0x56, 0xda, 0xab, 0x8b, 0x4e, 0xf7, 0x00, 0xa8, 0x85, 0xcd, 0xdb, 0x6c, 0x2d, 0xf3, 0x5a, 0x6b,
+	0x31, 0xb6, 0xbc, 0xdf, 0x3e, 0x68, 0xd5, 0x05, 0x8c, 0x2c, 0xb3, 0xa5, 0xc3, 0xc6, 0x5e, 0xfb,
+	0xb0, 0x5a, 0xd8, 0xdc, 0x66, 0xcc, 0xe8, 0xa5, 0x2b, 0x6c, 0x05, 0x45, 0x5a, 0xbd, 0x3e, 0x48,
+	0xc1, 0x84, 0x3b, 0x6d, 0x3d, 0xa6, 0x80, 0x63, 0x1a, 0x2f, 0x77, 0x68, 0xee, 0x6f, 0x58, 0xc5,
+	0x78, 0x78, 0x40, 0x3d, 0xea, 0x9d, 0xc3, 0xfd, 0x76, 0xff, 0x65, 0xb3, 0xa5, 0xd4, 0x6a, 0x1f,
+	0xf4, 0x5b, 0x07, 0xbd, 0x76, 0xff, 0x2d, 0x8c, 0x2b, 0xb1, 0xa2, 0x68, 0xd5, 0xf7, 0xab, 0x0b,
+	0xf8, 0xd5, 0xee, 0xd4, 0x77, 0xab, 0x8b, 0xb4, 0xfe, 0x5e, 0xbd, 0xd7, 0xaa, 0x16, 0x37, 0xff,
+	0x51, 0x60, 0x65, 0xa8, 0x5f, 0x22, 0xac, 0x19, 0x07, 0x38, 0xb6, 0xd7, 0xaf, 0xf7, 0xdf, 0x75,
+	0x5a, 0xf5, 0x03, 0x98, 0xea, 0x12, 0xab, 0x10, 0xd9, 0xeb, 0x37, 0x9b, 0xad, 0x57, 0x30, 0x59,
+	0x0c, 0x74, 0x5a, 0xcd, 0x36, 0x48, 0x2c, 0xa4, 0x40, 0xfb, 0xa0, 0x53, 0x7f, 0x53, 0x2d, 0xa6,
+	0x33, 0x74, 0x41, 0x99, 0x12, 0xee, 0x81, 0xc8, 0xf6, 0x0b, 0x51, 0xad, 0x26, 0x54, 0xa7, 0xde,
+	0xac, 0xde, 0x4a, 0xa8, 0xde, 0xcb, 0x4e, 0xf5, 0x29, 0x1c, 0xc0, 0x5a, 0xbc, 0x56, 0x4b, 0x88,
+	0xae, 0xa8, 0x7e, 0x40, 0x93, 0xae, 0x10, 0xd6, 0x78, 0x55, 0xfd, 0xb0, 0x60, 0xdd, 0x60, 0x57,
+	0x89, 0x3a, 0xe8, 0x36, 0xeb, 0xfd, 0xfa, 0xbb, 0x67, 0xa2, 0xde, 0xe8, 0xb7, 0xbb, 0x07, 0xd5,
+	0x0f, 0x45, 0xeb, 0x32, 0x5b, 0xd5, 0xab, 0x76, 0x5a, 0x07, 0xfd, 0x5e, 0xf5, 0x43, 0x69, 0xf3,
+	0x2b, 0x56, 0x8a, 0xbb, 0x4e, 0x54, 0xaa, 0x53, 0xef, 0x3d, 0x7f, 0xb7, 0xf3, 0xb6, 0x8f, 0x16,
+	0x82, 0x83, 0x24, 0x12, 0xcc, 0x74, 0xff, 0x11, 0xec, 0xaa, 0xca, 0x56, 0x89, 0x7e, 0xb6, 0xdf,
+	0xad, 0xf7, 0x1f, 0x6c, 0x57, 0x17, 0xb6, 0xe1, 0xe2, 0x28, 0xee, 0x36, 0xeb, 0xfb, 0x50, 0x0f,
+	0xae, 0x1c, 0x06, 0xfe, 0x00, 0xbc, 0xc5, 0xda, 0xc8, 0xa7, 0xd1, 0xf4, 0x1f, 0xc4, 0x8d, 0x2b,
+	0xf9, 0xc7, 0x12, 0xcc, 0xf5, 0x4f, 0x59, 0x85, 0x9e, 0xe8, 0x7a, 0xea, 0xdd, 0xe1, 0xff, 0x1d,
+	0x7f, 0xaf, 0x70, 0xb4, 0x4c, 0x15, 0xe7, 0x83, 0xff, 0x00, 0x19, 0x76, 0xf3, 0xa6, 0xf4, 0x1c,
 	0x00, 0x00,
 }